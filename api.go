@@ -8,28 +8,131 @@ Summary: ServiceFactory, abstract services and the AWS Service Factory implement
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"reflect"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/acm"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/apigateway/apigatewayiface"
+	"github.com/aws/aws-sdk-go/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go/service/apigatewayv2/apigatewayv2iface"
+	"github.com/aws/aws-sdk-go/service/backup"
+	"github.com/aws/aws-sdk-go/service/cloudcontrolapi"
+	"github.com/aws/aws-sdk-go/service/cloudcontrolapi/cloudcontrolapiiface"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go/service/cloudfront/cloudfrontiface"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/configservice"
+	"github.com/aws/aws-sdk-go/service/configservice/configserviceiface"
+	"github.com/aws/aws-sdk-go/service/docdb"
+	"github.com/aws/aws-sdk-go/service/docdb/docdbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+	"github.com/aws/aws-sdk-go/service/efs"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/elasticache/elasticacheiface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/eventbridge/eventbridgeiface"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 	"github.com/aws/aws-sdk-go/service/lightsail"
 	"github.com/aws/aws-sdk-go/service/lightsail/lightsailiface"
+	"github.com/aws/aws-sdk-go/service/neptune"
+	"github.com/aws/aws-sdk-go/service/neptune/neptuneiface"
+	"github.com/aws/aws-sdk-go/service/opensearchservice"
+	"github.com/aws/aws-sdk-go/service/opensearchservice/opensearchserviceiface"
 	"github.com/aws/aws-sdk-go/service/rds"
 	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/aws/aws-sdk-go/service/redshift"
+	"github.com/aws/aws-sdk-go/service/redshift/redshiftiface"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless/redshiftserverlessiface"
+	"github.com/aws/aws-sdk-go/service/resourceexplorer2"
+	"github.com/aws/aws-sdk-go/service/resourceexplorer2/resourceexplorer2iface"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/aws/aws-sdk-go/service/wafv2"
 )
 
+// The constructors of the AWS service clients that custom counters can use, keyed
+// by the name of the service's SDK package. (Each constructor has the form
+// New(client.ConfigProvider, ...*aws.Config).)
+var genericServiceConstructors = map[string]interface{}{
+	"acm":            acm.New,
+	"apigateway":     apigateway.New,
+	"backup":         backup.New,
+	"cloudformation": cloudformation.New,
+	"cloudtrail":     cloudtrail.New,
+	"cloudwatchlogs": cloudwatchlogs.New,
+	"dynamodb":       dynamodb.New,
+	"ec2":            ec2.New,
+	"ecr":            ecr.New,
+	"ecs":            ecs.New,
+	"efs":            efs.New,
+	"eks":            eks.New,
+	"elasticache":    elasticache.New,
+	"glue":           glue.New,
+	"iam":            iam.New,
+	"kinesis":        kinesis.New,
+	"kms":            kms.New,
+	"lambda":         lambda.New,
+	"rds":            rds.New,
+	"route53":        route53.New,
+	"s3":             s3.New,
+	"secretsmanager": secretsmanager.New,
+	"sfn":            sfn.New,
+	"sns":            sns.New,
+	"sqs":            sqs.New,
+	"ssm":            ssm.New,
+	"wafv2":          wafv2.New,
+}
+
+// IsGenericServiceName returns true if the named service (e.g., "kms") can be used by
+// custom counters.
+func IsGenericServiceName(serviceName string) bool {
+	_, ok := genericServiceConstructors[serviceName]
+	return ok
+}
+
 // DefaultRegion is used if the caller does not supply a region
 // on the command line or the profile does not have a default
 // region associated with it.
@@ -92,6 +195,68 @@ func (ec2i *EC2InstanceService) InspectVolumes(input *ec2.DescribeVolumesInput,
 	return ec2i.Client.DescribeVolumesPages(input, fn)
 }
 
+// InspectSnapshots takes an input filter specification (for the owners of snapshots)
+// and a function to evaluate a DescribeSnapshotsOutput struct. The supplied function
+// can determine when to stop iterating through EBS snapshots.
+func (ec2i *EC2InstanceService) InspectSnapshots(input *ec2.DescribeSnapshotsInput,
+	fn func(*ec2.DescribeSnapshotsOutput, bool) bool) error {
+	return ec2i.Client.DescribeSnapshotsPages(input, fn)
+}
+
+// InspectNatGateways takes an input filter specification (for the types of NAT gateways)
+// and a function to evaluate a DescribeNatGatewaysOutput struct. The supplied function
+// can determine when to stop iterating through NAT gateways.
+func (ec2i *EC2InstanceService) InspectNatGateways(input *ec2.DescribeNatGatewaysInput,
+	fn func(*ec2.DescribeNatGatewaysOutput, bool) bool) error {
+	return ec2i.Client.DescribeNatGatewaysPages(input, fn)
+}
+
+// InspectVpcs takes an input filter specification (for the types of VPCs) and a
+// function to evaluate a DescribeVpcsOutput struct. The supplied function can
+// determine when to stop iterating through VPCs.
+func (ec2i *EC2InstanceService) InspectVpcs(input *ec2.DescribeVpcsInput,
+	fn func(*ec2.DescribeVpcsOutput, bool) bool) error {
+	return ec2i.Client.DescribeVpcsPages(input, fn)
+}
+
+// InspectSubnets takes an input filter specification (for the types of subnets) and
+// a function to evaluate a DescribeSubnetsOutput struct. The supplied function can
+// determine when to stop iterating through subnets.
+func (ec2i *EC2InstanceService) InspectSubnets(input *ec2.DescribeSubnetsInput,
+	fn func(*ec2.DescribeSubnetsOutput, bool) bool) error {
+	return ec2i.Client.DescribeSubnetsPages(input, fn)
+}
+
+// InspectFlowLogs takes an input filter specification (for the types of flow logs)
+// and a function to evaluate a DescribeFlowLogsOutput struct. The supplied function
+// can determine when to stop iterating through flow logs.
+func (ec2i *EC2InstanceService) InspectFlowLogs(input *ec2.DescribeFlowLogsInput,
+	fn func(*ec2.DescribeFlowLogsOutput, bool) bool) error {
+	return ec2i.Client.DescribeFlowLogsPages(input, fn)
+}
+
+// InspectTransitGatewayAttachments takes an input filter specification (for the types
+// of attachments) and a function to evaluate a DescribeTransitGatewayAttachmentsOutput
+// struct. The supplied function can determine when to stop iterating through attachments.
+func (ec2i *EC2InstanceService) InspectTransitGatewayAttachments(input *ec2.DescribeTransitGatewayAttachmentsInput,
+	fn func(*ec2.DescribeTransitGatewayAttachmentsOutput, bool) bool) error {
+	return ec2i.Client.DescribeTransitGatewayAttachmentsPages(input, fn)
+}
+
+// InspectVpcEndpoints takes an input filter specification (for the types of VPC
+// endpoints) and a function to evaluate a DescribeVpcEndpointsOutput struct. The
+// supplied function can determine when to stop iterating through VPC endpoints.
+func (ec2i *EC2InstanceService) InspectVpcEndpoints(input *ec2.DescribeVpcEndpointsInput,
+	fn func(*ec2.DescribeVpcEndpointsOutput, bool) bool) error {
+	return ec2i.Client.DescribeVpcEndpointsPages(input, fn)
+}
+
+// GetAddresses returns the Elastic IP addresses based on the set of input parameters.
+// (This API does not page its results.)
+func (ec2i *EC2InstanceService) GetAddresses(input *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+	return ec2i.Client.DescribeAddresses(input)
+}
+
 // RDSInstanceService is a struct that knows how to get the
 // descriptions of all RDS instances using an object that
 // implements the Relational Database Service API interface.
@@ -107,6 +272,14 @@ func (rdsis *RDSInstanceService) InspectInstances(input *rds.DescribeDBInstances
 	return rdsis.Client.DescribeDBInstancesPages(input, fn)
 }
 
+// InspectClusters takes an input filter specification (for the types of clusters)
+// and a function to evaluate a DescribeDBClustersOutput struct. The supplied function
+// can determine when to stop iterating through RDS (and Aurora) clusters.
+func (rdsis *RDSInstanceService) InspectClusters(input *rds.DescribeDBClustersInput,
+	fn func(*rds.DescribeDBClustersOutput, bool) bool) error {
+	return rdsis.Client.DescribeDBClustersPages(input, fn)
+}
+
 // S3Service is a struct that knows how to get all of the S3 buckets using an object
 // that implements the Simple Storage Service API interface.
 type S3Service struct {
@@ -119,6 +292,180 @@ func (s3s *S3Service) ListBuckets(input *s3.ListBucketsInput) (*s3.ListBucketsOu
 	return s3s.Client.ListBuckets(input)
 }
 
+// GetBucketLocation takes an input specification (naming the S3 bucket) and returns
+// a GetBucketLocationOutput struct containing the bucket's location constraint.
+func (s3s *S3Service) GetBucketLocation(input *s3.GetBucketLocationInput) (*s3.GetBucketLocationOutput, error) {
+	return s3s.Client.GetBucketLocation(input)
+}
+
+// CloudFrontService is a struct that knows how to get all of the CloudFront
+// distributions using an object that implements the CloudFront API interface.
+type CloudFrontService struct {
+	Client cloudfrontiface.CloudFrontAPI
+}
+
+// ListDistributions takes an input specification (ListDistributionsInput) and a
+// function that is invoked for each page of results (ListDistributionsOutput). This
+// allows a caller to obtain all of the CloudFront distributions.
+func (cfs *CloudFrontService) ListDistributions(input *cloudfront.ListDistributionsInput,
+	fn func(*cloudfront.ListDistributionsOutput, bool) bool) error {
+	return cfs.Client.ListDistributionsPages(input, fn)
+}
+
+// CloudWatchService is a struct that knows how to get metric data (such as the daily
+// S3 storage metrics) using an object that implements the CloudWatch API interface.
+type CloudWatchService struct {
+	Client cloudwatchiface.CloudWatchAPI
+}
+
+// GetMetricData takes an input specification (GetMetricDataInput) and a function that
+// is invoked for each page of results (GetMetricDataOutput). This allows a caller to
+// obtain the data points of all of the requested metrics.
+func (cws *CloudWatchService) GetMetricData(input *cloudwatch.GetMetricDataInput,
+	fn func(*cloudwatch.GetMetricDataOutput, bool) bool) error {
+	return cws.Client.GetMetricDataPages(input, fn)
+}
+
+// CloudControlService is a struct that knows how to list the resources of any
+// (supported) CloudFormation resource type using an object that implements the
+// Cloud Control API interface.
+type CloudControlService struct {
+	Client cloudcontrolapiiface.CloudControlApiAPI
+}
+
+// ListResources takes an input specification (naming the resource type) and a
+// function that is invoked for each page of results (ListResourcesOutput). This
+// allows a caller to obtain all of the resources of that type.
+func (ccs *CloudControlService) ListResources(input *cloudcontrolapi.ListResourcesInput,
+	fn func(*cloudcontrolapi.ListResourcesOutput, bool) bool) error {
+	return ccs.Client.ListResourcesPages(input, fn)
+}
+
+// ConfigService is a struct that knows how to run advanced queries against an AWS
+// Config aggregator using an object that implements the Config Service API interface.
+type ConfigService struct {
+	Client configserviceiface.ConfigServiceAPI
+}
+
+// SelectAggregateResourceConfig takes an input specification (a query of an aggregator)
+// and a function that is invoked for each page of results. Each result is a JSON
+// document.
+func (cs *ConfigService) SelectAggregateResourceConfig(input *configservice.SelectAggregateResourceConfigInput,
+	fn func(*configservice.SelectAggregateResourceConfigOutput, bool) bool) error {
+	return cs.Client.SelectAggregateResourceConfigPages(input, fn)
+}
+
+// ResourceExplorerService is a struct that knows how to search the resource index of
+// AWS Resource Explorer using an object that implements the Resource Explorer API
+// interface.
+type ResourceExplorerService struct {
+	Client resourceexplorer2iface.ResourceExplorer2API
+}
+
+// Search takes an input specification (a query of the index) and returns a single page
+// of results, along with the number of resources that match the query.
+func (res *ResourceExplorerService) Search(input *resourceexplorer2.SearchInput) (*resourceexplorer2.SearchOutput, error) {
+	return res.Client.Search(input)
+}
+
+// GetIndex describes the index of the service's region (including whether it is the
+// LOCAL index of the region or the AGGREGATOR index of the account).
+func (res *ResourceExplorerService) GetIndex(input *resourceexplorer2.GetIndexInput) (*resourceexplorer2.GetIndexOutput, error) {
+	return res.Client.GetIndex(input)
+}
+
+// ListIndexes takes an input specification (such as the type of index) and returns a
+// single page of the indexes of the account, along with the region of each.
+func (res *ResourceExplorerService) ListIndexes(input *resourceexplorer2.ListIndexesInput) (*resourceexplorer2.ListIndexesOutput, error) {
+	return res.Client.ListIndexes(input)
+}
+
+// TaggingService is a struct that knows how to list the (tagged) resources of a region
+// using an object that implements the Resource Groups Tagging API interface.
+type TaggingService struct {
+	Client resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+}
+
+// GetResources takes an input specification (such as the resource types to list) and
+// a function that is invoked for each page of results.
+func (ts *TaggingService) GetResources(input *resourcegroupstaggingapi.GetResourcesInput,
+	fn func(*resourcegroupstaggingapi.GetResourcesOutput, bool) bool) error {
+	return ts.Client.GetResourcesPages(input, fn)
+}
+
+// GenericService is a struct that knows how to invoke any operation (by name) of an
+// AWS service client. It is used by custom counters, which name the operation to
+// invoke in configuration rather than in code.
+type GenericService struct {
+	Client interface{}
+}
+
+// ReadOnlyOperationPrefixes are the prefixes of the names of the operations that
+// GenericService may invoke. By AWS convention, these operations only read: this
+// tool counts resources, so it must never be able to change an account.
+var ReadOnlyOperationPrefixes = []string{"List", "Describe", "Get"}
+
+// IsReadOnlyOperation checks whether the named operation only reads (that is, it
+// starts with one of the ReadOnlyOperationPrefixes).
+func IsReadOnlyOperation(operation string) bool {
+	for _, prefix := range ReadOnlyOperationPrefixes {
+		if strings.HasPrefix(operation, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Invoke calls the named operation of our client. The supplied parameters are used
+// to fill in the operation's input struct (by field name). The output is returned as
+// plain JSON values (maps, slices, strings, numbers and booleans), such that it can be
+// examined without knowing the output struct. Only read-only operations (see
+// IsReadOnlyOperation) may be invoked.
+func (gs *GenericService) Invoke(operation string, params map[string]interface{}) (interface{}, error) {
+	// Refuse any operation that could change the account
+	if !IsReadOnlyOperation(operation) {
+		return nil, fmt.Errorf("%s is not a read-only (List, Describe or Get) operation", operation)
+	}
+
+	// Find the operation, which must have the form: Operation(*Input) (*Output, error)
+	method := reflect.ValueOf(gs.Client).MethodByName(operation)
+	if !method.IsValid() {
+		return nil, fmt.Errorf("%s is not an operation of this service", operation)
+	}
+	methodType := method.Type()
+	if methodType.NumIn() != 1 || methodType.In(0).Kind() != reflect.Ptr ||
+		methodType.NumOut() != 2 || methodType.Out(1) != reflect.TypeOf((*error)(nil)).Elem() {
+		return nil, fmt.Errorf("%s is not a supported operation", operation)
+	}
+
+	// Construct the input struct from our parameters
+	input := reflect.New(methodType.In(0).Elem())
+	encodedParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(encodedParams, input.Interface()); err != nil {
+		return nil, fmt.Errorf("Invalid input for %s: %v", operation, err)
+	}
+
+	// Invoke the operation
+	results := method.Call([]reflect.Value{input})
+	if err, _ := results[1].Interface().(error); err != nil {
+		return nil, err
+	}
+
+	// Convert the output struct into plain JSON values
+	encodedOutput, err := json.Marshal(results[0].Interface())
+	if err != nil {
+		return nil, err
+	}
+	var output interface{}
+	err = json.Unmarshal(encodedOutput, &output)
+
+	return output, err
+}
+
 // LambdaService is a struct that knows how to get all of the Lambda functions using
 // an object that implements the Lambda API interface
 type LambdaService struct {
@@ -152,8 +499,74 @@ func (cs *ContainerService) InspectTaskDefinition(input *ecs.DescribeTaskDefinit
 	return cs.Client.DescribeTaskDefinition(input)
 }
 
+// ListClusters takes an input specification (ListClustersInput) and a function that
+// is invoked for each page of results (ListClustersOutput). This allows a caller to
+// obtain a list of all ECS clusters.
+func (cs *ContainerService) ListClusters(input *ecs.ListClustersInput,
+	fn func(output *ecs.ListClustersOutput, lastPage bool) bool) error {
+	return cs.Client.ListClustersPages(input, fn)
+}
+
+// ListContainerInstances takes an input specification (ListContainerInstancesInput)
+// and a function that is invoked for each page of results (ListContainerInstancesOutput).
+// This allows a caller to obtain a list of all container instances in a cluster.
+func (cs *ContainerService) ListContainerInstances(input *ecs.ListContainerInstancesInput,
+	fn func(output *ecs.ListContainerInstancesOutput, lastPage bool) bool) error {
+	return cs.Client.ListContainerInstancesPages(input, fn)
+}
+
+// InspectContainerInstances takes an input specification (DescribeContainerInstancesInput)
+// that lists (up to 100) container instances in a cluster and returns information about
+// each, including the ID of the EC2 instance backing it.
+func (cs *ContainerService) InspectContainerInstances(input *ecs.DescribeContainerInstancesInput) (*ecs.DescribeContainerInstancesOutput, error) {
+	return cs.Client.DescribeContainerInstances(input)
+}
+
+// ListServices takes an input specification (ListServicesInput) and a function that
+// is invoked for each page of results (ListServicesOutput). This allows a caller to
+// obtain a list of all services in a cluster.
+func (cs *ContainerService) ListServices(input *ecs.ListServicesInput,
+	fn func(output *ecs.ListServicesOutput, lastPage bool) bool) error {
+	return cs.Client.ListServicesPages(input, fn)
+}
+
+// InspectServices takes an input specification (DescribeServicesInput) that lists
+// (up to 10) services in a cluster and returns information about each, including
+// the task definition it runs.
+func (cs *ContainerService) InspectServices(input *ecs.DescribeServicesInput) (*ecs.DescribeServicesOutput, error) {
+	return cs.Client.DescribeServices(input)
+}
+
+// ListTasks takes an input specification (ListTasksInput) and a function that is
+// invoked for each page of results (ListTasksOutput). This allows a caller to obtain
+// a list of all tasks in a cluster.
+func (cs *ContainerService) ListTasks(input *ecs.ListTasksInput,
+	fn func(output *ecs.ListTasksOutput, lastPage bool) bool) error {
+	return cs.Client.ListTasksPages(input, fn)
+}
+
+// InspectTasks takes an input specification (DescribeTasksInput) that lists (up to
+// 100) tasks in a cluster and returns information about each, including the task
+// definition it was started from.
+func (cs *ContainerService) InspectTasks(input *ecs.DescribeTasksInput) (*ecs.DescribeTasksOutput, error) {
+	return cs.Client.DescribeTasks(input)
+}
+
+// followPageTokens drives an API that the SDK offers no "Pages" variant of. It calls
+// fetch with the token of each page in turn (starting with the supplied one) until
+// fetch reports an error, returns an empty next token or asks to stop.
+func followPageTokens(token *string, fetch func(token *string) (next *string, more bool, err error)) error {
+	for {
+		next, more, err := fetch(token)
+		if err != nil || !more || aws.StringValue(next) == "" {
+			return err
+		}
+		token = next
+	}
+}
+
 // LightsailService is a struct that knows how to get a list of all Lightsail
-// instances and availble regions.
+// instances, databases, container services, load balancers and available regions.
 type LightsailService struct {
 	Client lightsailiface.LightsailAPI
 }
@@ -163,9 +576,386 @@ func (lss *LightsailService) GetRegions(input *lightsail.GetRegionsInput) (*ligh
 	return lss.Client.GetRegions(input)
 }
 
-// InspectInstances returns a full description of all Lightsail instances.
-func (lss *LightsailService) InspectInstances(input *lightsail.GetInstancesInput) (*lightsail.GetInstancesOutput, error) {
-	return lss.Client.GetInstances(input)
+// InspectInstances takes an input specification (GetInstancesInput) and a function
+// that is invoked for each page of results (GetInstancesOutput). This allows a caller
+// to obtain all of the Lightsail instances.
+func (lss *LightsailService) InspectInstances(input *lightsail.GetInstancesInput,
+	fn func(*lightsail.GetInstancesOutput, bool) bool) error {
+	pageInput := *input
+	return followPageTokens(input.PageToken, func(token *string) (*string, bool, error) {
+		pageInput.PageToken = token
+		output, err := lss.Client.GetInstances(&pageInput)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return output.NextPageToken, fn(output, aws.StringValue(output.NextPageToken) == ""), nil
+	})
+}
+
+// InspectRelationalDatabases takes an input specification
+// (GetRelationalDatabasesInput) and a function that is invoked for each page of
+// results (GetRelationalDatabasesOutput). This allows a caller to obtain all of the
+// Lightsail databases.
+func (lss *LightsailService) InspectRelationalDatabases(input *lightsail.GetRelationalDatabasesInput,
+	fn func(*lightsail.GetRelationalDatabasesOutput, bool) bool) error {
+	pageInput := *input
+	return followPageTokens(input.PageToken, func(token *string) (*string, bool, error) {
+		pageInput.PageToken = token
+		output, err := lss.Client.GetRelationalDatabases(&pageInput)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return output.NextPageToken, fn(output, aws.StringValue(output.NextPageToken) == ""), nil
+	})
+}
+
+// InspectContainerServices returns a full description of all Lightsail container
+// services. (This API is not paginated.)
+func (lss *LightsailService) InspectContainerServices(input *lightsail.GetContainerServicesInput) (*lightsail.GetContainerServicesOutput, error) {
+	return lss.Client.GetContainerServices(input)
+}
+
+// InspectLoadBalancers takes an input specification (GetLoadBalancersInput) and a
+// function that is invoked for each page of results (GetLoadBalancersOutput). This
+// allows a caller to obtain all of the Lightsail load balancers.
+func (lss *LightsailService) InspectLoadBalancers(input *lightsail.GetLoadBalancersInput,
+	fn func(*lightsail.GetLoadBalancersOutput, bool) bool) error {
+	pageInput := *input
+	return followPageTokens(input.PageToken, func(token *string) (*string, bool, error) {
+		pageInput.PageToken = token
+		output, err := lss.Client.GetLoadBalancers(&pageInput)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return output.NextPageToken, fn(output, aws.StringValue(output.NextPageToken) == ""), nil
+	})
+}
+
+// EKSService is a struct that knows how to get a list of all EKS clusters along with
+// the managed node groups and Fargate profiles of each.
+type EKSService struct {
+	Client eksiface.EKSAPI
+}
+
+// ListClusters takes an input specification (ListClustersInput) and a function that
+// is invoked for each page of results (ListClustersOutput). This allows a caller to
+// obtain the names of all EKS clusters.
+func (eksvc *EKSService) ListClusters(input *eks.ListClustersInput,
+	fn func(output *eks.ListClustersOutput, lastPage bool) bool) error {
+	return eksvc.Client.ListClustersPages(input, fn)
+}
+
+// InspectCluster takes an input specification (DescribeClusterInput) that names a
+// single cluster and returns information about it.
+func (eksvc *EKSService) InspectCluster(input *eks.DescribeClusterInput) (*eks.DescribeClusterOutput, error) {
+	return eksvc.Client.DescribeCluster(input)
+}
+
+// ListNodegroups takes an input specification (ListNodegroupsInput) and a function
+// that is invoked for each page of results (ListNodegroupsOutput). This allows a
+// caller to obtain the names of all managed node groups in a cluster.
+func (eksvc *EKSService) ListNodegroups(input *eks.ListNodegroupsInput,
+	fn func(output *eks.ListNodegroupsOutput, lastPage bool) bool) error {
+	return eksvc.Client.ListNodegroupsPages(input, fn)
+}
+
+// InspectNodegroup takes an input specification (DescribeNodegroupInput) that names
+// a single managed node group and returns information about it.
+func (eksvc *EKSService) InspectNodegroup(input *eks.DescribeNodegroupInput) (*eks.DescribeNodegroupOutput, error) {
+	return eksvc.Client.DescribeNodegroup(input)
+}
+
+// ListFargateProfiles takes an input specification (ListFargateProfilesInput) and a
+// function that is invoked for each page of results (ListFargateProfilesOutput). This
+// allows a caller to obtain the names of all Fargate profiles in a cluster.
+func (eksvc *EKSService) ListFargateProfiles(input *eks.ListFargateProfilesInput,
+	fn func(output *eks.ListFargateProfilesOutput, lastPage bool) bool) error {
+	return eksvc.Client.ListFargateProfilesPages(input, fn)
+}
+
+// ECRService is a struct that knows how to get a list of all ECR repositories
+// and the images stored in each.
+type ECRService struct {
+	Client ecriface.ECRAPI
+}
+
+// DescribeRepositories takes an input specification (DescribeRepositoriesInput) and
+// a function that is invoked for each page of results (DescribeRepositoriesOutput).
+// This allows a caller to obtain all of the repositories in a registry.
+func (ecrs *ECRService) DescribeRepositories(input *ecr.DescribeRepositoriesInput,
+	fn func(output *ecr.DescribeRepositoriesOutput, lastPage bool) bool) error {
+	return ecrs.Client.DescribeRepositoriesPages(input, fn)
+}
+
+// DescribeImages takes an input specification (DescribeImagesInput) and a function
+// that is invoked for each page of results (DescribeImagesOutput). This allows a
+// caller to obtain all of the images (and their tags) in a repository.
+func (ecrs *ECRService) DescribeImages(input *ecr.DescribeImagesInput,
+	fn func(output *ecr.DescribeImagesOutput, lastPage bool) bool) error {
+	return ecrs.Client.DescribeImagesPages(input, fn)
+}
+
+// DynamoDBService is a struct that knows how to get a list of all DynamoDB tables
+// (and, optionally, describe each of them).
+type DynamoDBService struct {
+	Client dynamodbiface.DynamoDBAPI
+}
+
+// ListTables takes an input specification (ListTablesInput) and a function that is
+// invoked for each page of results (ListTablesOutput). This allows a caller to
+// obtain the names of all DynamoDB tables.
+func (ddbs *DynamoDBService) ListTables(input *dynamodb.ListTablesInput,
+	fn func(output *dynamodb.ListTablesOutput, lastPage bool) bool) error {
+	return ddbs.Client.ListTablesPages(input, fn)
+}
+
+// InspectTable takes an input specification (DescribeTableInput) that names a
+// single table and returns information about it (such as its replicas).
+func (ddbs *DynamoDBService) InspectTable(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	return ddbs.Client.DescribeTable(input)
+}
+
+// ListGlobalTables takes an input specification (ListGlobalTablesInput) and returns
+// a page of the legacy (version 2017.11.29) global tables. The caller follows
+// LastEvaluatedGlobalTableName to get the next page.
+func (ddbs *DynamoDBService) ListGlobalTables(input *dynamodb.ListGlobalTablesInput) (*dynamodb.ListGlobalTablesOutput, error) {
+	return ddbs.Client.ListGlobalTables(input)
+}
+
+// ElastiCacheService is a struct that knows how to get a list of all ElastiCache
+// cache clusters and replication groups.
+type ElastiCacheService struct {
+	Client elasticacheiface.ElastiCacheAPI
+}
+
+// DescribeCacheClusters takes an input specification (DescribeCacheClustersInput)
+// and a function that is invoked for each page of results (DescribeCacheClustersOutput).
+// This allows a caller to obtain all of the cache clusters.
+func (ecsvc *ElastiCacheService) DescribeCacheClusters(input *elasticache.DescribeCacheClustersInput,
+	fn func(output *elasticache.DescribeCacheClustersOutput, lastPage bool) bool) error {
+	return ecsvc.Client.DescribeCacheClustersPages(input, fn)
+}
+
+// DescribeReplicationGroups takes an input specification (DescribeReplicationGroupsInput)
+// and a function that is invoked for each page of results (DescribeReplicationGroupsOutput).
+// This allows a caller to obtain all of the replication groups.
+func (ecsvc *ElastiCacheService) DescribeReplicationGroups(input *elasticache.DescribeReplicationGroupsInput,
+	fn func(output *elasticache.DescribeReplicationGroupsOutput, lastPage bool) bool) error {
+	return ecsvc.Client.DescribeReplicationGroupsPages(input, fn)
+}
+
+// RedshiftService is a struct that knows how to get a list of all (provisioned)
+// Redshift clusters.
+type RedshiftService struct {
+	Client redshiftiface.RedshiftAPI
+}
+
+// DescribeClusters takes an input specification (DescribeClustersInput) and a
+// function that is invoked for each page of results (DescribeClustersOutput). This
+// allows a caller to obtain all of the Redshift clusters.
+func (rss *RedshiftService) DescribeClusters(input *redshift.DescribeClustersInput,
+	fn func(output *redshift.DescribeClustersOutput, lastPage bool) bool) error {
+	return rss.Client.DescribeClustersPages(input, fn)
+}
+
+// RedshiftServerlessService is a struct that knows how to get a list of all Redshift
+// Serverless workgroups.
+type RedshiftServerlessService struct {
+	Client redshiftserverlessiface.RedshiftServerlessAPI
+}
+
+// ListWorkgroups takes an input specification (ListWorkgroupsInput) and a function
+// that is invoked for each page of results (ListWorkgroupsOutput). This allows a
+// caller to obtain all of the Redshift Serverless workgroups.
+func (rsss *RedshiftServerlessService) ListWorkgroups(input *redshiftserverless.ListWorkgroupsInput,
+	fn func(output *redshiftserverless.ListWorkgroupsOutput, lastPage bool) bool) error {
+	return rsss.Client.ListWorkgroupsPages(input, fn)
+}
+
+// OpenSearchService is a struct that knows how to get a list of all OpenSearch
+// (and Elasticsearch) domains.
+type OpenSearchService struct {
+	Client opensearchserviceiface.OpenSearchServiceAPI
+}
+
+// ListDomainNames takes an input specification (ListDomainNamesInput) and returns
+// the names of all domains. (This API does not page its results.)
+func (oss *OpenSearchService) ListDomainNames(input *opensearchservice.ListDomainNamesInput) (*opensearchservice.ListDomainNamesOutput, error) {
+	return oss.Client.ListDomainNames(input)
+}
+
+// DocumentDBService is a struct that knows how to get a list of all DocumentDB
+// clusters.
+type DocumentDBService struct {
+	Client docdbiface.DocDBAPI
+}
+
+// DescribeDBClusters takes an input filter specification (DescribeDBClustersInput)
+// and a function that is invoked for each page of results (DescribeDBClustersOutput).
+// This allows a caller to obtain all of the DocumentDB clusters.
+func (ddbs *DocumentDBService) DescribeDBClusters(input *docdb.DescribeDBClustersInput,
+	fn func(output *docdb.DescribeDBClustersOutput, lastPage bool) bool) error {
+	return ddbs.Client.DescribeDBClustersPages(input, fn)
+}
+
+// NeptuneService is a struct that knows how to get a list of all Neptune clusters.
+type NeptuneService struct {
+	Client neptuneiface.NeptuneAPI
+}
+
+// DescribeDBClusters takes an input filter specification (DescribeDBClustersInput)
+// and a function that is invoked for each page of results (DescribeDBClustersOutput).
+// This allows a caller to obtain all of the Neptune clusters.
+func (ns *NeptuneService) DescribeDBClusters(input *neptune.DescribeDBClustersInput,
+	fn func(output *neptune.DescribeDBClustersOutput, lastPage bool) bool) error {
+	return ns.Client.DescribeDBClustersPages(input, fn)
+}
+
+// ELBService is a struct that knows how to get a list of all Classic Load Balancers.
+type ELBService struct {
+	Client elbiface.ELBAPI
+}
+
+// DescribeLoadBalancers takes an input specification (DescribeLoadBalancersInput)
+// and a function that is invoked for each page of results (DescribeLoadBalancersOutput).
+// This allows a caller to obtain all of the Classic Load Balancers.
+func (elbs *ELBService) DescribeLoadBalancers(input *elb.DescribeLoadBalancersInput,
+	fn func(output *elb.DescribeLoadBalancersOutput, lastPage bool) bool) error {
+	return elbs.Client.DescribeLoadBalancersPages(input, fn)
+}
+
+// ELBv2Service is a struct that knows how to get a list of all Application, Network
+// and Gateway Load Balancers.
+type ELBv2Service struct {
+	Client elbv2iface.ELBV2API
+}
+
+// DescribeLoadBalancers takes an input specification (DescribeLoadBalancersInput)
+// and a function that is invoked for each page of results (DescribeLoadBalancersOutput).
+// This allows a caller to obtain all of the (v2) load balancers.
+func (elbs *ELBv2Service) DescribeLoadBalancers(input *elbv2.DescribeLoadBalancersInput,
+	fn func(output *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool) error {
+	return elbs.Client.DescribeLoadBalancersPages(input, fn)
+}
+
+// StepFunctionsService is a struct that knows how to get a list of all Step Functions
+// state machines.
+type StepFunctionsService struct {
+	Client sfniface.SFNAPI
+}
+
+// ListStateMachines takes an input specification (ListStateMachinesInput) and a
+// function that is invoked for each page of results (ListStateMachinesOutput). This
+// allows a caller to obtain all of the state machines.
+func (sfs *StepFunctionsService) ListStateMachines(input *sfn.ListStateMachinesInput,
+	fn func(output *sfn.ListStateMachinesOutput, lastPage bool) bool) error {
+	return sfs.Client.ListStateMachinesPages(input, fn)
+}
+
+// APIGatewayService is a struct that knows how to get a list of all API Gateway REST
+// APIs.
+type APIGatewayService struct {
+	Client apigatewayiface.APIGatewayAPI
+}
+
+// GetRestApis takes an input specification (GetRestApisInput) and a function that is
+// invoked for each page of results (GetRestApisOutput). This allows a caller to
+// obtain all of the REST APIs.
+func (ags *APIGatewayService) GetRestApis(input *apigateway.GetRestApisInput,
+	fn func(output *apigateway.GetRestApisOutput, lastPage bool) bool) error {
+	return ags.Client.GetRestApisPages(input, fn)
+}
+
+// APIGatewayV2Service is a struct that knows how to get a list of all API Gateway
+// HTTP and WebSocket APIs.
+type APIGatewayV2Service struct {
+	Client apigatewayv2iface.ApiGatewayV2API
+}
+
+// GetApis takes an input specification (GetApisInput) and a function that is invoked
+// for each page of results (GetApisOutput). This allows a caller to obtain all of the
+// HTTP and WebSocket APIs.
+func (ags *APIGatewayV2Service) GetApis(input *apigatewayv2.GetApisInput,
+	fn func(output *apigatewayv2.GetApisOutput, lastPage bool) bool) error {
+	pageInput := *input
+	return followPageTokens(input.NextToken, func(token *string) (*string, bool, error) {
+		pageInput.NextToken = token
+		output, err := ags.Client.GetApis(&pageInput)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return output.NextToken, fn(output, aws.StringValue(output.NextToken) == ""), nil
+	})
+}
+
+// SQSService is a struct that knows how to get a list of all SQS queues.
+type SQSService struct {
+	Client sqsiface.SQSAPI
+}
+
+// ListQueues takes an input specification (ListQueuesInput) and a function that is
+// invoked for each page of results (ListQueuesOutput). This allows a caller to obtain
+// all of the queue URLs.
+func (sqss *SQSService) ListQueues(input *sqs.ListQueuesInput,
+	fn func(output *sqs.ListQueuesOutput, lastPage bool) bool) error {
+	return sqss.Client.ListQueuesPages(input, fn)
+}
+
+// SNSService is a struct that knows how to get a list of all SNS topics.
+type SNSService struct {
+	Client snsiface.SNSAPI
+}
+
+// ListTopics takes an input specification (ListTopicsInput) and a function that is
+// invoked for each page of results (ListTopicsOutput). This allows a caller to obtain
+// all of the topic ARNs.
+func (snss *SNSService) ListTopics(input *sns.ListTopicsInput,
+	fn func(output *sns.ListTopicsOutput, lastPage bool) bool) error {
+	return snss.Client.ListTopicsPages(input, fn)
+}
+
+// EventBridgeService is a struct that knows how to get a list of all EventBridge event
+// buses and the rules on each.
+type EventBridgeService struct {
+	Client eventbridgeiface.EventBridgeAPI
+}
+
+// ListEventBuses takes an input specification (ListEventBusesInput) and a function
+// that is invoked for each page of results (ListEventBusesOutput). This allows a
+// caller to obtain all of the event buses.
+func (ebs *EventBridgeService) ListEventBuses(input *eventbridge.ListEventBusesInput,
+	fn func(output *eventbridge.ListEventBusesOutput, lastPage bool) bool) error {
+	pageInput := *input
+	return followPageTokens(input.NextToken, func(token *string) (*string, bool, error) {
+		pageInput.NextToken = token
+		output, err := ebs.Client.ListEventBuses(&pageInput)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return output.NextToken, fn(output, aws.StringValue(output.NextToken) == ""), nil
+	})
+}
+
+// ListRules takes an input specification (ListRulesInput, naming the event bus) and a
+// function that is invoked for each page of results (ListRulesOutput). This allows a
+// caller to obtain all of the rules of an event bus.
+func (ebs *EventBridgeService) ListRules(input *eventbridge.ListRulesInput,
+	fn func(output *eventbridge.ListRulesOutput, lastPage bool) bool) error {
+	pageInput := *input
+	return followPageTokens(input.NextToken, func(token *string) (*string, bool, error) {
+		pageInput.NextToken = token
+		output, err := ebs.Client.ListRules(&pageInput)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return output.NextToken, fn(output, aws.StringValue(output.NextToken) == ""), nil
+	})
 }
 
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -176,6 +966,7 @@ func (lss *LightsailService) InspectInstances(input *lightsail.GetInstancesInput
 type ServiceFactory interface {
 	Init()
 	GetCurrentRegion() string
+	GetCredentials() *credentials.Credentials
 	GetAccountIDService() *AccountIDService
 	GetEC2InstanceService(string) *EC2InstanceService
 	GetRDSInstanceService(string) *RDSInstanceService
@@ -183,6 +974,30 @@ type ServiceFactory interface {
 	GetLambdaService(string) *LambdaService
 	GetContainerService(string) *ContainerService
 	GetLightsailService(string) *LightsailService
+	GetEKSService(string) *EKSService
+	GetECRService(string) *ECRService
+	GetDynamoDBService(string) *DynamoDBService
+	GetElastiCacheService(string) *ElastiCacheService
+	GetRedshiftService(string) *RedshiftService
+	GetRedshiftServerlessService(string) *RedshiftServerlessService
+	GetOpenSearchService(string) *OpenSearchService
+	GetDocumentDBService(string) *DocumentDBService
+	GetNeptuneService(string) *NeptuneService
+	GetELBService(string) *ELBService
+	GetELBv2Service(string) *ELBv2Service
+	GetCloudFrontService() *CloudFrontService
+	GetStepFunctionsService(string) *StepFunctionsService
+	GetAPIGatewayService(string) *APIGatewayService
+	GetAPIGatewayV2Service(string) *APIGatewayV2Service
+	GetSQSService(string) *SQSService
+	GetSNSService(string) *SNSService
+	GetEventBridgeService(string) *EventBridgeService
+	GetCloudWatchService(string) *CloudWatchService
+	GetCloudControlService(string) *CloudControlService
+	GetConfigService(string) *ConfigService
+	GetResourceExplorerService(string) *ResourceExplorerService
+	GetTaggingService(string) *TaggingService
+	GetGenericService(string, string) *GenericService
 }
 
 // AWSServiceFactory is a struct that holds a reference to
@@ -262,6 +1077,11 @@ func (awssf *AWSServiceFactory) GetCurrentRegion() string {
 	return *awssf.Session.Config.Region
 }
 
+// GetCredentials returns the credentials associated with our session.
+func (awssf *AWSServiceFactory) GetCredentials() *credentials.Credentials {
+	return awssf.Session.Config.Credentials
+}
+
 // GetAccountIDService returns an instance of an AccountIDService associated
 // with our session.
 func (awssf *AWSServiceFactory) GetAccountIDService() *AccountIDService {
@@ -362,3 +1182,411 @@ func (awssf *AWSServiceFactory) GetLightsailService(regionName string) *Lightsai
 		Client: client,
 	}
 }
+
+// GetEKSService returns an instance of an EKSService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetEKSService(regionName string) *EKSService {
+	// Construct our service client
+	var client eksiface.EKSAPI
+	if regionName == "" {
+		client = eks.New(awssf.Session)
+	} else {
+		client = eks.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &EKSService{
+		Client: client,
+	}
+}
+
+// GetECRService returns an instance of an ECRService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetECRService(regionName string) *ECRService {
+	// Construct our service client
+	var client ecriface.ECRAPI
+	if regionName == "" {
+		client = ecr.New(awssf.Session)
+	} else {
+		client = ecr.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &ECRService{
+		Client: client,
+	}
+}
+
+// GetDynamoDBService returns an instance of a DynamoDBService associated with our
+// session. The caller can supply an optional region name to construct an instance
+// associated with that region.
+func (awssf *AWSServiceFactory) GetDynamoDBService(regionName string) *DynamoDBService {
+	// Construct our service client
+	var client dynamodbiface.DynamoDBAPI
+	if regionName == "" {
+		client = dynamodb.New(awssf.Session)
+	} else {
+		client = dynamodb.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &DynamoDBService{
+		Client: client,
+	}
+}
+
+// GetElastiCacheService returns an instance of an ElastiCacheService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetElastiCacheService(regionName string) *ElastiCacheService {
+	// Construct our service client
+	var client elasticacheiface.ElastiCacheAPI
+	if regionName == "" {
+		client = elasticache.New(awssf.Session)
+	} else {
+		client = elasticache.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &ElastiCacheService{
+		Client: client,
+	}
+}
+
+// GetRedshiftService returns an instance of a RedshiftService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetRedshiftService(regionName string) *RedshiftService {
+	// Construct our service client
+	var client redshiftiface.RedshiftAPI
+	if regionName == "" {
+		client = redshift.New(awssf.Session)
+	} else {
+		client = redshift.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &RedshiftService{
+		Client: client,
+	}
+}
+
+// GetRedshiftServerlessService returns an instance of a RedshiftServerlessService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetRedshiftServerlessService(regionName string) *RedshiftServerlessService {
+	// Construct our service client
+	var client redshiftserverlessiface.RedshiftServerlessAPI
+	if regionName == "" {
+		client = redshiftserverless.New(awssf.Session)
+	} else {
+		client = redshiftserverless.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &RedshiftServerlessService{
+		Client: client,
+	}
+}
+
+// GetOpenSearchService returns an instance of an OpenSearchService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetOpenSearchService(regionName string) *OpenSearchService {
+	// Construct our service client
+	var client opensearchserviceiface.OpenSearchServiceAPI
+	if regionName == "" {
+		client = opensearchservice.New(awssf.Session)
+	} else {
+		client = opensearchservice.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &OpenSearchService{
+		Client: client,
+	}
+}
+
+// GetDocumentDBService returns an instance of a DocumentDBService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetDocumentDBService(regionName string) *DocumentDBService {
+	// Construct our service client
+	var client docdbiface.DocDBAPI
+	if regionName == "" {
+		client = docdb.New(awssf.Session)
+	} else {
+		client = docdb.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &DocumentDBService{
+		Client: client,
+	}
+}
+
+// GetNeptuneService returns an instance of a NeptuneService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetNeptuneService(regionName string) *NeptuneService {
+	// Construct our service client
+	var client neptuneiface.NeptuneAPI
+	if regionName == "" {
+		client = neptune.New(awssf.Session)
+	} else {
+		client = neptune.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &NeptuneService{
+		Client: client,
+	}
+}
+
+// GetELBService returns an instance of an ELBService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetELBService(regionName string) *ELBService {
+	// Construct our service client
+	var client elbiface.ELBAPI
+	if regionName == "" {
+		client = elb.New(awssf.Session)
+	} else {
+		client = elb.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &ELBService{
+		Client: client,
+	}
+}
+
+// GetELBv2Service returns an instance of an ELBv2Service associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetELBv2Service(regionName string) *ELBv2Service {
+	// Construct our service client
+	var client elbv2iface.ELBV2API
+	if regionName == "" {
+		client = elbv2.New(awssf.Session)
+	} else {
+		client = elbv2.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &ELBv2Service{
+		Client: client,
+	}
+}
+
+// GetCloudFrontService returns an instance of a CloudFrontService associated with our
+// session. CloudFront is a global service, so there is no region to supply.
+func (awssf *AWSServiceFactory) GetCloudFrontService() *CloudFrontService {
+	return &CloudFrontService{
+		Client: cloudfront.New(awssf.Session),
+	}
+}
+
+// GetStepFunctionsService returns an instance of a StepFunctionsService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetStepFunctionsService(regionName string) *StepFunctionsService {
+	// Construct our service client
+	var client sfniface.SFNAPI
+	if regionName == "" {
+		client = sfn.New(awssf.Session)
+	} else {
+		client = sfn.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &StepFunctionsService{
+		Client: client,
+	}
+}
+
+// GetAPIGatewayService returns an instance of an APIGatewayService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetAPIGatewayService(regionName string) *APIGatewayService {
+	// Construct our service client
+	var client apigatewayiface.APIGatewayAPI
+	if regionName == "" {
+		client = apigateway.New(awssf.Session)
+	} else {
+		client = apigateway.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &APIGatewayService{
+		Client: client,
+	}
+}
+
+// GetAPIGatewayV2Service returns an instance of an APIGatewayV2Service associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetAPIGatewayV2Service(regionName string) *APIGatewayV2Service {
+	// Construct our service client
+	var client apigatewayv2iface.ApiGatewayV2API
+	if regionName == "" {
+		client = apigatewayv2.New(awssf.Session)
+	} else {
+		client = apigatewayv2.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &APIGatewayV2Service{
+		Client: client,
+	}
+}
+
+// GetSQSService returns an instance of an SQSService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetSQSService(regionName string) *SQSService {
+	// Construct our service client
+	var client sqsiface.SQSAPI
+	if regionName == "" {
+		client = sqs.New(awssf.Session)
+	} else {
+		client = sqs.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &SQSService{
+		Client: client,
+	}
+}
+
+// GetSNSService returns an instance of an SNSService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetSNSService(regionName string) *SNSService {
+	// Construct our service client
+	var client snsiface.SNSAPI
+	if regionName == "" {
+		client = sns.New(awssf.Session)
+	} else {
+		client = sns.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &SNSService{
+		Client: client,
+	}
+}
+
+// GetEventBridgeService returns an instance of an EventBridgeService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetEventBridgeService(regionName string) *EventBridgeService {
+	// Construct our service client
+	var client eventbridgeiface.EventBridgeAPI
+	if regionName == "" {
+		client = eventbridge.New(awssf.Session)
+	} else {
+		client = eventbridge.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &EventBridgeService{
+		Client: client,
+	}
+}
+
+// GetCloudWatchService returns an instance of a CloudWatchService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetCloudWatchService(regionName string) *CloudWatchService {
+	// Construct our service client
+	var client cloudwatchiface.CloudWatchAPI
+	if regionName == "" {
+		client = cloudwatch.New(awssf.Session)
+	} else {
+		client = cloudwatch.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &CloudWatchService{
+		Client: client,
+	}
+}
+
+// GetCloudControlService returns an instance of a CloudControlService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetCloudControlService(regionName string) *CloudControlService {
+	// Construct our service client
+	var client cloudcontrolapiiface.CloudControlApiAPI
+	if regionName == "" {
+		client = cloudcontrolapi.New(awssf.Session)
+	} else {
+		client = cloudcontrolapi.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &CloudControlService{
+		Client: client,
+	}
+}
+
+// GetConfigService returns an instance of a ConfigService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region (such as the region of an aggregator).
+func (awssf *AWSServiceFactory) GetConfigService(regionName string) *ConfigService {
+	// Construct our service client
+	var client configserviceiface.ConfigServiceAPI
+	if regionName == "" {
+		client = configservice.New(awssf.Session)
+	} else {
+		client = configservice.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &ConfigService{
+		Client: client,
+	}
+}
+
+// GetResourceExplorerService returns an instance of a ResourceExplorerService associated
+// with our session. The caller can supply an optional region name to construct an
+// instance associated with that region.
+func (awssf *AWSServiceFactory) GetResourceExplorerService(regionName string) *ResourceExplorerService {
+	// Construct our service client
+	var client resourceexplorer2iface.ResourceExplorer2API
+	if regionName == "" {
+		client = resourceexplorer2.New(awssf.Session)
+	} else {
+		client = resourceexplorer2.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &ResourceExplorerService{
+		Client: client,
+	}
+}
+
+// GetTaggingService returns an instance of a TaggingService associated with our session.
+// The caller can supply an optional region name to construct an instance associated
+// with that region.
+func (awssf *AWSServiceFactory) GetTaggingService(regionName string) *TaggingService {
+	// Construct our service client
+	var client resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+	if regionName == "" {
+		client = resourcegroupstaggingapi.New(awssf.Session)
+	} else {
+		client = resourcegroupstaggingapi.New(awssf.Session, aws.NewConfig().WithRegion(regionName))
+	}
+
+	return &TaggingService{
+		Client: client,
+	}
+}
+
+// GetGenericService returns an instance of a GenericService for the named service (see
+// IsGenericServiceName) associated with our session. The caller can supply an optional
+// region name to construct an instance associated with that region. If the service is
+// not known, then nil is returned.
+func (awssf *AWSServiceFactory) GetGenericService(serviceName string, regionName string) *GenericService {
+	// Find the constructor of the service client
+	constructor, ok := genericServiceConstructors[serviceName]
+	if !ok {
+		return nil
+	}
+
+	// Construct our service client
+	var configs []*aws.Config
+	if regionName != "" {
+		configs = append(configs, aws.NewConfig().WithRegion(regionName))
+	}
+	client := reflect.ValueOf(constructor).CallSlice([]reflect.Value{
+		reflect.ValueOf(awssf.Session),
+		reflect.ValueOf(configs),
+	})[0].Interface()
+
+	return &GenericService{
+		Client: client,
+	}
+}
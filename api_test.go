@@ -7,11 +7,34 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go/service/cloudcontrolapi"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/configservice"
+	"github.com/aws/aws-sdk-go/service/docdb"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/lightsail"
+	"github.com/aws/aws-sdk-go/service/neptune"
+	"github.com/aws/aws-sdk-go/service/opensearchservice"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/redshift"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless"
+	"github.com/aws/aws-sdk-go/service/resourceexplorer2"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
 )
 
 func TestAwsServiceFactoryRegionResolution(t *testing.T) {
@@ -386,3 +409,1145 @@ func TestAwsServiceFactoryGetLightsailService(t *testing.T) {
 		}
 	}
 }
+
+func TestAwsServiceFactoryGetEKSService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetEKSService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetEKSService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*eks.EKS)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*eks.EKS", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetECRService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetECRService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetECRService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*ecr.ECR)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*ecr.ECR", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetDynamoDBService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetDynamoDBService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetDynamoDBService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*dynamodb.DynamoDB)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*dynamodb.DynamoDB", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetElastiCacheService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetElastiCacheService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetElastiCacheService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*elasticache.ElastiCache)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*elasticache.ElastiCache", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetRedshiftService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetRedshiftService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetRedshiftService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*redshift.Redshift)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*redshift.Redshift", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetRedshiftServerlessService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetRedshiftServerlessService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetRedshiftServerlessService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*redshiftserverless.RedshiftServerless)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*redshiftserverless.RedshiftServerless", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetOpenSearchService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetOpenSearchService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetOpenSearchService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*opensearchservice.OpenSearchService)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*opensearchservice.OpenSearchService", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetDocumentDBService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetDocumentDBService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetDocumentDBService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*docdb.DocDB)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*docdb.DocDB", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetNeptuneService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetNeptuneService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetNeptuneService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*neptune.Neptune)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*neptune.Neptune", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetELBService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetELBService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetELBService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*elb.ELB)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*elb.ELB", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetELBv2Service(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetELBv2Service(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetELBv2Service")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*elbv2.ELBV2)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*elbv2.ELBV2", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetCloudFrontService(t *testing.T) {
+	// Create a new session
+	session, err := session.NewSession()
+	if err != nil {
+		t.Errorf("Unexpected error while creating a new session: %v", err)
+	}
+
+	// Create an AWS Service Factory
+	sf := &AWSServiceFactory{
+		Session: session,
+	}
+
+	// Get the desired service
+	service := sf.GetCloudFrontService()
+
+	// Is the service nil?
+	if service == nil {
+		t.Errorf("No service returned for %s", "GetCloudFrontService")
+	}
+}
+
+func TestAwsServiceFactoryGetStepFunctionsService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetStepFunctionsService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetStepFunctionsService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*sfn.SFN)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*sfn.SFN", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetAPIGatewayService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetAPIGatewayService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetAPIGatewayService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*apigateway.APIGateway)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*apigateway.APIGateway", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetAPIGatewayV2Service(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetAPIGatewayV2Service(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetAPIGatewayV2Service")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*apigatewayv2.ApiGatewayV2)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*apigatewayv2.ApiGatewayV2", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetSQSService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetSQSService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetSQSService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*sqs.SQS)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*sqs.SQS", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetSNSService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetSNSService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetSNSService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*sns.SNS)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*sns.SNS", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetEventBridgeService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetEventBridgeService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetEventBridgeService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*eventbridge.EventBridge)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*eventbridge.EventBridge", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetCloudWatchService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetCloudWatchService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetCloudWatchService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*cloudwatch.CloudWatch)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*cloudwatch.CloudWatch", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetCloudControlService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetCloudControlService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetCloudControlService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*cloudcontrolapi.CloudControlApi)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*cloudcontrolapi.CloudControlApi", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetConfigService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetConfigService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetConfigService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*configservice.ConfigService)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*configservice.ConfigService", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetResourceExplorerService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetResourceExplorerService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetResourceExplorerService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*resourceexplorer2.ResourceExplorer2)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*resourceexplorer2.ResourceExplorer2", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetTaggingService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName string
+	}{
+		{},
+		{
+			RegionName: "us-west-1",
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetTaggingService(c.RegionName)
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetTaggingService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*resourcegroupstaggingapi.ResourceGroupsTaggingAPI)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*resourcegroupstaggingapi.ResourceGroupsTaggingAPI", implType)
+			} else if *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected value for Client.Config.Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
+
+func TestAwsServiceFactoryGetGenericService(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		ServiceName string
+		RegionName  string
+		ExpectNil   bool
+	}{
+		{
+			ServiceName: "kms",
+		},
+		{
+			ServiceName: "kms",
+			RegionName:  "us-west-1",
+		},
+		{
+			ServiceName: "bingo",
+			ExpectNil:   true,
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a config for the region?
+		var config = &aws.Config{}
+		if c.RegionName != "" {
+			config = config.WithRegion(c.RegionName)
+		}
+
+		// Create our test
+		session, err := session.NewSession(config)
+		if err != nil {
+			t.Errorf("Unexpected error while creating a new session: %v", err)
+		}
+
+		// Create an AWS Service Factory
+		sf := &AWSServiceFactory{
+			Session: session,
+		}
+
+		// Get the desired service
+		service := sf.GetGenericService(c.ServiceName, c.RegionName)
+
+		// Did we expect no service?
+		if c.ExpectNil {
+			if service != nil {
+				t.Errorf("Unexpected service returned for %s", c.ServiceName)
+			}
+			continue
+		}
+
+		// Is the service nil?
+		if service == nil {
+			t.Errorf("No service returned for %s", "GetGenericService")
+		} else if service.Client != nil {
+			// Convert to implementation type
+			implType, ok := service.Client.(*kms.KMS)
+			if !ok {
+				t.Errorf("Unexpected Client type: expected %v, actual %v", "*kms.KMS", implType)
+			} else if c.RegionName != "" && *implType.Config.Region != c.RegionName {
+				t.Errorf("Unexpected Region: expected %s, actual %s", c.RegionName, *implType.Config.Region)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+/******************************************************************************
+Cloud Resource Counter
+File: backends.go
+
+Summary: Defines the backends that can produce resource counts.
+******************************************************************************/
+
+package main
+
+// CountingBackend is the source of our resource counts.
+type CountingBackend int
+
+const (
+	// APIBackend counts resources by calling the APIs of each AWS service in each
+	// region. This is the most accurate backend.
+	APIBackend CountingBackend = iota
+
+	// ConfigBackend counts resources by querying an AWS Config aggregator. It counts
+	// the resources of every account in the aggregator at once, but only those
+	// resources that AWS Config records.
+	ConfigBackend
+
+	// IndexBackend counts resources by searching the index of AWS Resource Explorer
+	// (or, if it is not available, by listing resources with the Resource Groups
+	// Tagging API). It is quick and needs few permissions, but its counts are only
+	// approximate.
+	IndexBackend
+)
+
+// The names of each CountingBackend (as supplied on the command line)
+var countingBackendNames = map[CountingBackend]string{
+	APIBackend:    "api",
+	ConfigBackend: "config",
+	IndexBackend:  "index",
+}
+
+// ParseCountingBackend returns the CountingBackend with the supplied name. The
+// second return value is false if the name is not valid.
+func ParseCountingBackend(name string) (CountingBackend, bool) {
+	for backend, backendName := range countingBackendNames {
+		if backendName == name {
+			return backend, true
+		}
+	}
+
+	return APIBackend, false
+}
+
+// String returns the name of the CountingBackend.
+func (backend CountingBackend) String() string {
+	return countingBackendNames[backend]
+}
@@ -0,0 +1,147 @@
+/******************************************************************************
+Cloud Resource Counter
+File: cloudControl.go
+
+Summary: Provides a count of the resources of any CloudFormation resource type
+         (such as AWS::KMS::Key) supported by the AWS Cloud Control API.
+******************************************************************************/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudcontrolapi"
+
+	color "github.com/logrusorgru/aurora"
+)
+
+// The form of a CloudFormation resource type name: Organization::Service::Resource
+var resourceTypeNameRegex = regexp.MustCompile(`^[A-Za-z0-9]+::[A-Za-z0-9]+::[A-Za-z0-9]+$`)
+
+// The services whose resources are global: listing their resources in any region
+// returns the resources of ALL REGIONS.
+var globalResourceServices = map[string]bool{
+	"CloudFront":    true,
+	"IAM":           true,
+	"Organizations": true,
+	"Route53":       true,
+}
+
+// ResourceTypesFile is the format of a resource types (JSON) file. For example, this
+// counts KMS keys and SQS queues:
+//
+//   {
+//     "resourceTypes": ["AWS::KMS::Key", "AWS::SQS::Queue"]
+//   }
+type ResourceTypesFile struct {
+	ResourceTypes []string `json:"resourceTypes"`
+}
+
+// LoadResourceTypes reads the CloudFormation resource type names from the named
+// file, checking that each is valid.
+func LoadResourceTypes(fileName string) ([]string, error) {
+	// Read and parse the file
+	contents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var typesFile ResourceTypesFile
+	if err = json.Unmarshal(contents, &typesFile); err != nil {
+		return nil, fmt.Errorf("%s is not a valid resource types file: %v", fileName, err)
+	}
+
+	// Check each resource type
+	for _, typeName := range typesFile.ResourceTypes {
+		if !IsValidResourceTypeName(typeName) {
+			return nil, fmt.Errorf("%s: '%s' is not a valid resource type (such as AWS::SQS::Queue)", fileName, typeName)
+		}
+	}
+
+	return typesFile.ResourceTypes, nil
+}
+
+// IsValidResourceTypeName returns true if the supplied name has the form of a
+// CloudFormation resource type name (e.g., "AWS::SQS::Queue").
+func IsValidResourceTypeName(typeName string) bool {
+	return resourceTypeNameRegex.MatchString(typeName)
+}
+
+// IsGlobalResourceType returns true if the resources of the supplied CloudFormation
+// resource type are global (such as "AWS::IAM::Role"), and so cannot be counted on a
+// per-region basis.
+func IsGlobalResourceType(typeName string) bool {
+	parts := strings.Split(typeName, "::")
+	return len(parts) == 3 && globalResourceServices[parts[1]]
+}
+
+// CloudControlResources retrieves the count of all resources of the supplied
+// CloudFormation resource type either for all regions (allRegions is true) or the
+// region associated with the session. The resources of a global resource type are
+// counted once (in the default region); that count is for ALL REGIONS, even when a
+// single region is specified.
+//
+// This method gives status back to the user via the supplied ActivityMonitor
+// instance.
+func CloudControlResources(sf ServiceFactory, am ActivityMonitor, allRegions bool, typeName string) int {
+	// Indicate activity
+	am.StartAction("Retrieving %s counts", typeName)
+
+	// Should we get the counts for all regions?
+	resourceCount := 0
+	var qualify string
+	if IsGlobalResourceType(typeName) {
+		// Get the count once for all regions
+		resourceCount = cloudControlResourcesForSingleRegion(sf.GetCloudControlService(DefaultRegion), am, typeName)
+
+		// Should we "qualify" our count?
+		if !allRegions && resourceCount > 0 {
+			qualify = "*"
+		}
+	} else if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the resource count for a specific region
+			resourceCount += cloudControlResourcesForSingleRegion(sf.GetCloudControlService(regionName), am, typeName)
+		}
+	} else {
+		// Get the resource count for the region selected by this session
+		resourceCount = cloudControlResourcesForSingleRegion(sf.GetCloudControlService(""), am, typeName)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d%s)", color.Bold(resourceCount), qualify)
+
+	return resourceCount
+}
+
+func cloudControlResourcesForSingleRegion(ccs *CloudControlService, am ActivityMonitor, typeName string) int {
+	// Construct our input to find all resources of the type
+	input := &cloudcontrolapi.ListResourcesInput{
+		TypeName: aws.String(typeName),
+	}
+
+	// Indicate activity
+	am.Message(".")
+
+	// Invoke our service
+	resourceCount := 0
+	err := ccs.ListResources(input, func(page *cloudcontrolapi.ListResourcesOutput, lastPage bool) bool {
+		resourceCount += len(page.ResourceDescriptions)
+
+		return true
+	})
+
+	// Check for error
+	am.CheckError(err)
+
+	return resourceCount
+}
@@ -0,0 +1,342 @@
+/******************************************************************************
+Cloud Resource Counter
+File: cloudControl_test.go
+
+Summary: The Unit Test for cloudControl.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudcontrolapi"
+	"github.com/aws/aws-sdk-go/service/cloudcontrolapi/cloudcontrolapiiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Cloud Control Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// Construct a page of resource descriptions with the supplied identifiers
+func fakeResourcesPage(identifiers ...string) *cloudcontrolapi.ListResourcesOutput {
+	page := &cloudcontrolapi.ListResourcesOutput{
+		ResourceDescriptions: []*cloudcontrolapi.ResourceDescription{},
+	}
+	for _, identifier := range identifiers {
+		page.ResourceDescriptions = append(page.ResourceDescriptions, &cloudcontrolapi.ResourceDescription{
+			Identifier: aws.String(identifier),
+		})
+	}
+
+	return page
+}
+
+// This is our map of regions and the pages of resources (by type) in each. A type
+// that is missing from a region simulates an error.
+var cloudControlPerRegion = map[string]map[string][]*cloudcontrolapi.ListResourcesOutput{
+	// US-EAST-1 has 3 KMS keys (over two pages) and 2 SQS queues. Global resources
+	// (4 IAM roles) are only listed here.
+	"us-east-1": {
+		"AWS::KMS::Key": {
+			fakeResourcesPage("key-1", "key-2"),
+			fakeResourcesPage("key-3"),
+		},
+		"AWS::SQS::Queue": {
+			fakeResourcesPage("queue-1", "queue-2"),
+		},
+		"AWS::IAM::Role": {
+			fakeResourcesPage("role-1", "role-2", "role-3"),
+			fakeResourcesPage("role-4"),
+		},
+	},
+
+	// US-EAST-2 has no KMS keys and 1 SQS queue
+	"us-east-2": {
+		"AWS::KMS::Key": {
+			fakeResourcesPage(),
+		},
+		"AWS::SQS::Queue": {
+			fakeResourcesPage("queue-3"),
+		},
+	},
+
+	// AF-SOUTH-1 has 2 KMS keys, but SQS queues cannot be listed
+	"af-south-1": {
+		"AWS::KMS::Key": {
+			fakeResourcesPage("key-4", "key-5"),
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Cloud Control Service
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// To use this struct, the caller must supply the pages of resources (by type) of
+// a region. If the requested type is missing, it simulates an error.
+type fakeCloudControlService struct {
+	cloudcontrolapiiface.CloudControlApiAPI
+	Resources map[string][]*cloudcontrolapi.ListResourcesOutput
+}
+
+// Simulate the ListResourcesPages function
+func (fake *fakeCloudControlService) ListResourcesPages(input *cloudcontrolapi.ListResourcesInput,
+	fn func(*cloudcontrolapi.ListResourcesOutput, bool) bool) error {
+	// Find the pages of the requested type
+	pages, ok := fake.Resources[aws.StringValue(input.TypeName)]
+	if !ok {
+		return fmt.Errorf("ListResources does not support type: %s", aws.StringValue(input.TypeName))
+	}
+
+	// We do not expect the caller to supply a resource model
+	if input.ResourceModel != nil {
+		return errors.New("The unit test does not support a ListResourcesInput with a ResourceModel")
+	}
+
+	// Loop through the pages, invoking the supplied function
+	for index, page := range pages {
+		if !fn(page, index == len(pages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeCloudControlServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Get the resources of the supplied region (or the region associated with our factory)
+func (fsf fakeCloudControlServiceFactory) regionInfo(regionName string) map[string][]*cloudcontrolapi.ListResourcesOutput {
+	if regionName == "" {
+		return cloudControlPerRegion[fsf.RegionName]
+	}
+
+	return cloudControlPerRegion[regionName]
+}
+
+// Return our current region
+func (fsf fakeCloudControlServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeCloudControlServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return a specialized CloudControlService that returns pre-canned responses
+func (fsf fakeCloudControlServiceFactory) GetCloudControlService(regionName string) *CloudControlService {
+	return &CloudControlService{
+		Client: &fakeCloudControlService{
+			Resources: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for CloudControlResources
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestCloudControlResources(t *testing.T) {
+	// Describe all of our test cases: 2 failures and 7 successes
+	cases := []struct {
+		RegionName    string
+		AllRegions    bool
+		TypeName      string
+		ExpectedCount int
+		ExpectError   bool
+	}{
+		{
+			RegionName:    "us-east-1",
+			TypeName:      "AWS::KMS::Key",
+			ExpectedCount: 3,
+		}, {
+			RegionName:    "us-east-2",
+			TypeName:      "AWS::KMS::Key",
+			ExpectedCount: 0,
+		}, {
+			RegionName:    "af-south-1",
+			TypeName:      "AWS::KMS::Key",
+			ExpectedCount: 2,
+		}, {
+			AllRegions:    true,
+			TypeName:      "AWS::KMS::Key",
+			ExpectedCount: 5,
+		}, {
+			RegionName:    "us-east-2",
+			TypeName:      "AWS::SQS::Queue",
+			ExpectedCount: 1,
+		}, {
+			RegionName:    "af-south-1",
+			TypeName:      "AWS::IAM::Role",
+			ExpectedCount: 4,
+		}, {
+			AllRegions:    true,
+			TypeName:      "AWS::IAM::Role",
+			ExpectedCount: 4,
+		}, {
+			AllRegions:  true,
+			TypeName:    "AWS::SQS::Queue",
+			ExpectError: true,
+		}, {
+			RegionName:  "undefined-region",
+			TypeName:    "AWS::KMS::Key",
+			ExpectError: true,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeCloudControlServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our Cloud Control Resources function
+		actualCount := CloudControlResources(sf, mon, c.AllRegions, c.TypeName)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCount != c.ExpectedCount {
+			t.Errorf("Error: CloudControlResources returned %d for %s; expected %d", actualCount, c.TypeName, c.ExpectedCount)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for IsValidResourceTypeName and IsGlobalResourceType
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestResourceTypeNames(t *testing.T) {
+	cases := []struct {
+		TypeName     string
+		ExpectValid  bool
+		ExpectGlobal bool
+	}{
+		{
+			TypeName:    "AWS::SQS::Queue",
+			ExpectValid: true,
+		}, {
+			TypeName:    "AWS::EC2::VPCEndpoint",
+			ExpectValid: true,
+		}, {
+			TypeName:     "AWS::IAM::Role",
+			ExpectValid:  true,
+			ExpectGlobal: true,
+		}, {
+			TypeName:     "AWS::Route53::HostedZone",
+			ExpectValid:  true,
+			ExpectGlobal: true,
+		}, {
+			TypeName: "AWS::SQS",
+		}, {
+			TypeName: "AWS::SQS::Queue::Policy",
+		}, {
+			TypeName: "SQS Queue",
+		}, {
+			TypeName: "",
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		if actual := IsValidResourceTypeName(c.TypeName); actual != c.ExpectValid {
+			t.Errorf("Error: IsValidResourceTypeName(%q) returned %v; expected %v", c.TypeName, actual, c.ExpectValid)
+		}
+		if actual := IsGlobalResourceType(c.TypeName); actual != c.ExpectGlobal {
+			t.Errorf("Error: IsGlobalResourceType(%q) returned %v; expected %v", c.TypeName, actual, c.ExpectGlobal)
+		}
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for LoadResourceTypes
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestLoadResourceTypes(t *testing.T) {
+	cases := []struct {
+		Contents      string
+		ExpectedTypes string
+		ExpectError   bool
+	}{
+		{
+			Contents:      `{"resourceTypes": ["AWS::KMS::Key", "AWS::SQS::Queue"]}`,
+			ExpectedTypes: "AWS::KMS::Key,AWS::SQS::Queue",
+		}, {
+			Contents: `{"resourceTypes": []}`,
+		}, {
+			Contents:    `{"resourceTypes": [`,
+			ExpectError: true,
+		}, {
+			Contents:    `{"resourceTypes": ["AWS::KMS::Key", "SQS Queue"]}`,
+			ExpectError: true,
+		},
+	}
+
+	// Loop through each test case
+	for index, c := range cases {
+		// Write the contents to a temporary file
+		file, err := ioutil.TempFile("", "resource-types-*.json")
+		if err != nil {
+			t.Fatalf("Unexpected error creating temporary file: %v", err)
+		}
+		file.WriteString(c.Contents)
+		file.Close()
+
+		// Load the resource types
+		typeNames, err := LoadResourceTypes(file.Name())
+		os.Remove(file.Name())
+
+		// Did we expect an error?
+		if c.ExpectError {
+			if err == nil {
+				t.Errorf("Case %d: Expected an error to occur, but it did not... :^(", index)
+			}
+		} else if err != nil {
+			t.Errorf("Case %d: Unexpected error occurred: %v", index, err)
+		} else if actualTypes := strings.Join(typeNames, ","); actualTypes != c.ExpectedTypes {
+			t.Errorf("Case %d: LoadResourceTypes returned %s; expected %s", index, actualTypes, c.ExpectedTypes)
+		}
+	}
+
+	// A missing file is an error
+	if _, err := LoadResourceTypes("no-such-types-file.json"); err == nil {
+		t.Error("Expected an error for a missing file, but it did not occur")
+	}
+}
@@ -0,0 +1,54 @@
+/******************************************************************************
+Cloud Resource Counter
+File: cloudfront.go
+
+Summary: Provides a count of all CloudFront distributions.
+******************************************************************************/
+
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+
+	color "github.com/logrusorgru/aurora"
+)
+
+// CloudFrontDistributions retrieves the count of all CloudFront distributions.
+// CloudFront is a global service, so its distributions do not belong to any
+// region: the count is for ALL REGIONS, even when a single region is specified.
+//
+// This method gives status back to the user via the supplied ActivityMonitor
+// instance.
+func CloudFrontDistributions(sf ServiceFactory, am ActivityMonitor, allRegions bool) int {
+	// Create a new instance of the CloudFront (abstract) service
+	svc := sf.GetCloudFrontService()
+
+	// Indicate activity
+	am.StartAction("Retrieving CloudFront distribution counts")
+
+	// Invoke our service
+	count := 0
+	err := svc.ListDistributions(&cloudfront.ListDistributionsInput{}, func(page *cloudfront.ListDistributionsOutput, lastPage bool) bool {
+		if page.DistributionList != nil {
+			count += len(page.DistributionList.Items)
+		}
+
+		return true
+	})
+
+	// Check for error
+	if am.CheckError(err) {
+		return 0
+	}
+
+	// Should we "qualify" our count?
+	var qualify string
+	if !allRegions && count > 0 {
+		qualify = "*"
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d%s)", color.Bold(count), qualify)
+
+	return count
+}
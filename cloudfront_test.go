@@ -0,0 +1,159 @@
+/******************************************************************************
+Cloud Resource Counter
+File: cloudfront_test.go
+
+Summary: The Unit Test for cloudfront.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go/service/cloudfront/cloudfrontiface"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake CloudFront Distributions
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This simulates two pages of responses from AWS: 3 distributions, then 2
+var fakeCloudFrontDistributionPages = []*cloudfront.ListDistributionsOutput{
+	&cloudfront.ListDistributionsOutput{
+		DistributionList: &cloudfront.DistributionList{
+			Items: []*cloudfront.DistributionSummary{
+				{
+					Id: aws.String("E1AAAAAAAAAAAA"),
+				},
+				{
+					Id: aws.String("E2BBBBBBBBBBBB"),
+				},
+				{
+					Id: aws.String("E3CCCCCCCCCCCC"),
+				},
+			},
+		},
+	},
+	&cloudfront.ListDistributionsOutput{
+		DistributionList: &cloudfront.DistributionList{
+			Items: []*cloudfront.DistributionSummary{
+				{
+					Id: aws.String("E4DDDDDDDDDDDD"),
+				},
+				{
+					Id: aws.String("E5EEEEEEEEEEEE"),
+				},
+			},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake CloudFront Service
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// To use this struct, the caller must supply a ListDistributionsOutput slice. If
+// it is missing, it will trigger the mock function to simulate an error from
+// the corresponding function.
+type fakeCloudFrontService struct {
+	cloudfrontiface.CloudFrontAPI
+	LDResponse []*cloudfront.ListDistributionsOutput
+}
+
+// Simulate the ListDistributionsPages function
+func (fcf *fakeCloudFrontService) ListDistributionsPages(input *cloudfront.ListDistributionsInput, fn func(*cloudfront.ListDistributionsOutput, bool) bool) error {
+	// If there was no supplied response, then simulate a possible error
+	if fcf.LDResponse == nil {
+		return errors.New("ListDistributionsPages returns an unexpected error: 2345")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fcf.LDResponse {
+		if !fn(output, index == len(fcf.LDResponse)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeCloudFrontServiceFactory struct {
+	baseFakeServiceFactory
+	LDResponse []*cloudfront.ListDistributionsOutput
+}
+
+// Simply return our fake CloudFront Service
+func (fsf fakeCloudFrontServiceFactory) GetCloudFrontService() *CloudFrontService {
+	return &CloudFrontService{
+		Client: &fakeCloudFrontService{
+			LDResponse: fsf.LDResponse,
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for CloudFrontDistributions
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestCloudFrontDistributions(t *testing.T) {
+	// Describe all of our test cases: 1 failure and 2 successes
+	cases := []struct {
+		AllRegions    bool
+		ExpectedCount int
+		ExpectError   bool
+	}{
+		{
+			ExpectedCount: 5,
+		}, {
+			AllRegions:    true,
+			ExpectedCount: 5,
+		}, {
+			ExpectError: true,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Construct a ListDistributionsOutput slice based on whether
+		// we expect an error or not
+		ldResponse := fakeCloudFrontDistributionPages
+		if c.ExpectError {
+			ldResponse = nil
+		}
+
+		// Create our fake service factory
+		sf := fakeCloudFrontServiceFactory{
+			LDResponse: ldResponse,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our CloudFront Distributions function
+		actualCount := CloudFrontDistributions(sf, mon, c.AllRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCount != c.ExpectedCount {
+			t.Errorf("Error: CloudFrontDistributions returned %d; expected %d", actualCount, c.ExpectedCount)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
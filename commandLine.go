@@ -11,6 +11,7 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	color "github.com/logrusorgru/aurora"
@@ -32,11 +33,42 @@ type CommandLineSettings struct {
 	outputFileName string
 	outputFile     *os.File
 	appendToOutput bool
+	outputHeaders  []string
 	noOutputFile   bool
 
 	// Trace file
 	traceFileName string
 	traceFile     *os.File
+
+	// Counting options
+	containerModes  bool
+	imageDedupeName string
+	imageDedupe     ImageDedupeMode
+	countReplicas   bool
+	lambdaVersions  bool
+	s3Metrics       bool
+	detail          bool
+	resourceTypes   stringList
+
+	// The file that lists further resource types (if any)
+	resourceTypesFileName string
+
+	// Custom counters (and the file that defines them)
+	countersFileName string
+	customCounters   []*CustomCounter
+
+	// External counter plugins (and the directory that holds them)
+	pluginsDirName string
+	plugins        []string
+
+	// Counting backend (and the AWS Config aggregator that it queries)
+	backendName      string
+	backend          CountingBackend
+	configAggregator string
+
+	// Performance options
+	concurrency   int
+	cacheFileName string
 }
 
 // Process inspects the command line for valid arguments.
@@ -48,6 +80,20 @@ type CommandLineSettings struct {
 //   --profile PN:     Use the credentials associated with shared profile PN
 //   --region RN:      View resource counts for the AWS region RN
 //   --trace-file TF:  Create a trace file that contains all calls to AWS.
+//   --container-modes: Also count images from ACTIVE task definitions and running workloads
+//   --image-dedupe M: Deduplicate container images by M (reference, raw, repository, pinned-digest or digest)
+//   --count-table-replicas: Count each replica of a DynamoDB global table separately
+//   --include-lambda-versions: Also count the published versions of Lambda functions
+//   --detail:         Also report EBS volume sizes, unattached volumes and snapshots
+//   --resource-type T: Also count the resources of CloudFormation type T (repeatable)
+//   --resource-types-file RF: Also count the resources of the CloudFormation types listed in file RF
+//   --counters-file CF: Also count the custom counters defined in file CF
+//   --plugins-dir PD: Also run the counter plugins (executables) in directory PD
+//   --s3-metrics:     Also report the total objects and bytes stored in S3 (from CloudWatch)
+//   --backend B:      Count using backend B (api, config or index)
+//   --config-aggregator CA: Query the AWS Config aggregator named CA (with --backend=config)
+//   --concurrency N:  Make at most N concurrent lookups (such as DescribeTaskDefinition or GetBucketLocation)
+//   --cache-file CF:  Cache task definition lookups in file CF across runs
 //   --version:        Display version information
 //
 func (cls *CommandLineSettings) Process(args []string, am ActivityMonitor) func() {
@@ -69,6 +115,20 @@ func (cls *CommandLineSettings) Process(args []string, am ActivityMonitor) func(
 	flagSet.StringVar(&cls.profileName, "profile", cls.defaultProfileName, "The name of the AWS Profile to use.")
 	flagSet.StringVar(&cls.regionName, "region", "", "The name of the AWS Region to use. If omitted, then all regions will be examined. This is the default behavior.")
 	flagSet.StringVar(&cls.traceFileName, "trace-file", "", "AWS Trace Log. Specify a `file` to record API calls being made. Each subsequent run OVERWRITES the prior run.")
+	flagSet.BoolVar(&cls.containerModes, "container-modes", false, "Also count unique container images from only ACTIVE task definitions and from only running workloads. Each is stored in its own column. (default false)")
+	flagSet.StringVar(&cls.imageDedupeName, "image-dedupe", DedupeByReference.String(), "How unique container images are determined: by canonical `mode` \"reference\", by unaltered \"raw\" image string, by \"repository\" (ignoring tags) by the digest that the image reference is pinned to (\"pinned-digest\"; tags are not resolved to digests) or by digest, resolving the tags of images in ECR (\"digest\").")
+	flagSet.BoolVar(&cls.countReplicas, "count-table-replicas", false, "Count each regional replica of a DynamoDB global table as its own table. (default false--each global table is counted once)")
+	flagSet.BoolVar(&cls.lambdaVersions, "include-lambda-versions", false, "Also count the published versions of each Lambda function (such as those used by Lambda@Edge or provisioned concurrency). (default false)")
+	flagSet.BoolVar(&cls.detail, "detail", false, "Also report the size of attached EBS volumes (in total and by volume type), the number and size of unattached EBS volumes and the number of EBS snapshots. (default false)")
+	flagSet.Var(&cls.resourceTypes, "resource-type", "Also count the resources of a CloudFormation resource `type` (such as AWS::KMS::Key) using the Cloud Control API. May be repeated.")
+	flagSet.StringVar(&cls.resourceTypesFileName, "resource-types-file", "", "Also count the resources of the CloudFormation resource types listed in a JSON `file` (like --resource-type).")
+	flagSet.StringVar(&cls.countersFileName, "counters-file", "", "Custom Counters. Specify a JSON `file` that defines additional counters (the AWS operation to invoke and the items to count in its output).")
+	flagSet.StringVar(&cls.pluginsDirName, "plugins-dir", "", "Counter Plugins. Specify a `directory` of executables that are run to supply additional counts (see README for the protocol).")
+	flagSet.BoolVar(&cls.s3Metrics, "s3-metrics", false, "Also report the total number of objects and bytes stored in S3 buckets, using the daily S3 storage metrics in CloudWatch. (default false)")
+	flagSet.StringVar(&cls.backendName, "backend", APIBackend.String(), "The `backend` that produces the counts: \"api\" (call the APIs of each service in each region), \"config\" (query an AWS Config aggregator) or \"index\" (approximate counts from AWS Resource Explorer or the Resource Groups Tagging API).")
+	flagSet.StringVar(&cls.configAggregator, "config-aggregator", "", "The `name` of the AWS Config aggregator to query (with --backend=config). It is queried in the region of the session.")
+	flagSet.IntVar(&cls.concurrency, "concurrency", 8, "The maximum `number` of concurrent lookups (such as describing task definitions or locating S3 buckets).")
+	flagSet.StringVar(&cls.cacheFileName, "cache-file", "", "Task Definition Cache. Specify a `file` to cache task definition lookups in. Later runs only describe task definitions not already in the file.")
 	flagSet.BoolVar(&showVersion, "version", false, "Shows the version number.")
 	flagSet.Parse(args)
 
@@ -84,6 +144,85 @@ func (cls *CommandLineSettings) Process(args []string, am ActivityMonitor) func(
 		cls.allRegions = true
 	}
 
+	// Check for a valid image dedupe mode
+	var validDedupeMode bool
+	if cls.imageDedupe, validDedupeMode = ParseImageDedupeMode(cls.imageDedupeName); !validDedupeMode {
+		am.ActionError("Error: '%s' is not a valid image dedupe mode.", cls.imageDedupeName)
+		return emptyFn
+	}
+
+	// Check for a valid backend
+	var validBackend bool
+	if cls.backend, validBackend = ParseCountingBackend(cls.backendName); !validBackend {
+		am.ActionError("Error: '%s' is not a valid backend.", cls.backendName)
+		return emptyFn
+	}
+
+	// Does the backend have what it needs?
+	if cls.backend == ConfigBackend && cls.configAggregator == "" {
+		am.ActionError("Error: --backend=config requires --config-aggregator.")
+		return emptyFn
+	}
+	if cls.backend != ConfigBackend && cls.configAggregator != "" {
+		am.ActionError("Error: --config-aggregator can only be used with --backend=config.")
+		return emptyFn
+	}
+	if cls.backend != APIBackend && (cls.countersFileName != "" || cls.pluginsDirName != "") {
+		am.ActionError("Error: --counters-file and --plugins-dir can only be used with --backend=api.")
+		return emptyFn
+	}
+	if cls.backend == IndexBackend && (len(cls.resourceTypes) > 0 || cls.resourceTypesFileName != "") {
+		am.ActionError("Error: --resource-type and --resource-types-file cannot be used with --backend=index.")
+		return emptyFn
+	}
+
+	// Check for valid resource types
+	for _, typeName := range cls.resourceTypes {
+		if !IsValidResourceTypeName(typeName) {
+			am.ActionError("Error: '%s' is not a valid resource type (such as AWS::SQS::Queue).", typeName)
+			return emptyFn
+		}
+	}
+
+	// Add the resource types listed in the resource types file (if any), skipping
+	// those that were already named on the command line
+	if cls.resourceTypesFileName != "" {
+		typeNames, err := LoadResourceTypes(cls.resourceTypesFileName)
+		if err != nil {
+			am.ActionError("Error: %v", err)
+			return emptyFn
+		}
+		for _, typeName := range typeNames {
+			if !cls.resourceTypes.Contains(typeName) {
+				cls.resourceTypes = append(cls.resourceTypes, typeName)
+			}
+		}
+	}
+
+	// Load the custom counters (if any)
+	if cls.countersFileName != "" {
+		var err error
+		if cls.customCounters, err = LoadCustomCounters(cls.countersFileName); err != nil {
+			am.ActionError("Error: %v", err)
+			return emptyFn
+		}
+	}
+
+	// Find the counter plugins (if any)
+	if cls.pluginsDirName != "" {
+		var err error
+		if cls.plugins, err = FindPlugins(cls.pluginsDirName); err != nil {
+			am.ActionError("Error: %v", err)
+			return emptyFn
+		}
+	}
+
+	// Check for a valid concurrency
+	if cls.concurrency < 1 {
+		am.ActionError("Error: --concurrency must be at least 1.")
+		return emptyFn
+	}
+
 	// If both --output-file and --no-output specified, then complain
 	if cls.outputFileName != "" && cls.noOutputFile {
 		// Show error...
@@ -110,6 +249,15 @@ func (cls *CommandLineSettings) Process(args []string, am ActivityMonitor) func(
 		// Determine whether to append the output file or not
 		cls.appendToOutput = FileExists(cls.outputFileName)
 
+		// If appending, get the columns of the file (to check against our own)
+		if cls.appendToOutput {
+			var err error
+			cls.outputHeaders, err = ReadResultsHeaders(cls.outputFileName)
+			if am.CheckError(err) {
+				return emptyFn
+			}
+		}
+
 		// Try to open the file for writing
 		cls.outputFile = OpenFileForWriting(cls.outputFileName, "CSV", am, cls.appendToOutput)
 	}
@@ -155,8 +303,73 @@ func (cls *CommandLineSettings) Display(am ActivityMonitor) {
 	am.Message(" o %s:  %s\n", color.Italic("AWS Region"), displayRegionName)
 	am.Message(" o %s: %s\n", color.Italic("Output file"), displayOutputFile)
 
+	// Are we using another backend?
+	if cls.backend == ConfigBackend {
+		am.Message(" o %s:     AWS Config aggregator %s\n", color.Italic("Backend"), cls.configAggregator)
+	} else if cls.backend == IndexBackend {
+		am.Message(" o %s:     Resource index (APPROXIMATE counts)\n", color.Italic("Backend"))
+	}
+
 	// Are we tracing?
 	if cls.traceFileName != "" {
 		am.Message(" o %s:  %s\n", color.Italic("Trace file"), cls.traceFileName)
 	}
+
+	// Are we caching?
+	if cls.cacheFileName != "" {
+		am.Message(" o %s:  %s\n", color.Italic("Cache file"), cls.cacheFileName)
+	}
+
+	// Are we using custom counters?
+	if cls.countersFileName != "" {
+		am.Message(" o %s: %s (%d counters)\n", color.Italic("Counters file"), cls.countersFileName, len(cls.customCounters))
+	}
+
+	// Are we running plugins?
+	if cls.pluginsDirName != "" {
+		am.Message(" o %s: %s (%d plugins)\n", color.Italic("Plugins directory"), cls.pluginsDirName, len(cls.plugins))
+	}
+
+	// Are we counting other resource types?
+	if len(cls.resourceTypes) > 0 {
+		am.Message(" o %s: %s\n", color.Italic("Resource types"), cls.resourceTypes.String())
+	}
+}
+
+// PluginSettings returns the settings that are supplied to counter plugins.
+func (cls *CommandLineSettings) PluginSettings() PluginSettings {
+	return PluginSettings{
+		ContainerModes:        cls.containerModes,
+		ImageDedupe:           cls.imageDedupe.String(),
+		CountTableReplicas:    cls.countReplicas,
+		IncludeLambdaVersions: cls.lambdaVersions,
+		S3Metrics:             cls.s3Metrics,
+		Detail:                cls.detail,
+		Concurrency:           cls.concurrency,
+	}
+}
+
+// stringList is a command line flag that can be repeated, collecting each value.
+type stringList []string
+
+// String returns the collected values, separated by commas.
+func (sl *stringList) String() string {
+	return strings.Join(*sl, ", ")
+}
+
+// Set appends the supplied value.
+func (sl *stringList) Set(value string) error {
+	*sl = append(*sl, value)
+	return nil
+}
+
+// Contains checks whether the supplied value has been collected.
+func (sl stringList) Contains(value string) bool {
+	for _, collected := range sl {
+		if collected == value {
+			return true
+		}
+	}
+
+	return false
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
@@ -12,6 +13,14 @@ func TestCommandLineProcess(t *testing.T) {
 	// Our temp file
 	const tempFile = "temp-output-file"
 
+	// Our resource types file
+	const typesFile = "temp-resource-types-file"
+	err := ioutil.WriteFile(typesFile, []byte(`{"resourceTypes": ["AWS::SQS::Queue", "AWS::SNS::Topic"]}`), 0644)
+	if err != nil {
+		t.Fatalf("Unexpected error while trying to create resource types file: %v", err)
+	}
+	defer os.Remove(typesFile)
+
 	// Construct our test cases...
 	cases := []struct {
 		Args             []string
@@ -20,6 +29,11 @@ func TestCommandLineProcess(t *testing.T) {
 		ExpectAppend     bool
 		ExpectAllRegions bool
 		ExpectSSO        bool
+		ExpectContainers bool
+		ExpectReplicas   bool
+		ExpectVersions   bool
+		ExpectTypes      string
+		ExpectBackend    CountingBackend
 	}{
 		{
 			Args:             []string{"--output-file", tempFile},
@@ -49,6 +63,96 @@ func TestCommandLineProcess(t *testing.T) {
 			ExpectAllRegions: true,
 			ExpectSSO:        true,
 		},
+		{
+			Args:             []string{"--container-modes", "--no-output"},
+			ExpectAllRegions: true,
+			ExpectContainers: true,
+		},
+		{
+			Args:             []string{"--count-table-replicas", "--no-output"},
+			ExpectAllRegions: true,
+			ExpectReplicas:   true,
+		},
+		{
+			Args:             []string{"--include-lambda-versions", "--no-output"},
+			ExpectAllRegions: true,
+			ExpectVersions:   true,
+		},
+		{
+			Args:             []string{"--resource-type", "AWS::KMS::Key", "--resource-type", "AWS::SQS::Queue", "--no-output"},
+			ExpectAllRegions: true,
+			ExpectTypes:      "AWS::KMS::Key,AWS::SQS::Queue",
+		},
+		{
+			Args:             []string{"--resource-type", "KMS Key", "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
+		{
+			Args:             []string{"--resource-type", "AWS::SQS::Queue", "--resource-types-file", typesFile, "--no-output"},
+			ExpectAllRegions: true,
+			ExpectTypes:      "AWS::SQS::Queue,AWS::SNS::Topic",
+		},
+		{
+			Args:             []string{"--resource-types-file", "no-such-types-file.json", "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
+		{
+			Args:             []string{"--counters-file", "no-such-counters-file.json", "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
+		{
+			Args:             []string{"--plugins-dir", "no-such-plugins-dir", "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
+		{
+			Args:             []string{"--backend", "config", "--config-aggregator", "org-aggregator", "--no-output"},
+			ExpectAllRegions: true,
+			ExpectBackend:    ConfigBackend,
+		},
+		{
+			Args:             []string{"--backend", "index", "--no-output"},
+			ExpectAllRegions: true,
+			ExpectBackend:    IndexBackend,
+		},
+		{
+			Args:             []string{"--backend", "index", "--resource-type", "AWS::KMS::Key", "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
+		{
+			Args:             []string{"--backend", "index", "--resource-types-file", typesFile, "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
+		{
+			Args:             []string{"--backend", "bingo", "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
+		{
+			Args:             []string{"--backend", "config", "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
+		{
+			Args:             []string{"--config-aggregator", "org-aggregator", "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
+		{
+			Args:             []string{"--backend", "config", "--config-aggregator", "org-aggregator", "--plugins-dir", ".", "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
+		{
+			Args:             []string{"--image-dedupe", "bingo-pajamas", "--no-output"},
+			ExpectError:      true,
+			ExpectAllRegions: true,
+		},
 	}
 
 	// Does the file exist?
@@ -90,6 +194,16 @@ func TestCommandLineProcess(t *testing.T) {
 			t.Errorf("Unexpected AllRegions: expected %v, actual: %v", c.ExpectAllRegions, settings.allRegions)
 		} else if c.ExpectSSO != settings.useSSO {
 			t.Errorf("Unexpected SSO: expected %v, actual: %v", c.ExpectSSO, settings.useSSO)
+		} else if c.ExpectContainers != settings.containerModes {
+			t.Errorf("Unexpected ContainerModes: expected %v, actual: %v", c.ExpectContainers, settings.containerModes)
+		} else if c.ExpectReplicas != settings.countReplicas {
+			t.Errorf("Unexpected CountReplicas: expected %v, actual: %v", c.ExpectReplicas, settings.countReplicas)
+		} else if c.ExpectVersions != settings.lambdaVersions {
+			t.Errorf("Unexpected LambdaVersions: expected %v, actual: %v", c.ExpectVersions, settings.lambdaVersions)
+		} else if actualTypes := strings.Join(settings.resourceTypes, ","); c.ExpectTypes != actualTypes {
+			t.Errorf("Unexpected ResourceTypes: expected %v, actual: %v", c.ExpectTypes, actualTypes)
+		} else if c.ExpectBackend != settings.backend {
+			t.Errorf("Unexpected Backend: expected %v, actual: %v", c.ExpectBackend, settings.backend)
 		}
 	}
 
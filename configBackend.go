@@ -0,0 +1,386 @@
+/******************************************************************************
+Cloud Resource Counter
+File: configBackend.go
+
+Summary: Counts resources by running advanced queries against an AWS Config
+         aggregator, producing the counts of every account in the aggregator.
+******************************************************************************/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/configservice"
+	"github.com/jmespath/go-jmespath"
+	color "github.com/logrusorgru/aurora"
+)
+
+// ConfigQuery is an advanced query of the resources recorded by AWS Config. The
+// resources that match the WHERE clause are counted (or, if Sum is supplied, that
+// property is summed) for each account and for each combination of the values of
+// the GROUP BY properties.
+type ConfigQuery struct {
+	Where   string
+	GroupBy []string
+	Sum     string
+
+	// Is this a query of a global resource type? If so, it is not limited to the
+	// selected region.
+	Global bool
+}
+
+// ConfigColumn maps a Results column to the query that counts it. Only the groups
+// of results that are accepted by Groups (if supplied) are added up. A group is
+// named by the values of the query's GROUP BY properties, separated by "/".
+type ConfigColumn struct {
+	Name   string
+	Query  *ConfigQuery
+	Groups func(group string) bool
+}
+
+// ConfigAccountCounts holds the counts of a single account: one for each column.
+type ConfigAccountCounts struct {
+	AccountID string
+	Counts    []int
+}
+
+// The queries that are used by several columns
+var (
+	configEC2InstancesQuery = &ConfigQuery{
+		Where:   "resourceType = 'AWS::EC2::Instance' AND configuration.state.name = 'running'",
+		GroupBy: []string{"configuration.instanceLifecycle"},
+	}
+	configEBSVolumesQuery = &ConfigQuery{
+		Where:   "resourceType = 'AWS::EC2::Volume'",
+		GroupBy: []string{"configuration.state"},
+	}
+	configEBSStorageQuery = &ConfigQuery{
+		Where:   "resourceType = 'AWS::EC2::Volume'",
+		GroupBy: []string{"configuration.state", "configuration.volumeType"},
+		Sum:     "configuration.size",
+	}
+	configAPIGatewayV2Query = &ConfigQuery{
+		Where:   "resourceType = 'AWS::ApiGatewayV2::Api'",
+		GroupBy: []string{"configuration.protocolType"},
+	}
+	configDBClustersQuery = &ConfigQuery{
+		Where:   "resourceType = 'AWS::RDS::DBCluster'",
+		GroupBy: []string{"configuration.engine"},
+	}
+	configLoadBalancersQuery = &ConfigQuery{
+		Where:   "resourceType = 'AWS::ElasticLoadBalancingV2::LoadBalancer'",
+		GroupBy: []string{"configuration.type"},
+	}
+)
+
+// ConfigColumnSuffix is added to the name of each column of the AWS Config backend,
+// so that its counts are not confused with those of the API backend (which counts
+// what the APIs of each service report, not what AWS Config has recorded).
+const ConfigColumnSuffix = " (AWS Config)"
+
+// ConfigBackendColumns returns the columns that can be counted by the AWS Config
+// backend, in the same order as the API backend. Columns without an equivalent
+// query (such as the unique container images) are omitted. Each column is named
+// after the column of the API backend, followed by the ConfigColumnSuffix.
+func ConfigBackendColumns(detail bool, countReplicas bool, resourceTypes []string) []ConfigColumn {
+	columns := []ConfigColumn{
+		{Name: "# of EC2 Instances", Query: configEC2InstancesQuery, Groups: configGroups("")},
+		{Name: "# of Spot Instances", Query: configEC2InstancesQuery, Groups: configGroups("spot")},
+		{Name: "# of Scheduled Instances", Query: configEC2InstancesQuery, Groups: configGroups("scheduled")},
+		{Name: "# of Capacity Block Instances", Query: configEC2InstancesQuery, Groups: configGroups(instanceLifecycleCapacityBlock)},
+		{Name: "# of Other Lifecycle Instances", Query: configEC2InstancesQuery, Groups: configOtherGroups("", "spot", "scheduled", instanceLifecycleCapacityBlock)},
+		{Name: "# of EBS Volumes", Query: configEBSVolumesQuery, Groups: configGroups("in-use")},
+	}
+
+	// Are we reporting EBS storage?
+	if detail {
+		columns = append(columns, ConfigColumn{
+			Name:   "EBS Attached Storage (GiB)",
+			Query:  configEBSStorageQuery,
+			Groups: func(group string) bool { return strings.HasPrefix(group, "in-use/") },
+		})
+		for _, volumeType := range EBSVolumeTypes {
+			columns = append(columns, ConfigColumn{
+				Name:   fmt.Sprintf("EBS Attached Storage (%s) (GiB)", volumeType),
+				Query:  configEBSStorageQuery,
+				Groups: configGroups("in-use/" + volumeType),
+			})
+		}
+		columns = append(columns, ConfigColumn{
+			Name:   "# of Unattached EBS Volumes",
+			Query:  configEBSVolumesQuery,
+			Groups: configOtherGroups("in-use"),
+		}, ConfigColumn{
+			Name:   "EBS Unattached Storage (GiB)",
+			Query:  configEBSStorageQuery,
+			Groups: func(group string) bool { return !strings.HasPrefix(group, "in-use/") },
+		})
+	}
+
+	columns = append(columns,
+		configResourceTypeColumn("# of ECR Repositories", "AWS::ECR::Repository"),
+		configResourceTypeColumn("# of EKS Clusters", "AWS::EKS::Cluster"),
+		configResourceTypeColumn("# of EKS Fargate Profiles", "AWS::EKS::FargateProfile"),
+		configResourceTypeColumn("# of Lambda Functions", "AWS::Lambda::Function"),
+		configResourceTypeColumn("# of Step Functions State Machines", "AWS::StepFunctions::StateMachine"),
+		configResourceTypeColumn("# of API Gateway REST APIs", "AWS::ApiGateway::RestApi"),
+		ConfigColumn{Name: "# of API Gateway HTTP APIs", Query: configAPIGatewayV2Query, Groups: configGroups("HTTP")},
+		ConfigColumn{Name: "# of API Gateway WebSocket APIs", Query: configAPIGatewayV2Query, Groups: configGroups("WEBSOCKET")},
+		configResourceTypeColumn("# of SQS Queues", "AWS::SQS::Queue"),
+		configResourceTypeColumn("# of SNS Topics", "AWS::SNS::Topic"),
+		configResourceTypeColumn("# of EventBridge Rules", "AWS::Events::Rule"),
+		ConfigColumn{
+			Name: "# of EventBridge Event Buses",
+			Query: &ConfigQuery{
+				Where:   "resourceType = 'AWS::Events::EventBus'",
+				GroupBy: []string{"resourceName"},
+			},
+			Groups: configOtherGroups(defaultEventBusName),
+		},
+	)
+
+	// AWS Config records each replica of a DynamoDB global table as its own table
+	if countReplicas {
+		columns = append(columns, configResourceTypeColumn("# of DynamoDB Tables", "AWS::DynamoDB::Table"))
+	}
+
+	columns = append(columns,
+		ConfigColumn{
+			Name: "# of RDS Instances",
+			Query: &ConfigQuery{
+				Where:   "resourceType = 'AWS::RDS::DBInstance'",
+				GroupBy: []string{"configuration.dBInstanceStatus"},
+			},
+			Groups: configGroups("available"),
+		},
+		configResourceTypeColumn("# of ElastiCache Clusters", "AWS::ElastiCache::CacheCluster"),
+		configResourceTypeColumn("# of ElastiCache Replication Groups", "AWS::ElastiCache::ReplicationGroup"),
+		configResourceTypeColumn("# of Redshift Clusters", "AWS::Redshift::Cluster"),
+		configResourceTypeColumn("# of Redshift Serverless Workgroups", "AWS::RedshiftServerless::Workgroup"),
+		configResourceTypeColumn("# of OpenSearch Domains", "AWS::OpenSearch::Domain"),
+		ConfigColumn{Name: "# of DocumentDB Clusters", Query: configDBClustersQuery, Groups: configGroups("docdb")},
+		ConfigColumn{Name: "# of Neptune Clusters", Query: configDBClustersQuery, Groups: configGroups("neptune")},
+		configResourceTypeColumn("# of Classic Load Balancers", "AWS::ElasticLoadBalancing::LoadBalancer"),
+		ConfigColumn{Name: "# of Application Load Balancers", Query: configLoadBalancersQuery, Groups: configGroups("application")},
+		ConfigColumn{Name: "# of Network Load Balancers", Query: configLoadBalancersQuery, Groups: configGroups("network")},
+		ConfigColumn{Name: "# of Gateway Load Balancers", Query: configLoadBalancersQuery, Groups: configGroups("gateway")},
+		ConfigColumn{
+			Name: "# of NAT Gateways",
+			Query: &ConfigQuery{
+				Where:   "resourceType = 'AWS::EC2::NatGateway'",
+				GroupBy: []string{"configuration.state"},
+			},
+			Groups: configOtherGroups("failed", "deleting", "deleted"),
+		},
+		configResourceTypeColumn("# of Elastic IPs", "AWS::EC2::EIP"),
+		configResourceTypeColumn("# of VPCs", "AWS::EC2::VPC"),
+		configResourceTypeColumn("# of Subnets", "AWS::EC2::Subnet"),
+		ConfigColumn{
+			Name: "# of Transit Gateway Attachments",
+			Query: &ConfigQuery{
+				Where:   "resourceType = 'AWS::EC2::TransitGatewayAttachment'",
+				GroupBy: []string{"configuration.state"},
+			},
+			Groups: func(group string) bool { return !isDefunctVPCState(group) },
+		},
+		ConfigColumn{
+			Name: "# of VPC Endpoints",
+			Query: &ConfigQuery{
+				Where:   "resourceType = 'AWS::EC2::VPCEndpoint'",
+				GroupBy: []string{"configuration.state"},
+			},
+			Groups: func(group string) bool { return !isDefunctVPCState(group) },
+		},
+		configResourceTypeColumn("# of S3 Buckets", "AWS::S3::Bucket"),
+		configResourceTypeColumn("# of CloudFront Distributions", "AWS::CloudFront::Distribution"),
+	)
+
+	// Add the other resource types
+	for _, typeName := range resourceTypes {
+		columns = append(columns, configResourceTypeColumn(fmt.Sprintf("# of %s", typeName), typeName))
+	}
+
+	// Distinguish our columns from those of the API backend
+	for index := range columns {
+		columns[index].Name += ConfigColumnSuffix
+	}
+
+	return columns
+}
+
+// Construct a column that counts all of the resources of a resource type.
+func configResourceTypeColumn(name string, typeName string) ConfigColumn {
+	return ConfigColumn{
+		Name: name,
+		Query: &ConfigQuery{
+			Where:  fmt.Sprintf("resourceType = '%s'", typeName),
+			Global: IsGlobalResourceType(typeName),
+		},
+	}
+}
+
+// Accept only the supplied groups.
+func configGroups(groups ...string) func(string) bool {
+	return func(group string) bool {
+		for _, accepted := range groups {
+			if group == accepted {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Accept every group except the supplied groups.
+func configOtherGroups(groups ...string) func(string) bool {
+	accepted := configGroups(groups...)
+	return func(group string) bool {
+		return !accepted(group)
+	}
+}
+
+// ConfigAggregatorCounts retrieves the counts of the supplied columns for each account
+// in the named AWS Config aggregator, either for all regions (allRegions is true)
+// or the region associated with the session. (The aggregator itself is queried in
+// the region associated with the session.) The accounts are returned in order of
+// their IDs.
+//
+// This method gives status back to the user via the supplied ActivityMonitor
+// instance.
+func ConfigAggregatorCounts(sf ServiceFactory, am ActivityMonitor, allRegions bool, aggregatorName string, columns []ConfigColumn) []ConfigAccountCounts {
+	// Indicate activity
+	am.StartAction("Querying AWS Config aggregator %s", aggregatorName)
+
+	// Which region are we limited to?
+	var regionName string
+	if !allRegions {
+		regionName = sf.GetCurrentRegion()
+	}
+
+	// Run each query (once), collecting the accounts that we have seen
+	cs := sf.GetConfigService("")
+	queryResults := make(map[*ConfigQuery]map[string]map[string]int)
+	accounts := make(map[string]bool)
+	for _, column := range columns {
+		if _, ok := queryResults[column.Query]; ok {
+			continue
+		}
+
+		// Indicate activity
+		am.Message(".")
+
+		// Run the query
+		accountGroups, err := configQueryForAggregator(cs, aggregatorName, column.Query, regionName)
+		if am.CheckError(err) {
+			return nil
+		}
+		queryResults[column.Query] = accountGroups
+		for accountID := range accountGroups {
+			accounts[accountID] = true
+		}
+	}
+
+	// Sort the accounts
+	accountIDs := make([]string, 0, len(accounts))
+	for accountID := range accounts {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+
+	// Add up the counts of each account
+	accountCounts := make([]ConfigAccountCounts, 0, len(accountIDs))
+	for _, accountID := range accountIDs {
+		counts := ConfigAccountCounts{
+			AccountID: accountID,
+			Counts:    make([]int, len(columns)),
+		}
+		for index, column := range columns {
+			for group, value := range queryResults[column.Query][accountID] {
+				if column.Groups == nil || column.Groups(group) {
+					counts.Counts[index] += value
+				}
+			}
+		}
+		accountCounts = append(accountCounts, counts)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d accounts, %d columns)", color.Bold(len(accountCounts)), color.Bold(len(columns)))
+
+	return accountCounts
+}
+
+// Run a query of the aggregator (limited to the supplied region, if any) and return
+// its results by account and by group.
+func configQueryForAggregator(cs *ConfigService, aggregatorName string, query *ConfigQuery, regionName string) (map[string]map[string]int, error) {
+	// What are we adding up?
+	aggregate := "COUNT(*)"
+	if query.Sum != "" {
+		aggregate = fmt.Sprintf("SUM(%s)", query.Sum)
+	}
+
+	// Construct our input to run the query
+	input := &configservice.SelectAggregateResourceConfigInput{
+		ConfigurationAggregatorName: aws.String(aggregatorName),
+		Expression:                  aws.String(configQueryExpression(query, aggregate, regionName)),
+	}
+
+	// Invoke our service
+	accountGroups := make(map[string]map[string]int)
+	var parseErr error
+	err := cs.SelectAggregateResourceConfig(input, func(page *configservice.SelectAggregateResourceConfigOutput, lastPage bool) bool {
+		for _, result := range page.Results {
+			// Parse the result
+			var row map[string]interface{}
+			if parseErr = json.Unmarshal([]byte(aws.StringValue(result)), &row); parseErr != nil {
+				return false
+			}
+			accountID, _ := row["accountId"].(string)
+			value, _ := row[aggregate].(float64)
+
+			// Name the group by the values of its properties
+			values := make([]string, len(query.GroupBy))
+			for index, property := range query.GroupBy {
+				propertyValue, _ := jmespath.Search(property, row)
+				if propertyValue != nil {
+					values[index] = fmt.Sprintf("%v", propertyValue)
+				}
+			}
+			group := strings.Join(values, "/")
+
+			// Add it to the account's groups
+			if accountGroups[accountID] == nil {
+				accountGroups[accountID] = make(map[string]int)
+			}
+			accountGroups[accountID][group] += int(value)
+		}
+
+		return true
+	})
+
+	// Check for error
+	if err == nil && parseErr != nil {
+		err = fmt.Errorf("AWS Config returned an invalid result: %v", parseErr)
+	}
+
+	return accountGroups, err
+}
+
+// Construct the expression of the supplied query.
+func configQueryExpression(query *ConfigQuery, aggregate string, regionName string) string {
+	// Select the account, the group's properties and the aggregate
+	groupBy := append([]string{"accountId"}, query.GroupBy...)
+	expression := fmt.Sprintf("SELECT %s, %s WHERE %s", strings.Join(groupBy, ", "), aggregate, query.Where)
+
+	// Are we limited to a single region?
+	if regionName != "" && !query.Global {
+		expression += fmt.Sprintf(" AND awsRegion = '%s'", regionName)
+	}
+
+	return expression + " GROUP BY " + strings.Join(groupBy, ", ")
+}
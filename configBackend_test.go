@@ -0,0 +1,406 @@
+/******************************************************************************
+Cloud Resource Counter
+File: configBackend_test.go
+
+Summary: The Unit Test for configBackend.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/configservice"
+	"github.com/aws/aws-sdk-go/service/configservice/configserviceiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake AWS Config Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This is our map of query expressions and the pages of results of each. A query
+// that is missing has no results.
+var configQueryResults = map[string][][]string{
+	// Running EC2 instances (across all regions), over two pages
+	"SELECT accountId, configuration.instanceLifecycle, COUNT(*) WHERE resourceType = 'AWS::EC2::Instance' AND configuration.state.name = 'running' GROUP BY accountId, configuration.instanceLifecycle": {
+		{
+			`{"accountId":"111111111111","COUNT(*)":5}`,
+			`{"accountId":"111111111111","configuration":{"instanceLifecycle":"spot"},"COUNT(*)":2}`,
+		},
+		{
+			`{"accountId":"222222222222","configuration":{"instanceLifecycle":"capacity-block"},"COUNT(*)":1}`,
+			`{"accountId":"222222222222","configuration":{"instanceLifecycle":"bingo"},"COUNT(*)":3}`,
+		},
+	},
+
+	// Running EC2 instances in US-EAST-1
+	"SELECT accountId, configuration.instanceLifecycle, COUNT(*) WHERE resourceType = 'AWS::EC2::Instance' AND configuration.state.name = 'running' AND awsRegion = 'us-east-1' GROUP BY accountId, configuration.instanceLifecycle": {
+		{
+			`{"accountId":"111111111111","COUNT(*)":4}`,
+		},
+	},
+
+	// EBS volumes (across all regions)
+	"SELECT accountId, configuration.state, COUNT(*) WHERE resourceType = 'AWS::EC2::Volume' GROUP BY accountId, configuration.state": {
+		{
+			`{"accountId":"111111111111","configuration":{"state":"in-use"},"COUNT(*)":6}`,
+			`{"accountId":"111111111111","configuration":{"state":"available"},"COUNT(*)":2}`,
+		},
+	},
+
+	// EBS storage (across all regions)
+	"SELECT accountId, configuration.state, configuration.volumeType, SUM(configuration.size) WHERE resourceType = 'AWS::EC2::Volume' GROUP BY accountId, configuration.state, configuration.volumeType": {
+		{
+			`{"accountId":"111111111111","configuration":{"state":"in-use","volumeType":"gp3"},"SUM(configuration.size)":400}`,
+			`{"accountId":"111111111111","configuration":{"state":"in-use","volumeType":"io2"},"SUM(configuration.size)":100}`,
+			`{"accountId":"111111111111","configuration":{"state":"available","volumeType":"gp2"},"SUM(configuration.size)":16}`,
+		},
+	},
+
+	// Load balancers (across all regions)
+	"SELECT accountId, configuration.type, COUNT(*) WHERE resourceType = 'AWS::ElasticLoadBalancingV2::LoadBalancer' GROUP BY accountId, configuration.type": {
+		{
+			`{"accountId":"222222222222","configuration":{"type":"application"},"COUNT(*)":3}`,
+			`{"accountId":"222222222222","configuration":{"type":"network"},"COUNT(*)":1}`,
+		},
+	},
+
+	// Event buses (across all regions)
+	"SELECT accountId, resourceName, COUNT(*) WHERE resourceType = 'AWS::Events::EventBus' GROUP BY accountId, resourceName": {
+		{
+			`{"accountId":"111111111111","resourceName":"default","COUNT(*)":1}`,
+			`{"accountId":"111111111111","resourceName":"orders","COUNT(*)":1}`,
+		},
+	},
+
+	// S3 buckets (across all regions and in US-EAST-1)
+	"SELECT accountId, COUNT(*) WHERE resourceType = 'AWS::S3::Bucket' GROUP BY accountId": {
+		{
+			`{"accountId":"111111111111","COUNT(*)":12}`,
+			`{"accountId":"333333333333","COUNT(*)":1}`,
+		},
+	},
+	"SELECT accountId, COUNT(*) WHERE resourceType = 'AWS::S3::Bucket' AND awsRegion = 'us-east-1' GROUP BY accountId": {
+		{
+			`{"accountId":"111111111111","COUNT(*)":7}`,
+		},
+	},
+
+	// CloudFront distributions (a global resource type)
+	"SELECT accountId, COUNT(*) WHERE resourceType = 'AWS::CloudFront::Distribution' GROUP BY accountId": {
+		{
+			`{"accountId":"222222222222","COUNT(*)":2}`,
+		},
+	},
+
+	// An invalid result (for a resource type)
+	"SELECT accountId, COUNT(*) WHERE resourceType = 'AWS::Bingo::Pajamas' GROUP BY accountId": {
+		{
+			`{"accountId":`,
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Config Service
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This struct answers the queries of a single aggregator (named "org-aggregator")
+type fakeConfigService struct {
+	configserviceiface.ConfigServiceAPI
+}
+
+// Simulate the SelectAggregateResourceConfigPages function
+func (fake *fakeConfigService) SelectAggregateResourceConfigPages(input *configservice.SelectAggregateResourceConfigInput,
+	fn func(*configservice.SelectAggregateResourceConfigOutput, bool) bool) error {
+	// Is this our aggregator?
+	if aws.StringValue(input.ConfigurationAggregatorName) != "org-aggregator" {
+		return errors.New("NoSuchConfigurationAggregatorException: The configuration aggregator does not exist")
+	}
+
+	// Return each page of results
+	pages := configQueryResults[aws.StringValue(input.Expression)]
+	for index, page := range pages {
+		if !fn(&configservice.SelectAggregateResourceConfigOutput{
+			Results: aws.StringSlice(page),
+		}, index == len(pages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeConfigBackendServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Return our current region
+func (fsf fakeConfigBackendServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeConfigBackendServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return a ConfigService that answers the queries of our aggregator
+func (fsf fakeConfigBackendServiceFactory) GetConfigService(string) *ConfigService {
+	return &ConfigService{
+		Client: &fakeConfigService{},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for ConfigAggregatorCounts
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestConfigAggregatorCounts(t *testing.T) {
+	// Describe all of our test cases: 2 failures and 3 successes
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		AggregatorName string
+		Detail         bool
+		ResourceTypes  []string
+		ExpectedCounts map[string]map[string]int
+		ExpectError    bool
+	}{
+		{
+			AllRegions:     true,
+			AggregatorName: "org-aggregator",
+			ExpectedCounts: map[string]map[string]int{
+				"111111111111": {
+					"# of EC2 Instances (AWS Config)":           5,
+					"# of Spot Instances (AWS Config)":          2,
+					"# of EBS Volumes (AWS Config)":             6,
+					"# of EventBridge Event Buses (AWS Config)": 1,
+					"# of S3 Buckets (AWS Config)":              12,
+				},
+				"222222222222": {
+					"# of Capacity Block Instances (AWS Config)":   1,
+					"# of Other Lifecycle Instances (AWS Config)":  3,
+					"# of Application Load Balancers (AWS Config)": 3,
+					"# of Network Load Balancers (AWS Config)":     1,
+					"# of CloudFront Distributions (AWS Config)":   2,
+				},
+				"333333333333": {
+					"# of S3 Buckets (AWS Config)": 1,
+				},
+			},
+		}, {
+			AllRegions:     true,
+			AggregatorName: "org-aggregator",
+			Detail:         true,
+			ExpectedCounts: map[string]map[string]int{
+				"111111111111": {
+					"# of EC2 Instances (AWS Config)":               5,
+					"# of Spot Instances (AWS Config)":              2,
+					"# of EBS Volumes (AWS Config)":                 6,
+					"EBS Attached Storage (GiB) (AWS Config)":       500,
+					"EBS Attached Storage (gp3) (GiB) (AWS Config)": 400,
+					"EBS Attached Storage (io2) (GiB) (AWS Config)": 100,
+					"# of Unattached EBS Volumes (AWS Config)":      2,
+					"EBS Unattached Storage (GiB) (AWS Config)":     16,
+					"# of EventBridge Event Buses (AWS Config)":     1,
+					"# of S3 Buckets (AWS Config)":                  12,
+				},
+				"222222222222": {
+					"# of Capacity Block Instances (AWS Config)":   1,
+					"# of Other Lifecycle Instances (AWS Config)":  3,
+					"# of Application Load Balancers (AWS Config)": 3,
+					"# of Network Load Balancers (AWS Config)":     1,
+					"# of CloudFront Distributions (AWS Config)":   2,
+				},
+				"333333333333": {
+					"# of S3 Buckets (AWS Config)": 1,
+				},
+			},
+		}, {
+			RegionName:     "us-east-1",
+			AggregatorName: "org-aggregator",
+			ExpectedCounts: map[string]map[string]int{
+				"111111111111": {
+					"# of EC2 Instances (AWS Config)": 4,
+					"# of S3 Buckets (AWS Config)":    7,
+				},
+				"222222222222": {
+					"# of CloudFront Distributions (AWS Config)": 2,
+				},
+			},
+		}, {
+			AllRegions:     true,
+			AggregatorName: "missing-aggregator",
+			ExpectError:    true,
+		}, {
+			AllRegions:     true,
+			AggregatorName: "org-aggregator",
+			ResourceTypes:  []string{"AWS::Bingo::Pajamas"},
+			ExpectError:    true,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeConfigBackendServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our ConfigAggregatorCounts function
+		columns := ConfigBackendColumns(c.Detail, false, c.ResourceTypes)
+		actualCounts := ConfigAggregatorCounts(sf, mon, c.AllRegions, c.AggregatorName, columns)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Errorf("Expected an error to occur for %s, but it did not... :^(", c.AggregatorName)
+			}
+			continue
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+			continue
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+			continue
+		}
+
+		// Convert the counts of each account into a map of its (non-zero) columns
+		actualMap := make(map[string]map[string]int)
+		for _, accountCounts := range actualCounts {
+			actualMap[accountCounts.AccountID] = make(map[string]int)
+			for index, count := range accountCounts.Counts {
+				if count != 0 {
+					actualMap[accountCounts.AccountID][columns[index].Name] = count
+				}
+			}
+		}
+
+		// Do they match?
+		if !reflect.DeepEqual(actualMap, c.ExpectedCounts) {
+			t.Errorf("Error: ConfigAggregatorCounts returned %v; expected %v", actualMap, c.ExpectedCounts)
+		}
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for ConfigBackendColumns
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestConfigBackendColumns(t *testing.T) {
+	// Get the columns without any options
+	baseColumns := ConfigBackendColumns(false, false, nil)
+
+	// Describe all of our test cases
+	cases := []struct {
+		Detail          bool
+		CountReplicas   bool
+		ResourceTypes   []string
+		ExpectedColumns []string
+	}{
+		{
+			Detail: true,
+			ExpectedColumns: []string{
+				"EBS Attached Storage (GiB) (AWS Config)",
+				"EBS Attached Storage (gp2) (GiB) (AWS Config)",
+				"EBS Attached Storage (gp3) (GiB) (AWS Config)",
+				"EBS Attached Storage (io1) (GiB) (AWS Config)",
+				"EBS Attached Storage (io2) (GiB) (AWS Config)",
+				"EBS Attached Storage (st1) (GiB) (AWS Config)",
+				"EBS Attached Storage (sc1) (GiB) (AWS Config)",
+				"EBS Attached Storage (standard) (GiB) (AWS Config)",
+				"# of Unattached EBS Volumes (AWS Config)",
+				"EBS Unattached Storage (GiB) (AWS Config)",
+			},
+		}, {
+			CountReplicas:   true,
+			ExpectedColumns: []string{"# of DynamoDB Tables (AWS Config)"},
+		}, {
+			ResourceTypes:   []string{"AWS::KMS::Key", "AWS::IAM::Role"},
+			ExpectedColumns: []string{"# of AWS::KMS::Key (AWS Config)", "# of AWS::IAM::Role (AWS Config)"},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Find the columns that are not in our base columns
+		baseNames := make(map[string]bool)
+		for _, column := range baseColumns {
+			baseNames[column.Name] = true
+		}
+		var actualColumns []string
+		for _, column := range ConfigBackendColumns(c.Detail, c.CountReplicas, c.ResourceTypes) {
+			if !baseNames[column.Name] {
+				actualColumns = append(actualColumns, column.Name)
+			}
+		}
+
+		// Do they match?
+		if !reflect.DeepEqual(actualColumns, c.ExpectedColumns) {
+			t.Errorf("Error: ConfigBackendColumns added %v; expected %v", actualColumns, c.ExpectedColumns)
+		}
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for configQueryExpression
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestConfigQueryExpression(t *testing.T) {
+	cases := []struct {
+		Query      *ConfigQuery
+		Aggregate  string
+		RegionName string
+		Expected   string
+	}{
+		{
+			Query:     &ConfigQuery{Where: "resourceType = 'AWS::SQS::Queue'"},
+			Aggregate: "COUNT(*)",
+			Expected:  "SELECT accountId, COUNT(*) WHERE resourceType = 'AWS::SQS::Queue' GROUP BY accountId",
+		}, {
+			Query:      &ConfigQuery{Where: "resourceType = 'AWS::SQS::Queue'"},
+			Aggregate:  "COUNT(*)",
+			RegionName: "us-west-2",
+			Expected:   "SELECT accountId, COUNT(*) WHERE resourceType = 'AWS::SQS::Queue' AND awsRegion = 'us-west-2' GROUP BY accountId",
+		}, {
+			Query:      &ConfigQuery{Where: "resourceType = 'AWS::IAM::Role'", Global: true},
+			Aggregate:  "COUNT(*)",
+			RegionName: "us-west-2",
+			Expected:   "SELECT accountId, COUNT(*) WHERE resourceType = 'AWS::IAM::Role' GROUP BY accountId",
+		}, {
+			Query:     configEBSStorageQuery,
+			Aggregate: "SUM(configuration.size)",
+			Expected:  "SELECT accountId, configuration.state, configuration.volumeType, SUM(configuration.size) WHERE resourceType = 'AWS::EC2::Volume' GROUP BY accountId, configuration.state, configuration.volumeType",
+		},
+	}
+
+	for index, c := range cases {
+		if actual := configQueryExpression(c.Query, c.Aggregate, c.RegionName); actual != c.Expected {
+			t.Errorf("Case %d: configQueryExpression returned %q; expected %q", index, actual, c.Expected)
+		}
+	}
+}
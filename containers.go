@@ -8,94 +8,392 @@ Summary: Counts the number of unique container images.
 package main
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	color "github.com/logrusorgru/aurora"
 )
 
+// ContainerImageSource determines which task definitions contribute images to
+// the count of unique container images.
+type ContainerImageSource int
+
+const (
+	// AllTaskDefinitions inspects every task definition revision, including
+	// INACTIVE (deregistered) revisions.
+	AllTaskDefinitions ContainerImageSource = iota
+
+	// ActiveTaskDefinitions inspects only ACTIVE task definition revisions.
+	ActiveTaskDefinitions
+
+	// RunningWorkloads inspects only those task definitions referenced by a
+	// service or by a running task in one of the account's clusters.
+	RunningWorkloads
+)
+
+// String returns a description of the source (used when displaying activity).
+func (cis ContainerImageSource) String() string {
+	switch cis {
+	case ActiveTaskDefinitions:
+		return "active task definitions"
+	case RunningWorkloads:
+		return "running workloads"
+	default:
+		return "all task definitions"
+	}
+}
+
+// ContainerImageOptions controls which container images are counted and when two
+// images are considered to be the same.
+type ContainerImageOptions struct {
+	Source ContainerImageSource
+	Dedupe ImageDedupeMode
+
+	// The maximum number of task definitions to describe at once
+	Concurrency int
+
+	// An (optional) cache of previously described task definitions
+	Cache *TaskDefinitionCache
+}
+
+// ContainerImageCounts holds the number of unique container images, along with how
+// many of them are stored in the account's own ECR registries and how many in public
+// or other (third-party) registries.
+type ContainerImageCounts struct {
+	Unique int
+	ECR    int
+	Other  int
+}
+
 // UniqueContainerImages reviews all of the ECS containers either in the current region
-// or (if allRegions is true) in all regions. It inspects the task definitions for all
-// containers, looking at the image definition. It then counts the number of unique
-// images across all containers in the given region (or all regions).
-func UniqueContainerImages(sf ServiceFactory, am ActivityMonitor, allRegions bool) int {
+// or (if allRegions is true) in all regions. It inspects the task definitions selected
+// by the options' Source, looking at the image definition of each container. It then
+// counts the number of unique images (as determined by the options' Dedupe mode) across
+// all containers in the given region (or all regions), classifying each by its registry.
+// In the DedupeByDigest mode, the tags of images stored in ECR are first resolved to
+// their digests.
+func UniqueContainerImages(sf ServiceFactory, am ActivityMonitor, allRegions bool, opts ContainerImageOptions) ContainerImageCounts {
 	// Indicate activity
-	am.StartAction("Retrieving Unique container counts")
+	if opts.Source == AllTaskDefinitions {
+		am.StartAction("Retrieving Unique container counts")
+	} else {
+		am.StartAction("Retrieving Unique container counts (%s)", opts.Source)
+	}
+
+	// Map each unique image (by its dedupe key) to the first image that we find with it
+	var containerImageMap map[string]string = make(map[string]string)
+	resolvedDigests := make(map[string]string)
+	addImages := func(containerImagesSlice []string) {
+		for _, cntrImg := range containerImagesSlice {
+			if opts.Dedupe == DedupeByDigest {
+				cntrImg = resolveECRImageDigest(sf, am, cntrImg, resolvedDigests)
+			}
+			key := opts.Dedupe.Key(cntrImg)
+			if _, ok := containerImageMap[key]; !ok {
+				containerImageMap[key] = cntrImg
+			}
+		}
+	}
 
 	// Should we get the counts for all regions?
-	var containerImageMap map[string]bool = make(map[string]bool)
 	if allRegions {
 		// Get the list of all enabled regions for this account
 		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
 
 		// Loop through all of the regions
 		for _, regionName := range regionsSlice {
-			// Get the container image names for a specific region
-			containerImagesSlice := containerImagesForSingleRegion(sf.GetContainerService(regionName), am)
+			// Get (and add) the container image names for a specific region
+			addImages(containerImagesForSingleRegion(sf.GetContainerService(regionName), am, opts))
+		}
+	} else {
+		// Get (and add) the container image names for a specific region
+		addImages(containerImagesForSingleRegion(sf.GetContainerService(""), am, opts))
+	}
 
-			// Add the container names to our map
-			for _, cntrImg := range containerImagesSlice {
-				containerImageMap[cntrImg] = true
+	// Get our container counts, classifying each image by its registry
+	containerCounts := ContainerImageCounts{
+		Unique: len(containerImageMap),
+	}
+	for _, cntrImg := range containerImageMap {
+		if ParseImageReference(cntrImg).IsECR() {
+			containerCounts.ECR++
+		} else {
+			containerCounts.Other++
+		}
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d)", color.Bold(containerCounts.Unique))
+
+	return containerCounts
+}
+
+// Resolve the tag of an image stored in ECR to the digest that it points to, returning
+// the image reference pinned to that digest. Images that are already pinned to a digest
+// or are not stored in ECR are returned unchanged, as are images that cannot be found
+// (or that belong to another account's registry that we may not read). The supplied map
+// remembers the digest (or "") of each tagged image that we have already looked up.
+func resolveECRImageDigest(sf ServiceFactory, am ActivityMonitor, image string, digests map[string]string) string {
+	// Is this a tagged image in ECR?
+	ref := ParseImageReference(image)
+	registryID, regionName, ok := ref.ECRRegistry()
+	if !ok || ref.Digest != "" {
+		return image
+	}
+
+	// Have we already looked it up?
+	canonical := ref.String()
+	digest, ok := digests[canonical]
+	if !ok {
+		err := sf.GetECRService(regionName).DescribeImages(&ecr.DescribeImagesInput{
+			RegistryId:     aws.String(registryID),
+			RepositoryName: aws.String(ref.Repository),
+			ImageIds: []*ecr.ImageIdentifier{
+				&ecr.ImageIdentifier{ImageTag: aws.String(ref.Tag)},
+			},
+		}, func(page *ecr.DescribeImagesOutput, lastPage bool) bool {
+			for _, imageDetail := range page.ImageDetails {
+				digest = aws.StringValue(imageDetail.ImageDigest)
 			}
+
+			return true
+		})
+
+		// Can we not find (or read) the image? Then we count it by its tag.
+		if err != nil && !isECRImageUnavailableError(err) && am.CheckError(err) {
+			return image
 		}
+		digests[canonical] = digest
+	}
+
+	// Did we find its digest?
+	if digest == "" {
+		return image
+	}
+	ref.Digest = digest
+
+	return ref.String()
+}
+
+// Check whether the supplied error shows that an ECR image does not exist (or that
+// we are not allowed to describe it).
+func isECRImageUnavailableError(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case ecr.ErrCodeImageNotFoundException, ecr.ErrCodeRepositoryNotFoundException, "AccessDeniedException":
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get a list of all container images used by the selected task definitions for this region
+func containerImagesForSingleRegion(cs *ContainerService, am ActivityMonitor, opts ContainerImageOptions) []string {
+	// Indicate activity
+	am.Message(".")
+
+	// Get the task definitions to inspect
+	var taskDefnArns []*string
+	var ok bool
+	if opts.Source == RunningWorkloads {
+		taskDefnArns, ok = runningTaskDefinitionArnsForSingleRegion(cs, am)
 	} else {
-		// Get the container image names for a specific region
-		containerImagesSlice := containerImagesForSingleRegion(sf.GetContainerService(""), am)
+		taskDefnArns, ok = taskDefinitionArnsForSingleRegion(cs, am, opts.Source == ActiveTaskDefinitions)
+	}
 
-		// Add the container names to our map
-		for _, cntrImg := range containerImagesSlice {
-			containerImageMap[cntrImg] = true
+	// If error, then get out now!
+	if !ok {
+		return nil
+	}
+
+	// Describe the task definitions concurrently. Each goroutine only writes to
+	// its own slot in these slices.
+	imagesPerTaskDefn := make([][]string, len(taskDefnArns))
+	errs := make([]error, len(taskDefnArns))
+	ForEachConcurrently(len(taskDefnArns), opts.Concurrency, func(index int) {
+		imagesPerTaskDefn[index], errs[index] = taskDefinitionImages(cs, taskDefnArns[index], opts.Cache)
+	})
+
+	// Loop through the task definitions...
+	var containerImageNames []string
+	for index := range taskDefnArns {
+		// Error?
+		if am.CheckError(errs[index]) {
+			// Stop iterating
+			break
 		}
+
+		containerImageNames = append(containerImageNames, imagesPerTaskDefn[index]...)
 	}
 
-	// Get our container count
-	containerCount := len(containerImageMap)
+	return containerImageNames
+}
 
-	// Indicate end of activity
-	am.EndAction("OK (%d)", color.Bold(containerCount))
+// Get the container images used by a single task definition, consulting (and
+// updating) the supplied cache. This function is called from multiple goroutines,
+// so it must not use the ActivityMonitor.
+func taskDefinitionImages(cs *ContainerService, taskDefnArn *string, cache *TaskDefinitionCache) ([]string, error) {
+	// Have we already described this task definition?
+	if images, ok := cache.Lookup(*taskDefnArn); ok {
+		return images, nil
+	}
+
+	// Construct an input struct for the specific task definition
+	input := &ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: taskDefnArn,
+	}
 
-	return containerCount
+	// Inspect the task definition details
+	taskDefn, err := cs.InspectTaskDefinition(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do we have a TaskDefinition?
+	var images []string
+	if taskDefn.TaskDefinition != nil {
+		// Loop through the container definitions...
+		for _, cntrDefn := range taskDefn.TaskDefinition.ContainerDefinitions {
+			images = append(images, *cntrDefn.Image)
+		}
+	}
+
+	// Remember it for next time
+	cache.Store(*taskDefnArn, images)
+
+	return images, nil
 }
 
-// Get a list of all container images used by all tasks for this region
-func containerImagesForSingleRegion(cs *ContainerService, am ActivityMonitor) []string {
+// Get the ARNs of all (or only ACTIVE) task definitions for this region. The
+// boolean result is false if an error occurred.
+func taskDefinitionArnsForSingleRegion(cs *ContainerService, am ActivityMonitor, activeOnly bool) ([]*string, bool) {
 	// Construct our input to find all Task Definitions
 	input := &ecs.ListTaskDefinitionsInput{}
-
-	// Indicate activity
-	am.Message(".")
+	if activeOnly {
+		input.Status = aws.String(ecs.TaskDefinitionStatusActive)
+	}
 
 	// Invoke our service
-	var containerImageNames []string
+	var taskDefnArns []*string
 	err := cs.ListTaskDefinitions(input, func(page *ecs.ListTaskDefinitionsOutput, lastPage bool) bool {
-		// Loop through the results...
-		for _, taskDefnArn := range page.TaskDefinitionArns {
-			// Construct an input struct for the specific task definition
-			input := &ecs.DescribeTaskDefinitionInput{
-				TaskDefinition: taskDefnArn,
-			}
+		taskDefnArns = append(taskDefnArns, page.TaskDefinitionArns...)
+
+		return true
+	})
+
+	// Check for error
+	return taskDefnArns, !am.CheckError(err)
+}
+
+// Get the ARNs of all task definitions referenced by a service or a running task
+// in any cluster for this region. The boolean result is false if an error occurred.
+func runningTaskDefinitionArnsForSingleRegion(cs *ContainerService, am ActivityMonitor) ([]*string, bool) {
+	// Get the list of clusters
+	clusterArns, ok := ecsClusterArnsForSingleRegion(cs, am)
+	if !ok {
+		return nil, false
+	}
 
-			// Inspect the task definition details
-			taskDefn, err := cs.InspectTaskDefinition(input)
+	// Collect the (unique) task definition ARNs in the order that we find them
+	var taskDefnArns []*string
+	seen := make(map[string]bool)
+	addTaskDefnArn := func(taskDefnArn *string) {
+		if taskDefnArn != nil && !seen[*taskDefnArn] {
+			seen[*taskDefnArn] = true
+			taskDefnArns = append(taskDefnArns, taskDefnArn)
+		}
+	}
+
+	// Loop through the clusters...
+	for _, clusterArn := range clusterArns {
+		// Each page holds at most 10 service ARNs, which is what DescribeServices accepts
+		var innerErr error
+		err := cs.ListServices(&ecs.ListServicesInput{
+			Cluster:    clusterArn,
+			MaxResults: aws.Int64(10),
+		}, func(page *ecs.ListServicesOutput, lastPage bool) bool {
+			// Anything to describe?
+			if len(page.ServiceArns) == 0 {
+				return true
+			}
 
-			// Error?
-			if am.CheckError(err) {
+			// Inspect the services
+			var output *ecs.DescribeServicesOutput
+			output, innerErr = cs.InspectServices(&ecs.DescribeServicesInput{
+				Cluster:  clusterArn,
+				Services: page.ServiceArns,
+			})
+			if innerErr != nil {
 				// Stop iterating
 				return false
 			}
 
-			// Do we have a TaskDefinition?
-			if taskDefn.TaskDefinition != nil {
-				// Loop through the container definitions...
-				for _, cntrDefn := range taskDefn.TaskDefinition.ContainerDefinitions {
-					containerImageNames = append(containerImageNames, *cntrDefn.Image)
+			// Record the task definition of each active service
+			for _, svc := range output.Services {
+				if aws.StringValue(svc.Status) == "ACTIVE" {
+					addTaskDefnArn(svc.TaskDefinition)
 				}
 			}
+
+			return true
+		})
+
+		// Check for either error
+		if am.CheckError(err) || am.CheckError(innerErr) {
+			return nil, false
 		}
 
+		// Each page holds at most 100 task ARNs, which is what DescribeTasks accepts.
+		// (By default, ListTasks only returns tasks whose desired status is RUNNING.)
+		err = cs.ListTasks(&ecs.ListTasksInput{
+			Cluster: clusterArn,
+		}, func(page *ecs.ListTasksOutput, lastPage bool) bool {
+			// Anything to describe?
+			if len(page.TaskArns) == 0 {
+				return true
+			}
+
+			// Inspect the tasks
+			var output *ecs.DescribeTasksOutput
+			output, innerErr = cs.InspectTasks(&ecs.DescribeTasksInput{
+				Cluster: clusterArn,
+				Tasks:   page.TaskArns,
+			})
+			if innerErr != nil {
+				// Stop iterating
+				return false
+			}
+
+			// Record the task definition of each task
+			for _, task := range output.Tasks {
+				addTaskDefnArn(task.TaskDefinitionArn)
+			}
+
+			return true
+		})
+
+		// Check for either error
+		if am.CheckError(err) || am.CheckError(innerErr) {
+			return nil, false
+		}
+	}
+
+	return taskDefnArns, true
+}
+
+// Get the ARNs of all ECS clusters for this region. The boolean result is false
+// if an error occurred.
+func ecsClusterArnsForSingleRegion(cs *ContainerService, am ActivityMonitor) ([]*string, bool) {
+	// Invoke our service
+	var clusterArns []*string
+	err := cs.ListClusters(&ecs.ListClustersInput{}, func(page *ecs.ListClustersOutput, lastPage bool) bool {
+		clusterArns = append(clusterArns, page.ClusterArns...)
+
 		return true
 	})
 
 	// Check for error
-	am.CheckError(err)
-
-	return containerImageNames
+	return clusterArns, !am.CheckError(err)
 }
@@ -9,10 +9,15 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
 	"github.com/expel-io/cloud-resource-counter/mock"
@@ -27,6 +32,7 @@ import (
 type TaskInfo struct {
 	ListOutputs       []*ecs.ListTaskDefinitionsOutput
 	DescribeOutputMap map[string]*ecs.DescribeTaskDefinitionOutput
+	InactiveArns      map[string]bool
 }
 
 // This is our map of regions and the task definitions in each
@@ -37,7 +43,8 @@ var taskDefinitionsPerRegion = map[string]*TaskInfo{
 	// first task definition uses two containers, each with different images. The second task
 	// definition uses one container, with a single image. The third task has a single container
 	// but uses the same image as the first task. In total, there are 3 task definitions, 4
-	// containers, but with only 3 unique container images.
+	// containers, but with only 3 unique container images. The first task definition has been
+	// deregistered (INACTIVE), so only 2 unique container images are used by ACTIVE ones.
 	"us-east-1": &TaskInfo{
 		ListOutputs: []*ecs.ListTaskDefinitionsOutput{
 			&ecs.ListTaskDefinitionsOutput{
@@ -84,6 +91,9 @@ var taskDefinitionsPerRegion = map[string]*TaskInfo{
 				},
 			},
 		},
+		InactiveArns: map[string]bool{
+			"some-long-name:task-definition/family:1": true,
+		},
 	},
 	// US-EAST-2 simulates the case when a single page of ListTaskDefinitionOutput is returned
 	// to the caller. There are two task definitions. Each has a single container image, which
@@ -119,6 +129,55 @@ var taskDefinitionsPerRegion = map[string]*TaskInfo{
 			},
 		},
 	},
+	// EU-WEST-1 simulates the same images being referenced in different ways. There is a
+	// single task definition with 8 containers: 3 spellings of nginx:latest, nginx pinned
+	// by digest (twice, once with a tag) and 3 ECR images (web:1.0, the digest that it
+	// points to and web:deleted, which no longer exists). There are 8 distinct image
+	// strings, 6 distinct canonical references (docker.io/library/nginx:latest, the two
+	// pinned nginx references and the 3 ECR images), 2 distinct repositories and 5
+	// distinct pinned "digests" (the 2 digests and the 3 images referenced by tag alone).
+	// Resolving the ECR tags to digests leaves 4: web:1.0 is the same as its digest.
+	"eu-west-1": &TaskInfo{
+		ListOutputs: []*ecs.ListTaskDefinitionsOutput{
+			&ecs.ListTaskDefinitionsOutput{
+				TaskDefinitionArns: []*string{
+					aws.String("some-long-name:task-definition/web:1"),
+				},
+			},
+		},
+		DescribeOutputMap: map[string]*ecs.DescribeTaskDefinitionOutput{
+			"some-long-name:task-definition/web:1": &ecs.DescribeTaskDefinitionOutput{
+				TaskDefinition: &ecs.TaskDefinition{
+					ContainerDefinitions: []*ecs.ContainerDefinition{
+						&ecs.ContainerDefinition{
+							Image: aws.String("nginx"),
+						},
+						&ecs.ContainerDefinition{
+							Image: aws.String("docker.io/library/nginx:latest"),
+						},
+						&ecs.ContainerDefinition{
+							Image: aws.String("index.docker.io/nginx"),
+						},
+						&ecs.ContainerDefinition{
+							Image: aws.String("nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"),
+						},
+						&ecs.ContainerDefinition{
+							Image: aws.String("nginx:1.25@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"),
+						},
+						&ecs.ContainerDefinition{
+							Image: aws.String("123456789012.dkr.ecr.eu-west-1.amazonaws.com/web:1.0"),
+						},
+						&ecs.ContainerDefinition{
+							Image: aws.String("123456789012.dkr.ecr.eu-west-1.amazonaws.com/web@" + testECRImageDigest),
+						},
+						&ecs.ContainerDefinition{
+							Image: aws.String("123456789012.dkr.ecr.eu-west-1.amazonaws.com/web:deleted"),
+						},
+					},
+				},
+			},
+		},
+	},
 	// AF-SOUTH-1 indicates that no tasks were defined for this regino.
 	"af-south-1": &TaskInfo{
 		ListOutputs: []*ecs.ListTaskDefinitionsOutput{
@@ -139,6 +198,81 @@ var taskDefinitionsPerRegion = map[string]*TaskInfo{
 	},
 }
 
+// For our tests, we describe the ECS clusters in a region by mapping each cluster
+// ARN to the container instances registered with it, the services defined in it
+// and the tasks running in it.
+type ClusterInfo struct {
+	ContainerInstances map[string][]*ecs.ContainerInstance
+	Services           map[string][]*ecs.Service
+	Tasks              map[string][]*ecs.Task
+}
+
+// This is our map of regions and the workloads (services and tasks) running in each
+var ecsWorkloadsPerRegion = map[string]*ClusterInfo{
+	// US-EAST-1 has two clusters. The first runs an (ACTIVE) service using family:2 and
+	// two tasks started by that service. It also has a DRAINING service using family:1
+	// (which is not counted). The second cluster runs a standalone task using family:2.
+	// In total, only 1 unique container image is in use.
+	"us-east-1": &ClusterInfo{
+		Services: map[string][]*ecs.Service{
+			"cluster-1": []*ecs.Service{
+				&ecs.Service{
+					ServiceArn:     aws.String("service-1"),
+					Status:         aws.String("ACTIVE"),
+					TaskDefinition: aws.String("some-long-name:task-definition/family:2"),
+				},
+				&ecs.Service{
+					ServiceArn:     aws.String("service-2"),
+					Status:         aws.String("DRAINING"),
+					TaskDefinition: aws.String("some-long-name:task-definition/family:1"),
+				},
+			},
+		},
+		Tasks: map[string][]*ecs.Task{
+			"cluster-1": []*ecs.Task{
+				&ecs.Task{
+					TaskArn:           aws.String("task-1"),
+					TaskDefinitionArn: aws.String("some-long-name:task-definition/family:2"),
+				},
+				&ecs.Task{
+					TaskArn:           aws.String("task-2"),
+					TaskDefinitionArn: aws.String("some-long-name:task-definition/family:2"),
+				},
+			},
+			"cluster-2": []*ecs.Task{
+				&ecs.Task{
+					TaskArn:           aws.String("task-3"),
+					TaskDefinitionArn: aws.String("some-long-name:task-definition/family:2"),
+				},
+			},
+		},
+	},
+	// US-EAST-2 has a single cluster running a task using anotherfamily:1 (1 image)
+	"us-east-2": &ClusterInfo{
+		Tasks: map[string][]*ecs.Task{
+			"cluster-1": []*ecs.Task{
+				&ecs.Task{
+					TaskArn:           aws.String("task-1"),
+					TaskDefinitionArn: aws.String("some-long-name:task-definition/anotherfamily:1"),
+				},
+			},
+		},
+	},
+	// AF-SOUTH-1 has no clusters
+	"af-south-1": &ClusterInfo{},
+	// AF-SOUTH-2 has a task whose task definition cannot be described
+	"af-south-2": &ClusterInfo{
+		Tasks: map[string][]*ecs.Task{
+			"cluster-1": []*ecs.Task{
+				&ecs.Task{
+					TaskArn:           aws.String("task-1"),
+					TaskDefinitionArn: aws.String("this-is-a-non-existent-task-arn-which-triggers-failure"),
+				},
+			},
+		},
+	},
+}
+
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 // Fake Container Service
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -148,7 +282,8 @@ var taskDefinitionsPerRegion = map[string]*TaskInfo{
 // pre-canned outputs (for the InspectTaskDefinition method).
 type fakeContainerService struct {
 	ecsiface.ECSAPI
-	TaskInfo *TaskInfo
+	TaskInfo    *TaskInfo
+	ClusterInfo *ClusterInfo
 }
 
 // Implement the ListTaskDefinitionsPages method by returning the pre-canned array
@@ -166,11 +301,25 @@ func (fake *fakeContainerService) ListTaskDefinitionsPages(input *ecs.ListTaskDe
 		lastPage := index == len(fake.TaskInfo.ListOutputs)-1
 
 		// Apply filtering to the supplied response
-		// NOTE: I have not implemented this feature as our code does not require it.
-		// To prevent unexpected cases, if the caller supplies an input other then
-		// the "zero" input, the unit test fails.
-		if input.FamilyPrefix != nil || input.MaxResults != nil || input.NextToken != nil || input.Sort != nil || input.Status != nil {
-			return errors.New("The unit test does not support a ListTaskDefinitionsInput other than 'zero' (no parameters)")
+		// NOTE: I have only implemented filtering by Status as our code does not require
+		// anything else. To prevent unexpected cases, if the caller supplies any other
+		// input, the unit test fails.
+		if input.FamilyPrefix != nil || input.MaxResults != nil || input.NextToken != nil || input.Sort != nil {
+			return errors.New("The unit test does not support a ListTaskDefinitionsInput other than Status")
+		}
+		if input.Status != nil && *input.Status != ecs.TaskDefinitionStatusActive {
+			return errors.New("The unit test only supports a ListTaskDefinitionsInput Status of ACTIVE")
+		}
+
+		// Remove the INACTIVE task definitions (if requested)
+		if input.Status != nil {
+			filteredOutput := &ecs.ListTaskDefinitionsOutput{}
+			for _, taskDefnArn := range output.TaskDefinitionArns {
+				if !fake.TaskInfo.InactiveArns[*taskDefnArn] {
+					filteredOutput.TaskDefinitionArns = append(filteredOutput.TaskDefinitionArns, taskDefnArn)
+				}
+			}
+			output = filteredOutput
 		}
 
 		// Invoke our fn
@@ -204,6 +353,213 @@ func (fake *fakeContainerService) DescribeTaskDefinition(input *ecs.DescribeTask
 	return response, nil
 }
 
+// Implement the ListClustersPages method by returning a single page containing
+// the ARNs of all of our clusters (in sorted order).
+func (fake *fakeContainerService) ListClustersPages(input *ecs.ListClustersInput,
+	fn func(page *ecs.ListClustersOutput, lastPage bool) bool) error {
+	// If there is no ClusterInfo, simulate an error...
+	if fake.ClusterInfo == nil {
+		return errors.New("ListClustersPages encountered an unexpected error: 1357")
+	}
+
+	// Collect the (unique) cluster ARNs
+	clusterArnMap := make(map[string]bool)
+	for clusterArn := range fake.ClusterInfo.ContainerInstances {
+		clusterArnMap[clusterArn] = true
+	}
+	for clusterArn := range fake.ClusterInfo.Services {
+		clusterArnMap[clusterArn] = true
+	}
+	for clusterArn := range fake.ClusterInfo.Tasks {
+		clusterArnMap[clusterArn] = true
+	}
+	var clusterArns []string
+	for clusterArn := range clusterArnMap {
+		clusterArns = append(clusterArns, clusterArn)
+	}
+	sort.Strings(clusterArns)
+
+	// Invoke our fn
+	fn(&ecs.ListClustersOutput{
+		ClusterArns: aws.StringSlice(clusterArns),
+	}, true)
+
+	return nil
+}
+
+// Implement the ListContainerInstancesPages method by returning a single page
+// containing the ARNs of the container instances in the requested cluster.
+func (fake *fakeContainerService) ListContainerInstancesPages(input *ecs.ListContainerInstancesInput,
+	fn func(page *ecs.ListContainerInstancesOutput, lastPage bool) bool) error {
+	// Get the container instances for the cluster
+	containerInstances, ok := fake.ClusterInfo.ContainerInstances[*input.Cluster]
+	if !ok {
+		return errors.New("ListContainerInstancesPages encountered an unexpected error: 3579")
+	}
+
+	// Collect their ARNs
+	output := &ecs.ListContainerInstancesOutput{}
+	for _, ci := range containerInstances {
+		output.ContainerInstanceArns = append(output.ContainerInstanceArns, ci.ContainerInstanceArn)
+	}
+
+	// Invoke our fn
+	fn(output, true)
+
+	return nil
+}
+
+// Implement the DescribeContainerInstances method by returning the container
+// instances of the requested cluster whose ARNs were supplied.
+func (fake *fakeContainerService) DescribeContainerInstances(input *ecs.DescribeContainerInstancesInput) (*ecs.DescribeContainerInstancesOutput, error) {
+	// DescribeContainerInstances is limited to 100 container instances
+	if len(input.ContainerInstances) > 100 {
+		return nil, errors.New("DescribeContainerInstances only accepts up to 100 container instances")
+	}
+
+	// Loop through the requested ARNs
+	output := &ecs.DescribeContainerInstancesOutput{}
+	for _, arn := range input.ContainerInstances {
+		for _, ci := range fake.ClusterInfo.ContainerInstances[*input.Cluster] {
+			if *ci.ContainerInstanceArn == *arn {
+				output.ContainerInstances = append(output.ContainerInstances, ci)
+			}
+		}
+	}
+
+	return output, nil
+}
+
+// Implement the ListServicesPages method by returning the ARNs of the services
+// in the requested cluster, in pages of (at most) MaxResults services.
+func (fake *fakeContainerService) ListServicesPages(input *ecs.ListServicesInput,
+	fn func(page *ecs.ListServicesOutput, lastPage bool) bool) error {
+	// Collect the service ARNs
+	var serviceArns []*string
+	for _, svc := range fake.ClusterInfo.Services[*input.Cluster] {
+		serviceArns = append(serviceArns, svc.ServiceArn)
+	}
+
+	// Split them into pages
+	pageSize := 10
+	if input.MaxResults != nil {
+		pageSize = int(*input.MaxResults)
+	}
+	for start := 0; start == 0 || start < len(serviceArns); start += pageSize {
+		end := start + pageSize
+		if end > len(serviceArns) {
+			end = len(serviceArns)
+		}
+
+		// Invoke our fn
+		if !fn(&ecs.ListServicesOutput{ServiceArns: serviceArns[start:end]}, end == len(serviceArns)) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Implement the DescribeServices method by returning the services of the requested
+// cluster whose ARNs were supplied.
+func (fake *fakeContainerService) DescribeServices(input *ecs.DescribeServicesInput) (*ecs.DescribeServicesOutput, error) {
+	// DescribeServices is limited to 10 services
+	if len(input.Services) > 10 {
+		return nil, errors.New("DescribeServices only accepts up to 10 services")
+	}
+
+	// Loop through the requested ARNs
+	output := &ecs.DescribeServicesOutput{}
+	for _, arn := range input.Services {
+		for _, svc := range fake.ClusterInfo.Services[*input.Cluster] {
+			if *svc.ServiceArn == *arn {
+				output.Services = append(output.Services, svc)
+			}
+		}
+	}
+
+	return output, nil
+}
+
+// Implement the ListTasksPages method by returning a single page containing the
+// ARNs of the tasks running in the requested cluster (optionally filtered by
+// launch type).
+func (fake *fakeContainerService) ListTasksPages(input *ecs.ListTasksInput,
+	fn func(page *ecs.ListTasksOutput, lastPage bool) bool) error {
+	// Collect the task ARNs
+	output := &ecs.ListTasksOutput{}
+	for _, task := range fake.ClusterInfo.Tasks[*input.Cluster] {
+		if input.LaunchType == nil || aws.StringValue(task.LaunchType) == *input.LaunchType {
+			output.TaskArns = append(output.TaskArns, task.TaskArn)
+		}
+	}
+
+	// Invoke our fn
+	fn(output, true)
+
+	return nil
+}
+
+// Implement the DescribeTasks method by returning the tasks of the requested
+// cluster whose ARNs were supplied.
+func (fake *fakeContainerService) DescribeTasks(input *ecs.DescribeTasksInput) (*ecs.DescribeTasksOutput, error) {
+	// DescribeTasks is limited to 100 tasks
+	if len(input.Tasks) > 100 {
+		return nil, errors.New("DescribeTasks only accepts up to 100 tasks")
+	}
+
+	// Loop through the requested ARNs
+	output := &ecs.DescribeTasksOutput{}
+	for _, arn := range input.Tasks {
+		for _, task := range fake.ClusterInfo.Tasks[*input.Cluster] {
+			if *task.TaskArn == *arn {
+				output.Tasks = append(output.Tasks, task)
+			}
+		}
+	}
+
+	return output, nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake ECR Image Digests
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// The digest of the "web:1.0" image in our fake ECR registry
+const testECRImageDigest = "sha256:5e0a1ca2c3f4b9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4"
+
+// This is our map of the tagged images (registry/region/repository:tag) in our fake
+// ECR registry and the digest each points to
+var ecrImageDigests = map[string]string{
+	"123456789012/eu-west-1/web:1.0": testECRImageDigest,
+}
+
+// This fake ECR service describes the images in ecrImageDigests (of its region). Any
+// other image is reported as not found.
+type fakeECRDigestService struct {
+	ecriface.ECRAPI
+	RegionName string
+}
+
+// Simulate the DescribeImagesPages function
+func (fake *fakeECRDigestService) DescribeImagesPages(input *ecr.DescribeImagesInput, fn func(*ecr.DescribeImagesOutput, bool) bool) error {
+	// Look up the (single) image that we are asked about
+	image := fmt.Sprintf("%s/%s/%s:%s", aws.StringValue(input.RegistryId), fake.RegionName,
+		aws.StringValue(input.RepositoryName), aws.StringValue(input.ImageIds[0].ImageTag))
+	digest, ok := ecrImageDigests[image]
+	if !ok {
+		return awserr.New(ecr.ErrCodeImageNotFoundException, "The image requested does not exist", nil)
+	}
+
+	fn(&ecr.DescribeImagesOutput{
+		ImageDetails: []*ecr.ImageDetail{
+			&ecr.ImageDetail{ImageDigest: aws.String(digest)},
+		},
+	}, true)
+
+	return nil
+}
+
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 // Fake Service Factory
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -211,23 +567,16 @@ func (fake *fakeContainerService) DescribeTaskDefinition(input *ecs.DescribeTask
 // This structure simulates the AWS Service Factory by storing some pregenerated
 // responses (that would come from AWS).
 type fakeCntrServiceFactory struct {
+	baseFakeServiceFactory
 	RegionName string
 	DRResponse *ec2.DescribeRegionsOutput
 }
 
-// Don't need to implement
-func (fsf fakeCntrServiceFactory) Init() {}
-
 // Return our current region
 func (fsf fakeCntrServiceFactory) GetCurrentRegion() string {
 	return fsf.RegionName
 }
 
-// Don't need to implement
-func (fsf fakeCntrServiceFactory) GetAccountIDService() *AccountIDService {
-	return nil
-}
-
 // This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
 // only.
 func (fsf fakeCntrServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
@@ -238,21 +587,6 @@ func (fsf fakeCntrServiceFactory) GetEC2InstanceService(string) *EC2InstanceServ
 	}
 }
 
-// Don't need to implement
-func (fsf fakeCntrServiceFactory) GetRDSInstanceService(regionName string) *RDSInstanceService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeCntrServiceFactory) GetS3Service() *S3Service {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeCntrServiceFactory) GetLambdaService(string) *LambdaService {
-	return nil
-}
-
 // Implement a way to return a ContainerService instance associated with a specific
 // region
 func (fsf fakeCntrServiceFactory) GetContainerService(regionName string) *ContainerService {
@@ -266,14 +600,19 @@ func (fsf fakeCntrServiceFactory) GetContainerService(regionName string) *Contai
 
 	return &ContainerService{
 		Client: &fakeContainerService{
-			TaskInfo: taskDefinitionsPerRegion[resolvedRegionName],
+			TaskInfo:    taskDefinitionsPerRegion[resolvedRegionName],
+			ClusterInfo: ecsWorkloadsPerRegion[resolvedRegionName],
 		},
 	}
 }
 
-// Don't need to implement
-func (fsf fakeCntrServiceFactory) GetLightsailService(string) *LightsailService {
-	return nil
+// Return a specialized ECRService that resolves the tags of our fake ECR images
+func (fsf fakeCntrServiceFactory) GetECRService(regionName string) *ECRService {
+	return &ECRService{
+		Client: &fakeECRDigestService{
+			RegionName: regionName,
+		},
+	}
 }
 
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -281,11 +620,14 @@ func (fsf fakeCntrServiceFactory) GetLightsailService(string) *LightsailService
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 
 func TestUniqueContainerImages(t *testing.T) {
-	// Describe all of our test cases: 2 failures and 4 success cases
+	// Describe all of our test cases: 5 failures and 17 success cases
 	cases := []struct {
 		RegionName    string
 		AllRegions    bool
+		Source        ContainerImageSource
+		Dedupe        ImageDedupeMode
 		ExpectedCount int
+		ExpectedECR   int
 		ExpectError   bool
 	}{
 		{
@@ -306,6 +648,74 @@ func TestUniqueContainerImages(t *testing.T) {
 		}, {
 			AllRegions:    true,
 			ExpectedCount: 4,
+		}, {
+			RegionName:    "us-east-1",
+			Source:        ActiveTaskDefinitions,
+			ExpectedCount: 2,
+		}, {
+			RegionName:    "us-east-2",
+			Source:        ActiveTaskDefinitions,
+			ExpectedCount: 2,
+		}, {
+			RegionName:    "af-south-1",
+			Source:        ActiveTaskDefinitions,
+			ExpectedCount: 0,
+		}, {
+			RegionName:  "undefined-region",
+			Source:      ActiveTaskDefinitions,
+			ExpectError: true,
+		}, {
+			AllRegions:    true,
+			Source:        ActiveTaskDefinitions,
+			ExpectedCount: 4,
+		}, {
+			RegionName:    "us-east-1",
+			Source:        RunningWorkloads,
+			ExpectedCount: 1,
+		}, {
+			RegionName:    "us-east-2",
+			Source:        RunningWorkloads,
+			ExpectedCount: 1,
+		}, {
+			RegionName:    "af-south-1",
+			Source:        RunningWorkloads,
+			ExpectedCount: 0,
+		}, {
+			RegionName:  "af-south-2",
+			Source:      RunningWorkloads,
+			ExpectError: true,
+		}, {
+			RegionName:  "undefined-region",
+			Source:      RunningWorkloads,
+			ExpectError: true,
+		}, {
+			AllRegions:    true,
+			Source:        RunningWorkloads,
+			ExpectedCount: 2,
+		}, {
+			RegionName:    "eu-west-1",
+			Dedupe:        DedupeRaw,
+			ExpectedCount: 8,
+			ExpectedECR:   3,
+		}, {
+			RegionName:    "eu-west-1",
+			ExpectedCount: 6,
+			ExpectedECR:   3,
+		}, {
+			RegionName:    "eu-west-1",
+			Dedupe:        DedupeByRepository,
+			ExpectedCount: 2,
+			ExpectedECR:   1,
+		}, {
+			RegionName:    "eu-west-1",
+			Dedupe:        DedupeByPinnedDigest,
+			ExpectedCount: 5,
+			ExpectedECR:   3,
+		}, {
+			RegionName:    "eu-west-1",
+			Dedupe:        DedupeByDigest,
+			ExpectedCount: 4,
+			ExpectedECR:   2,
 		},
 	}
 
@@ -321,7 +731,7 @@ func TestUniqueContainerImages(t *testing.T) {
 		mon := &mock.ActivityMonitorImpl{}
 
 		// Invoke our UniqueContainerImages function
-		actualCount := UniqueContainerImages(sf, mon, c.AllRegions)
+		actualCounts := UniqueContainerImages(sf, mon, c.AllRegions, ContainerImageOptions{Source: c.Source, Dedupe: c.Dedupe})
 
 		// Did we expect an error?
 		if c.ExpectError {
@@ -331,10 +741,71 @@ func TestUniqueContainerImages(t *testing.T) {
 			}
 		} else if mon.ErrorOccured {
 			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
-		} else if actualCount != c.ExpectedCount {
-			t.Errorf("Error: UniqueContainerImages returned %d; expected %d", actualCount, c.ExpectedCount)
+		} else if actualCounts.Unique != c.ExpectedCount {
+			t.Errorf("Error: UniqueContainerImages (%s) returned %d; expected %d", c.Source, actualCounts.Unique, c.ExpectedCount)
+		} else if actualCounts.ECR != c.ExpectedECR || actualCounts.Other != c.ExpectedCount-c.ExpectedECR {
+			t.Errorf("Error: UniqueContainerImages (%s) returned %d ECR and %d other images; expected %d and %d", c.Source, actualCounts.ECR, actualCounts.Other, c.ExpectedECR, c.ExpectedCount-c.ExpectedECR)
 		} else if mon.ProgramExited {
 			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
 		}
 	}
 }
+
+func TestUniqueContainerImagesConcurrentAndCached(t *testing.T) {
+	// Describe all of our test cases. Each starts with a cache that already knows
+	// about the task definition that cannot be described in AF-SOUTH-2 (simulating a
+	// revision described by a prior run).
+	cases := []struct {
+		RegionName       string
+		ExpectedCount    int
+		ExpectedCacheLen int
+	}{
+		{
+			RegionName:       "us-east-1",
+			ExpectedCount:    3,
+			ExpectedCacheLen: 4,
+		}, {
+			RegionName:       "us-east-2",
+			ExpectedCount:    2,
+			ExpectedCacheLen: 3,
+		}, {
+			RegionName:       "af-south-2",
+			ExpectedCount:    1,
+			ExpectedCacheLen: 1,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeCntrServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create our cache
+		cache := &TaskDefinitionCache{
+			images: map[string][]string{
+				"this-is-a-non-existent-task-arn-which-triggers-failure": []string{"image5"},
+			},
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our UniqueContainerImages function
+		actualCount := UniqueContainerImages(sf, mon, false, ContainerImageOptions{
+			Concurrency: 4,
+			Cache:       cache,
+		}).Unique
+
+		// Check our results
+		if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCount != c.ExpectedCount {
+			t.Errorf("Error: UniqueContainerImages returned %d for %s; expected %d", actualCount, c.RegionName, c.ExpectedCount)
+		} else if cache.Len() != c.ExpectedCacheLen {
+			t.Errorf("Error: TaskDefinitionCache holds %d entries for %s; expected %d", cache.Len(), c.RegionName, c.ExpectedCacheLen)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+/******************************************************************************
+Cloud Resource Counter
+File: customCounters.go
+
+Summary: Provides counts defined in a configuration file: each custom counter
+         names an AWS SDK operation to invoke and how to find (and filter) the
+         items to count in its output.
+******************************************************************************/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/jmespath/go-jmespath"
+	color "github.com/logrusorgru/aurora"
+)
+
+// CustomCountersFile is the format of a custom counters (JSON) file.
+type CustomCountersFile struct {
+	Counters []*CustomCounter `json:"counters"`
+}
+
+// CustomCounter describes how to count one kind of resource. For example, this counts
+// the Secrets Manager secrets that are not scheduled for deletion:
+//
+//   {
+//     "column": "# of Secrets",
+//     "service": "secretsmanager",
+//     "operation": "ListSecrets",
+//     "inputToken": "NextToken",
+//     "items": "SecretList",
+//     "filters": ["!DeletedDate"]
+//   }
+//
+// The items and filters are JMESPath expressions that are evaluated against the
+// operation's output (and each item, respectively). An item is only counted if all
+// of the filters are "truthy".
+type CustomCounter struct {
+	// The name of the CSV column
+	Column string `json:"column"`
+
+	// The SDK package name of the service (see IsGenericServiceName) and the name
+	// of the operation to invoke, along with any parameters of its input
+	Service   string                 `json:"service"`
+	Operation string                 `json:"operation"`
+	Input     map[string]interface{} `json:"input"`
+
+	// The name of the input field that takes the pagination token, and where to
+	// find the next token in the output. (The output token defaults to the input
+	// token.) If omitted, then the operation is invoked only once.
+	InputToken  string `json:"inputToken"`
+	OutputToken string `json:"outputToken"`
+
+	// Where to find the list of items in the output, and which items to count
+	Items   string   `json:"items"`
+	Filters []string `json:"filters"`
+
+	// Is this a global service? If so, the items are counted once (in the default
+	// region) rather than in each region.
+	Global bool `json:"global"`
+
+	// The compiled expressions
+	outputToken *jmespath.JMESPath
+	items       *jmespath.JMESPath
+	filters     []*jmespath.JMESPath
+}
+
+// LoadCustomCounters reads the custom counters from the named file, checking that
+// each is complete and that its expressions are valid.
+func LoadCustomCounters(fileName string) ([]*CustomCounter, error) {
+	// Read and parse the file
+	contents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var countersFile CustomCountersFile
+	if err = json.Unmarshal(contents, &countersFile); err != nil {
+		return nil, fmt.Errorf("%s is not a valid counters file: %v", fileName, err)
+	}
+
+	// Check each counter
+	for index, counter := range countersFile.Counters {
+		if err = counter.compile(); err != nil {
+			return nil, fmt.Errorf("%s: counter #%d: %v", fileName, index+1, err)
+		}
+	}
+
+	return countersFile.Counters, nil
+}
+
+// Check that the counter is complete and compile its expressions.
+func (cc *CustomCounter) compile() error {
+	// Check for the required fields
+	switch {
+	case cc.Column == "":
+		return fmt.Errorf("a column is required")
+	case !IsGenericServiceName(cc.Service):
+		return fmt.Errorf("'%s' is not a supported service", cc.Service)
+	case cc.Operation == "":
+		return fmt.Errorf("an operation is required")
+	case !IsReadOnlyOperation(cc.Operation):
+		return fmt.Errorf("'%s' is not a read-only operation: only List, Describe and Get operations may be called", cc.Operation)
+	case cc.Items == "":
+		return fmt.Errorf("an items expression is required")
+	case cc.OutputToken != "" && cc.InputToken == "":
+		return fmt.Errorf("an outputToken requires an inputToken")
+	}
+
+	// Compile the expressions
+	var err error
+	if cc.items, err = jmespath.Compile(cc.Items); err != nil {
+		return fmt.Errorf("invalid items expression '%s': %v", cc.Items, err)
+	}
+	if cc.InputToken != "" {
+		if cc.OutputToken == "" {
+			cc.OutputToken = cc.InputToken
+		}
+		if cc.outputToken, err = jmespath.Compile(cc.OutputToken); err != nil {
+			return fmt.Errorf("invalid outputToken expression '%s': %v", cc.OutputToken, err)
+		}
+	}
+	cc.filters = nil
+	for _, filter := range cc.Filters {
+		compiled, err := jmespath.Compile(filter)
+		if err != nil {
+			return fmt.Errorf("invalid filter expression '%s': %v", filter, err)
+		}
+		cc.filters = append(cc.filters, compiled)
+	}
+
+	return nil
+}
+
+// CustomCount retrieves the count of the supplied custom counter either for all
+// regions (allRegions is true) or the region associated with the session. The items
+// of a global counter are counted once; that count is for ALL REGIONS, even when a
+// single region is specified.
+//
+// This method gives status back to the user via the supplied ActivityMonitor
+// instance.
+func CustomCount(sf ServiceFactory, am ActivityMonitor, allRegions bool, counter *CustomCounter) int {
+	// Indicate activity
+	am.StartAction("Retrieving %s", counter.Column)
+
+	// Should we get the counts for all regions?
+	itemCount := 0
+	var qualify string
+	if counter.Global {
+		// Get the count once for all regions
+		itemCount = customCountForSingleRegion(sf.GetGenericService(counter.Service, DefaultRegion), am, counter)
+
+		// Should we "qualify" our count?
+		if !allRegions && itemCount > 0 {
+			qualify = "*"
+		}
+	} else if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the count for a specific region
+			itemCount += customCountForSingleRegion(sf.GetGenericService(counter.Service, regionName), am, counter)
+		}
+	} else {
+		// Get the count for the region selected by this session
+		itemCount = customCountForSingleRegion(sf.GetGenericService(counter.Service, ""), am, counter)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d%s)", color.Bold(itemCount), qualify)
+
+	return itemCount
+}
+
+// Get the count for a single region. We stop at the first error.
+func customCountForSingleRegion(gs *GenericService, am ActivityMonitor, counter *CustomCounter) int {
+	// Indicate activity
+	am.Message(".")
+
+	// Copy the input parameters (as we add the pagination token to them)
+	params := make(map[string]interface{})
+	for name, value := range counter.Input {
+		params[name] = value
+	}
+
+	// Loop through the pages of output...
+	itemCount := 0
+	for {
+		// Invoke the operation
+		output, err := gs.Invoke(counter.Operation, params)
+		if am.CheckError(err) {
+			return itemCount
+		}
+
+		// Count the items on this page
+		pageCount, err := counter.countItems(output)
+		if am.CheckError(err) {
+			return itemCount
+		}
+		itemCount += pageCount
+
+		// Is there another page?
+		if counter.outputToken == nil {
+			return itemCount
+		}
+		token, err := counter.outputToken.Search(output)
+		if am.CheckError(err) {
+			return itemCount
+		}
+		nextToken, _ := token.(string)
+		if nextToken == "" || nextToken == params[counter.InputToken] {
+			return itemCount
+		}
+		params[counter.InputToken] = nextToken
+	}
+}
+
+// Count the items in the supplied output that pass all of our filters.
+func (cc *CustomCounter) countItems(output interface{}) (int, error) {
+	// Find the items
+	result, err := cc.items.Search(output)
+	if err != nil {
+		return 0, err
+	}
+	if result == nil {
+		return 0, nil
+	}
+	items, ok := result.([]interface{})
+	if !ok {
+		return 0, fmt.Errorf("%s: the items expression '%s' does not select a list", cc.Column, cc.Items)
+	}
+
+	// Count the items that pass all of the filters
+	itemCount := 0
+	for _, item := range items {
+		passed := true
+		for _, filter := range cc.filters {
+			value, err := filter.Search(item)
+			if err != nil {
+				return 0, err
+			}
+			if !isTruthy(value) {
+				passed = false
+				break
+			}
+		}
+		if passed {
+			itemCount++
+		}
+	}
+
+	return itemCount, nil
+}
+
+// Is the supplied value "truthy" (according to JMESPath)? The false values are false,
+// null, empty strings, empty lists and empty objects.
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,459 @@
+/******************************************************************************
+Cloud Resource Counter
+File: customCounters_test.go
+
+Summary: The Unit Test for customCounters.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Custom Counter Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This is our map of regions and the pages of Secrets Manager secrets in each. A
+// missing region simulates an error.
+var secretsPerRegion = map[string][]*secretsmanager.ListSecretsOutput{
+	// US-EAST-1 has 3 secrets (over two pages), but one is scheduled for deletion
+	"us-east-1": {
+		{
+			SecretList: []*secretsmanager.SecretListEntry{
+				{
+					Name: aws.String("database-password"),
+				},
+				{
+					Name:        aws.String("old-api-key"),
+					DeletedDate: aws.Time(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)),
+				},
+			},
+		},
+		{
+			SecretList: []*secretsmanager.SecretListEntry{
+				{
+					Name: aws.String("api-key"),
+				},
+			},
+		},
+	},
+
+	// US-EAST-2 has no secrets
+	"us-east-2": {
+		{
+			SecretList: []*secretsmanager.SecretListEntry{},
+		},
+	},
+
+	// AF-SOUTH-1 has 1 secret
+	"af-south-1": {
+		{
+			SecretList: []*secretsmanager.SecretListEntry{
+				{
+					Name: aws.String("signing-key"),
+				},
+			},
+		},
+	},
+}
+
+// These are the pages of IAM roles (a global resource)
+var iamRolePages = []*iam.ListRolesOutput{
+	{
+		Roles: []*iam.Role{
+			{
+				RoleName: aws.String("admin"),
+			},
+			{
+				RoleName: aws.String("read-only"),
+			},
+		},
+	},
+	{
+		Roles: []*iam.Role{
+			{
+				RoleName: aws.String("lambda-execution"),
+			},
+		},
+	},
+}
+
+// Our custom counter of (not deleted) secrets, asking for 20 secrets at a time
+var secretsCounter = &CustomCounter{
+	Column:     "# of Secrets",
+	Service:    "secretsmanager",
+	Operation:  "ListSecrets",
+	Input:      map[string]interface{}{"MaxResults": 20},
+	InputToken: "NextToken",
+	Items:      "SecretList",
+	Filters:    []string{"!DeletedDate"},
+}
+
+// Our custom counter of IAM roles (which uses different input and output tokens)
+var rolesCounter = &CustomCounter{
+	Column:      "# of IAM Roles",
+	Service:     "iam",
+	Operation:   "ListRoles",
+	InputToken:  "Marker",
+	OutputToken: "IsTruncated && Marker",
+	Items:       "Roles",
+	Global:      true,
+}
+
+// A custom counter whose items are not a list
+var badItemsCounter = &CustomCounter{
+	Column:    "# of Bad Items",
+	Service:   "secretsmanager",
+	Operation: "ListSecrets",
+	Items:     "@",
+}
+
+// A custom counter of an operation that the service does not have
+var badOperationCounter = &CustomCounter{
+	Column:    "# of Bad Operations",
+	Service:   "secretsmanager",
+	Operation: "ListBingoPajamas",
+	Items:     "SecretList",
+}
+
+// A custom counter of an operation that changes the account (which must never be
+// called: our fake service does not even implement it). It cannot be compiled, so
+// this only checks that GenericService refuses to invoke it.
+var mutatingOperationCounter = &CustomCounter{
+	Column:    "# of Deleted Secrets",
+	Service:   "secretsmanager",
+	Operation: "DeleteSecret",
+	Input:     map[string]interface{}{"SecretId": "prod/db", "ForceDeleteWithoutRecovery": true},
+	Items:     "SecretList",
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Secrets Manager and IAM Services
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// To use this struct, the caller must supply the pages of secrets. If they are
+// missing, it simulates an error.
+type fakeSecretsManagerService struct {
+	secretsmanageriface.SecretsManagerAPI
+	SecretPages []*secretsmanager.ListSecretsOutput
+}
+
+// Simulate the ListSecrets function (a page at a time, using the NextToken)
+func (fake *fakeSecretsManagerService) ListSecrets(input *secretsmanager.ListSecretsInput) (*secretsmanager.ListSecretsOutput, error) {
+	// If the supplied pages are nil, then simulate an error
+	if fake.SecretPages == nil {
+		return nil, errors.New("ListSecrets encountered an unexpected error: 8642")
+	}
+
+	// If a page size is supplied, then it must be the one in our counter
+	if input.MaxResults != nil && aws.Int64Value(input.MaxResults) != 20 {
+		return nil, errors.New("ListSecrets received an unexpected MaxResults")
+	}
+
+	// Get the requested page
+	index, nextToken, err := fakePageIndex(input.NextToken, len(fake.SecretPages))
+	if err != nil {
+		return nil, err
+	}
+
+	return &secretsmanager.ListSecretsOutput{
+		SecretList: fake.SecretPages[index].SecretList,
+		NextToken:  nextToken,
+	}, nil
+}
+
+// To use this struct, the caller must supply the pages of roles. If they are
+// missing, it simulates an error.
+type fakeIAMService struct {
+	iamiface.IAMAPI
+	RolePages []*iam.ListRolesOutput
+}
+
+// Simulate the ListRoles function (a page at a time, using the Marker). Like IAM,
+// we always return a Marker, but it is only meaningful when the page is truncated.
+func (fake *fakeIAMService) ListRoles(input *iam.ListRolesInput) (*iam.ListRolesOutput, error) {
+	// If the supplied pages are nil, then simulate an error
+	if fake.RolePages == nil {
+		return nil, errors.New("ListRoles encountered an unexpected error: 9753")
+	}
+
+	// Get the requested page
+	index, nextToken, err := fakePageIndex(input.Marker, len(fake.RolePages))
+	if err != nil {
+		return nil, err
+	}
+
+	output := &iam.ListRolesOutput{
+		Roles:       fake.RolePages[index].Roles,
+		IsTruncated: aws.Bool(nextToken != nil),
+		Marker:      nextToken,
+	}
+	if nextToken == nil {
+		output.Marker = aws.String("stale-marker")
+	}
+
+	return output, nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeCustomCountersServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Resolve the supplied region name (or use the region associated with our factory)
+func (fsf fakeCustomCountersServiceFactory) resolveRegion(regionName string) string {
+	if regionName == "" {
+		return fsf.RegionName
+	}
+
+	return regionName
+}
+
+// Return our current region
+func (fsf fakeCustomCountersServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeCustomCountersServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return a GenericService whose client is a fake Secrets Manager or IAM service. (IAM
+// roles can only be listed in the default region.)
+func (fsf fakeCustomCountersServiceFactory) GetGenericService(serviceName string, regionName string) *GenericService {
+	switch serviceName {
+	case "secretsmanager":
+		return &GenericService{
+			Client: &fakeSecretsManagerService{
+				SecretPages: secretsPerRegion[fsf.resolveRegion(regionName)],
+			},
+		}
+	case "iam":
+		fake := &fakeIAMService{}
+		if fsf.resolveRegion(regionName) == DefaultRegion {
+			fake.RolePages = iamRolePages
+		}
+		return &GenericService{
+			Client: fake,
+		}
+	default:
+		return nil
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for CustomCount
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestCustomCount(t *testing.T) {
+	// Compile our counters
+	for _, counter := range []*CustomCounter{secretsCounter, rolesCounter, badItemsCounter, badOperationCounter} {
+		if err := counter.compile(); err != nil {
+			t.Fatalf("Unexpected error compiling %s: %v", counter.Column, err)
+		}
+	}
+
+	// Describe all of our test cases: 5 failures and 6 successes
+	cases := []struct {
+		RegionName    string
+		AllRegions    bool
+		Counter       *CustomCounter
+		ExpectedCount int
+		ExpectError   bool
+	}{
+		{
+			RegionName:    "us-east-1",
+			Counter:       secretsCounter,
+			ExpectedCount: 2,
+		}, {
+			RegionName:    "us-east-2",
+			Counter:       secretsCounter,
+			ExpectedCount: 0,
+		}, {
+			RegionName:    "af-south-1",
+			Counter:       secretsCounter,
+			ExpectedCount: 1,
+		}, {
+			AllRegions:    true,
+			Counter:       secretsCounter,
+			ExpectedCount: 3,
+		}, {
+			RegionName:    "af-south-1",
+			Counter:       rolesCounter,
+			ExpectedCount: 3,
+		}, {
+			AllRegions:    true,
+			Counter:       rolesCounter,
+			ExpectedCount: 3,
+		}, {
+			RegionName:  "undefined-region",
+			Counter:     secretsCounter,
+			ExpectError: true,
+		}, {
+			RegionName:  "us-east-1",
+			Counter:     badItemsCounter,
+			ExpectError: true,
+		}, {
+			RegionName:  "us-east-1",
+			Counter:     badOperationCounter,
+			ExpectError: true,
+		}, {
+			RegionName:  "us-east-1",
+			Counter:     mutatingOperationCounter,
+			ExpectError: true,
+		}, {
+			RegionName: "us-east-1",
+			Counter: &CustomCounter{
+				Column:    "# of Bad Inputs",
+				Service:   "secretsmanager",
+				Operation: "ListSecrets",
+				Input:     map[string]interface{}{"MaxResults": "twenty"},
+				Items:     "SecretList",
+				items:     secretsCounter.items,
+			},
+			ExpectError: true,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeCustomCountersServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our CustomCount function
+		actualCount := CustomCount(sf, mon, c.AllRegions, c.Counter)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Errorf("Expected an error to occur for %s, but it did not... :^(", c.Counter.Column)
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCount != c.ExpectedCount {
+			t.Errorf("Error: CustomCount returned %d for %s; expected %d", actualCount, c.Counter.Column, c.ExpectedCount)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for LoadCustomCounters
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestLoadCustomCounters(t *testing.T) {
+	cases := []struct {
+		Contents      string
+		ExpectedCount int
+		ExpectError   bool
+	}{
+		{
+			Contents: `{"counters": [
+				{"column": "# of Secrets", "service": "secretsmanager", "operation": "ListSecrets",
+				 "inputToken": "NextToken", "items": "SecretList", "filters": ["!DeletedDate"]},
+				{"column": "# of KMS Keys", "service": "kms", "operation": "ListKeys",
+				 "inputToken": "Marker", "outputToken": "NextMarker", "items": "Keys"}
+			]}`,
+			ExpectedCount: 2,
+		}, {
+			Contents:      `{"counters": []}`,
+			ExpectedCount: 0,
+		}, {
+			Contents:    `{"counters": [`,
+			ExpectError: true,
+		}, {
+			Contents:    `{"counters": [{"service": "kms", "operation": "ListKeys", "items": "Keys"}]}`,
+			ExpectError: true,
+		}, {
+			Contents:    `{"counters": [{"column": "# of Things", "service": "bingo", "operation": "ListThings", "items": "Things"}]}`,
+			ExpectError: true,
+		}, {
+			Contents:    `{"counters": [{"column": "# of Keys", "service": "kms", "items": "Keys"}]}`,
+			ExpectError: true,
+		}, {
+			Contents:    `{"counters": [{"column": "# of Instances", "service": "ec2", "operation": "TerminateInstances", "items": "TerminatingInstances"}]}`,
+			ExpectError: true,
+		}, {
+			Contents:    `{"counters": [{"column": "# of Secrets", "service": "secretsmanager", "operation": "DeleteSecret", "items": "SecretList"}]}`,
+			ExpectError: true,
+		}, {
+			Contents:    `{"counters": [{"column": "# of Keys", "service": "kms", "operation": "ListKeys"}]}`,
+			ExpectError: true,
+		}, {
+			Contents:    `{"counters": [{"column": "# of Keys", "service": "kms", "operation": "ListKeys", "items": "Keys[?"}]}`,
+			ExpectError: true,
+		}, {
+			Contents:    `{"counters": [{"column": "# of Keys", "service": "kms", "operation": "ListKeys", "items": "Keys", "filters": ["=="]}]}`,
+			ExpectError: true,
+		}, {
+			Contents:    `{"counters": [{"column": "# of Keys", "service": "kms", "operation": "ListKeys", "items": "Keys", "outputToken": "NextMarker"}]}`,
+			ExpectError: true,
+		},
+	}
+
+	// Loop through each test case
+	for index, c := range cases {
+		// Write the contents to a temporary file
+		file, err := ioutil.TempFile("", "counters-*.json")
+		if err != nil {
+			t.Fatalf("Unexpected error creating temporary file: %v", err)
+		}
+		file.WriteString(c.Contents)
+		file.Close()
+
+		// Load the counters
+		counters, err := LoadCustomCounters(file.Name())
+		os.Remove(file.Name())
+
+		// Did we expect an error?
+		if c.ExpectError {
+			if err == nil {
+				t.Errorf("Case %d: Expected an error to occur, but it did not... :^(", index)
+			}
+		} else if err != nil {
+			t.Errorf("Case %d: Unexpected error occurred: %v", index, err)
+		} else if len(counters) != c.ExpectedCount {
+			t.Errorf("Case %d: LoadCustomCounters returned %d counters; expected %d", index, len(counters), c.ExpectedCount)
+		}
+	}
+
+	// A missing file is an error
+	if _, err := LoadCustomCounters("no-such-counters-file.json"); err == nil {
+		t.Error("Expected an error for a missing file, but it did not occur")
+	}
+}
@@ -0,0 +1,176 @@
+/******************************************************************************
+Cloud Resource Counter
+File: dataServices.go
+
+Summary: Provides a count of managed data stores (other than RDS): ElastiCache,
+         Redshift, OpenSearch, DocumentDB and Neptune.
+******************************************************************************/
+
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/docdb"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/neptune"
+	"github.com/aws/aws-sdk-go/service/opensearchservice"
+	"github.com/aws/aws-sdk-go/service/redshift"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless"
+	color "github.com/logrusorgru/aurora"
+)
+
+// DataServiceCounts holds the number of managed data stores of each kind.
+type DataServiceCounts struct {
+	CacheClusters      int
+	ReplicationGroups  int
+	RedshiftClusters   int
+	RedshiftWorkgroups int
+	OpenSearchDomains  int
+	DocumentDBClusters int
+	NeptuneClusters    int
+}
+
+// Add the supplied counts into our struct.
+func (dsc *DataServiceCounts) Add(other DataServiceCounts) {
+	dsc.CacheClusters += other.CacheClusters
+	dsc.ReplicationGroups += other.ReplicationGroups
+	dsc.RedshiftClusters += other.RedshiftClusters
+	dsc.RedshiftWorkgroups += other.RedshiftWorkgroups
+	dsc.OpenSearchDomains += other.OpenSearchDomains
+	dsc.DocumentDBClusters += other.DocumentDBClusters
+	dsc.NeptuneClusters += other.NeptuneClusters
+}
+
+// Total returns the number of all data stores.
+func (dsc DataServiceCounts) Total() int {
+	return dsc.CacheClusters + dsc.ReplicationGroups + dsc.RedshiftClusters + dsc.RedshiftWorkgroups +
+		dsc.OpenSearchDomains + dsc.DocumentDBClusters + dsc.NeptuneClusters
+}
+
+// DataServices retrieves the counts of ElastiCache cache clusters and replication
+// groups, Redshift clusters and serverless workgroups, OpenSearch domains and
+// DocumentDB and Neptune clusters either for all regions (allRegions is true) or
+// the region associated with the session. This method gives status back to the
+// user via the supplied ActivityMonitor instance.
+func DataServices(sf ServiceFactory, am ActivityMonitor, allRegions bool) DataServiceCounts {
+	// Indicate activity
+	am.StartAction("Retrieving Data Service counts")
+
+	// Should we get the counts for all regions?
+	var dataCounts DataServiceCounts
+	if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the data service counts for a specific region
+			dataCounts.Add(dataServicesForSingleRegion(sf, regionName, am))
+		}
+	} else {
+		// Get the data service counts for the region selected by this session
+		dataCounts = dataServicesForSingleRegion(sf, "", am)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d)", color.Bold(dataCounts.Total()))
+
+	return dataCounts
+}
+
+// Get the data service counts for a single region. We stop at the first error.
+func dataServicesForSingleRegion(sf ServiceFactory, regionName string, am ActivityMonitor) DataServiceCounts {
+	// Indicate activity
+	am.Message(".")
+
+	var dataCounts DataServiceCounts
+
+	// Count the ElastiCache cache clusters that are not members of a replication
+	// group (the replication group itself is counted below)...
+	ecss := sf.GetElastiCacheService(regionName)
+	err := ecss.DescribeCacheClusters(&elasticache.DescribeCacheClustersInput{
+		ShowCacheClustersNotInReplicationGroups: aws.Bool(true),
+	}, func(page *elasticache.DescribeCacheClustersOutput, lastPage bool) bool {
+		dataCounts.CacheClusters += len(page.CacheClusters)
+
+		return true
+	})
+	if am.CheckError(err) {
+		return dataCounts
+	}
+
+	// ...and replication groups
+	err = ecss.DescribeReplicationGroups(&elasticache.DescribeReplicationGroupsInput{}, func(page *elasticache.DescribeReplicationGroupsOutput, lastPage bool) bool {
+		dataCounts.ReplicationGroups += len(page.ReplicationGroups)
+
+		return true
+	})
+	if am.CheckError(err) {
+		return dataCounts
+	}
+
+	// Count the Redshift clusters
+	err = sf.GetRedshiftService(regionName).DescribeClusters(&redshift.DescribeClustersInput{}, func(page *redshift.DescribeClustersOutput, lastPage bool) bool {
+		dataCounts.RedshiftClusters += len(page.Clusters)
+
+		return true
+	})
+	if am.CheckError(err) {
+		return dataCounts
+	}
+
+	// Count the Redshift Serverless workgroups. Redshift Serverless is not offered
+	// in every region; where it is not, the region simply has none.
+	err = sf.GetRedshiftServerlessService(regionName).ListWorkgroups(&redshiftserverless.ListWorkgroupsInput{}, func(page *redshiftserverless.ListWorkgroupsOutput, lastPage bool) bool {
+		dataCounts.RedshiftWorkgroups += len(page.Workgroups)
+
+		return true
+	})
+	if !IsServiceUnavailableError(err) && am.CheckError(err) {
+		return dataCounts
+	}
+
+	// Count the OpenSearch domains (again, treating an unavailable service as none)
+	domains, err := sf.GetOpenSearchService(regionName).ListDomainNames(&opensearchservice.ListDomainNamesInput{})
+	if IsServiceUnavailableError(err) {
+		domains = &opensearchservice.ListDomainNamesOutput{}
+	} else if am.CheckError(err) {
+		return dataCounts
+	}
+	dataCounts.OpenSearchDomains = len(domains.DomainNames)
+
+	// Count the DocumentDB clusters. (The DocumentDB API also returns RDS and
+	// Neptune clusters, so we must filter by engine.)
+	err = sf.GetDocumentDBService(regionName).DescribeDBClusters(&docdb.DescribeDBClustersInput{
+		Filters: []*docdb.Filter{
+			&docdb.Filter{
+				Name:   aws.String("engine"),
+				Values: aws.StringSlice([]string{"docdb"}),
+			},
+		},
+	}, func(page *docdb.DescribeDBClustersOutput, lastPage bool) bool {
+		dataCounts.DocumentDBClusters += len(page.DBClusters)
+
+		return true
+	})
+	if am.CheckError(err) {
+		return dataCounts
+	}
+
+	// Count the Neptune clusters (again, filtering by engine)
+	err = sf.GetNeptuneService(regionName).DescribeDBClusters(&neptune.DescribeDBClustersInput{
+		Filters: []*neptune.Filter{
+			&neptune.Filter{
+				Name:   aws.String("engine"),
+				Values: aws.StringSlice([]string{"neptune"}),
+			},
+		},
+	}, func(page *neptune.DescribeDBClustersOutput, lastPage bool) bool {
+		dataCounts.NeptuneClusters += len(page.DBClusters)
+
+		return true
+	})
+	am.CheckError(err)
+
+	return dataCounts
+}
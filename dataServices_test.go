@@ -0,0 +1,555 @@
+/******************************************************************************
+Cloud Resource Counter
+File: dataServices_test.go
+
+Summary: The Unit Test for dataServices.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/docdb"
+	"github.com/aws/aws-sdk-go/service/docdb/docdbiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/elasticache/elasticacheiface"
+	"github.com/aws/aws-sdk-go/service/neptune"
+	"github.com/aws/aws-sdk-go/service/neptune/neptuneiface"
+	"github.com/aws/aws-sdk-go/service/opensearchservice"
+	"github.com/aws/aws-sdk-go/service/opensearchservice/opensearchserviceiface"
+	"github.com/aws/aws-sdk-go/service/redshift"
+	"github.com/aws/aws-sdk-go/service/redshift/redshiftiface"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless/redshiftserverlessiface"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Data Service Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// DataServiceRegionInfo describes the fake data stores in a region. Each slice
+// holds the pages returned by the corresponding API. If ServerlessUnavailable is
+// set, Redshift Serverless and OpenSearch are not offered in the region.
+type DataServiceRegionInfo struct {
+	ServerlessUnavailable bool
+	CacheClusterPages     []*elasticache.DescribeCacheClustersOutput
+	ReplicationGroupPages []*elasticache.DescribeReplicationGroupsOutput
+	RedshiftClusterPages  []*redshift.DescribeClustersOutput
+	WorkgroupPages        []*redshiftserverless.ListWorkgroupsOutput
+	DomainNames           *opensearchservice.ListDomainNamesOutput
+	DocumentDBPages       []*docdb.DescribeDBClustersOutput
+	NeptunePages          []*neptune.DescribeDBClustersOutput
+}
+
+// This is our map of regions and the data stores in each
+var dataServicesPerRegion = map[string]*DataServiceRegionInfo{
+	// US-EAST-1 illustrates a case where most APIs return two pages of results:
+	// 3 cache clusters (2 of which are members of the "redis" replication group,
+	// and so not counted), 2 replication groups, 2 Redshift clusters, 1 workgroup,
+	// 2 OpenSearch domains, 3 DocumentDB clusters and 1 Neptune cluster.
+	"us-east-1": &DataServiceRegionInfo{
+		CacheClusterPages: []*elasticache.DescribeCacheClustersOutput{
+			&elasticache.DescribeCacheClustersOutput{
+				CacheClusters: []*elasticache.CacheCluster{
+					&elasticache.CacheCluster{CacheClusterId: aws.String("redis-001"), ReplicationGroupId: aws.String("redis")},
+					&elasticache.CacheCluster{CacheClusterId: aws.String("redis-002"), ReplicationGroupId: aws.String("redis")},
+				},
+			},
+			&elasticache.DescribeCacheClustersOutput{
+				CacheClusters: []*elasticache.CacheCluster{
+					&elasticache.CacheCluster{CacheClusterId: aws.String("memcached")},
+				},
+			},
+		},
+		ReplicationGroupPages: []*elasticache.DescribeReplicationGroupsOutput{
+			&elasticache.DescribeReplicationGroupsOutput{
+				ReplicationGroups: []*elasticache.ReplicationGroup{
+					&elasticache.ReplicationGroup{ReplicationGroupId: aws.String("redis")},
+				},
+			},
+			&elasticache.DescribeReplicationGroupsOutput{
+				ReplicationGroups: []*elasticache.ReplicationGroup{
+					&elasticache.ReplicationGroup{ReplicationGroupId: aws.String("sessions")},
+				},
+			},
+		},
+		RedshiftClusterPages: []*redshift.DescribeClustersOutput{
+			&redshift.DescribeClustersOutput{
+				Clusters: []*redshift.Cluster{
+					&redshift.Cluster{ClusterIdentifier: aws.String("warehouse")},
+				},
+			},
+			&redshift.DescribeClustersOutput{
+				Clusters: []*redshift.Cluster{
+					&redshift.Cluster{ClusterIdentifier: aws.String("reporting")},
+				},
+			},
+		},
+		WorkgroupPages: []*redshiftserverless.ListWorkgroupsOutput{
+			&redshiftserverless.ListWorkgroupsOutput{
+				Workgroups: []*redshiftserverless.Workgroup{
+					&redshiftserverless.Workgroup{WorkgroupName: aws.String("adhoc")},
+				},
+			},
+		},
+		DomainNames: &opensearchservice.ListDomainNamesOutput{
+			DomainNames: []*opensearchservice.DomainInfo{
+				&opensearchservice.DomainInfo{DomainName: aws.String("logs")},
+				&opensearchservice.DomainInfo{DomainName: aws.String("search")},
+			},
+		},
+		DocumentDBPages: []*docdb.DescribeDBClustersOutput{
+			&docdb.DescribeDBClustersOutput{
+				DBClusters: []*docdb.DBCluster{
+					&docdb.DBCluster{DBClusterIdentifier: aws.String("catalog")},
+					&docdb.DBCluster{DBClusterIdentifier: aws.String("profiles")},
+				},
+			},
+			&docdb.DescribeDBClustersOutput{
+				DBClusters: []*docdb.DBCluster{
+					&docdb.DBCluster{DBClusterIdentifier: aws.String("events")},
+				},
+			},
+		},
+		NeptunePages: []*neptune.DescribeDBClustersOutput{
+			&neptune.DescribeDBClustersOutput{
+				DBClusters: []*neptune.DBCluster{
+					&neptune.DBCluster{DBClusterIdentifier: aws.String("graph")},
+				},
+			},
+		},
+	},
+	// US-EAST-2 has 1 Redshift cluster and 1 OpenSearch domain (and nothing else)
+	"us-east-2": &DataServiceRegionInfo{
+		CacheClusterPages: []*elasticache.DescribeCacheClustersOutput{
+			&elasticache.DescribeCacheClustersOutput{},
+		},
+		ReplicationGroupPages: []*elasticache.DescribeReplicationGroupsOutput{
+			&elasticache.DescribeReplicationGroupsOutput{},
+		},
+		RedshiftClusterPages: []*redshift.DescribeClustersOutput{
+			&redshift.DescribeClustersOutput{
+				Clusters: []*redshift.Cluster{
+					&redshift.Cluster{ClusterIdentifier: aws.String("archive")},
+				},
+			},
+		},
+		WorkgroupPages: []*redshiftserverless.ListWorkgroupsOutput{
+			&redshiftserverless.ListWorkgroupsOutput{},
+		},
+		DomainNames: &opensearchservice.ListDomainNamesOutput{
+			DomainNames: []*opensearchservice.DomainInfo{
+				&opensearchservice.DomainInfo{DomainName: aws.String("audit")},
+			},
+		},
+		DocumentDBPages: []*docdb.DescribeDBClustersOutput{
+			&docdb.DescribeDBClustersOutput{},
+		},
+		NeptunePages: []*neptune.DescribeDBClustersOutput{
+			&neptune.DescribeDBClustersOutput{},
+		},
+	},
+	// AF-SOUTH-1 has no data stores (and does not offer Redshift Serverless or OpenSearch)
+	"af-south-1": &DataServiceRegionInfo{
+		ServerlessUnavailable: true,
+		CacheClusterPages: []*elasticache.DescribeCacheClustersOutput{
+			&elasticache.DescribeCacheClustersOutput{},
+		},
+		ReplicationGroupPages: []*elasticache.DescribeReplicationGroupsOutput{
+			&elasticache.DescribeReplicationGroupsOutput{},
+		},
+		RedshiftClusterPages: []*redshift.DescribeClustersOutput{
+			&redshift.DescribeClustersOutput{},
+		},
+		DocumentDBPages: []*docdb.DescribeDBClustersOutput{
+			&docdb.DescribeDBClustersOutput{},
+		},
+		NeptunePages: []*neptune.DescribeDBClustersOutput{
+			&neptune.DescribeDBClustersOutput{},
+		},
+	},
+	// AF-SOUTH-2 has a cache cluster, but Redshift is not available (an error)
+	"af-south-2": &DataServiceRegionInfo{
+		CacheClusterPages: []*elasticache.DescribeCacheClustersOutput{
+			&elasticache.DescribeCacheClustersOutput{
+				CacheClusters: []*elasticache.CacheCluster{
+					&elasticache.CacheCluster{CacheClusterId: aws.String("redis-001")},
+				},
+			},
+		},
+		ReplicationGroupPages: []*elasticache.DescribeReplicationGroupsOutput{
+			&elasticache.DescribeReplicationGroupsOutput{},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Data Services
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// To use these structs, the caller must supply a DataServiceRegionInfo. If it (or
+// the pages for a particular API) is missing, it will trigger the mock functions to
+// simulate an error.
+type fakeElastiCacheService struct {
+	elasticacheiface.ElastiCacheAPI
+	RegionInfo *DataServiceRegionInfo
+}
+
+// Simulate the DescribeCacheClustersPages function
+func (fake *fakeElastiCacheService) DescribeCacheClustersPages(input *elasticache.DescribeCacheClustersInput, fn func(*elasticache.DescribeCacheClustersOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.CacheClusterPages == nil {
+		return errors.New("DescribeCacheClustersPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function. Like
+	// AWS, leave out the members of replication groups if asked to.
+	standaloneOnly := aws.BoolValue(input.ShowCacheClustersNotInReplicationGroups)
+	for index, output := range fake.RegionInfo.CacheClusterPages {
+		page := output
+		if standaloneOnly {
+			page = &elasticache.DescribeCacheClustersOutput{}
+			for _, cluster := range output.CacheClusters {
+				if cluster.ReplicationGroupId == nil {
+					page.CacheClusters = append(page.CacheClusters, cluster)
+				}
+			}
+		}
+
+		if !fn(page, index == len(fake.RegionInfo.CacheClusterPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Simulate the DescribeReplicationGroupsPages function
+func (fake *fakeElastiCacheService) DescribeReplicationGroupsPages(input *elasticache.DescribeReplicationGroupsInput, fn func(*elasticache.DescribeReplicationGroupsOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.ReplicationGroupPages == nil {
+		return errors.New("DescribeReplicationGroupsPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.ReplicationGroupPages {
+		if !fn(output, index == len(fake.RegionInfo.ReplicationGroupPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+type fakeRedshiftService struct {
+	redshiftiface.RedshiftAPI
+	RegionInfo *DataServiceRegionInfo
+}
+
+// Simulate the DescribeClustersPages function
+func (fake *fakeRedshiftService) DescribeClustersPages(input *redshift.DescribeClustersInput, fn func(*redshift.DescribeClustersOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.RedshiftClusterPages == nil {
+		return errors.New("DescribeClustersPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.RedshiftClusterPages {
+		if !fn(output, index == len(fake.RegionInfo.RedshiftClusterPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+type fakeRedshiftServerlessService struct {
+	redshiftserverlessiface.RedshiftServerlessAPI
+	RegionInfo *DataServiceRegionInfo
+}
+
+// Simulate the error returned when a service's endpoint does not exist in a region
+func serviceUnavailableError() error {
+	return awserr.New(request.ErrCodeRequestError, "send request failed", &net.DNSError{
+		Err:        "no such host",
+		Name:       "service.af-south-1.amazonaws.com",
+		IsNotFound: true,
+	})
+}
+
+// Simulate the ListWorkgroupsPages function
+func (fake *fakeRedshiftServerlessService) ListWorkgroupsPages(input *redshiftserverless.ListWorkgroupsInput, fn func(*redshiftserverless.ListWorkgroupsOutput, bool) bool) error {
+	// Is the service offered in this region?
+	if fake.RegionInfo != nil && fake.RegionInfo.ServerlessUnavailable {
+		return serviceUnavailableError()
+	}
+
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.WorkgroupPages == nil {
+		return errors.New("ListWorkgroupsPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.WorkgroupPages {
+		if !fn(output, index == len(fake.RegionInfo.WorkgroupPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+type fakeOpenSearchService struct {
+	opensearchserviceiface.OpenSearchServiceAPI
+	RegionInfo *DataServiceRegionInfo
+}
+
+// Simulate the ListDomainNames function
+func (fake *fakeOpenSearchService) ListDomainNames(input *opensearchservice.ListDomainNamesInput) (*opensearchservice.ListDomainNamesOutput, error) {
+	// Is the service offered in this region?
+	if fake.RegionInfo != nil && fake.RegionInfo.ServerlessUnavailable {
+		return nil, serviceUnavailableError()
+	}
+
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.DomainNames == nil {
+		return nil, errors.New("ListDomainNames encountered an unexpected error: 1234")
+	}
+
+	return fake.RegionInfo.DomainNames, nil
+}
+
+type fakeDocumentDBService struct {
+	docdbiface.DocDBAPI
+	RegionInfo *DataServiceRegionInfo
+}
+
+// Simulate the DescribeDBClustersPages function
+func (fake *fakeDocumentDBService) DescribeDBClustersPages(input *docdb.DescribeDBClustersInput, fn func(*docdb.DescribeDBClustersOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.DocumentDBPages == nil {
+		return errors.New("DescribeDBClustersPages encountered an unexpected error: 1234")
+	}
+
+	// We only support filtering by the "docdb" engine
+	if len(input.Filters) != 1 || aws.StringValue(input.Filters[0].Name) != "engine" ||
+		len(input.Filters[0].Values) != 1 || aws.StringValue(input.Filters[0].Values[0]) != "docdb" {
+		return errors.New("The unit test only supports a DescribeDBClustersInput that filters by the docdb engine")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.DocumentDBPages {
+		if !fn(output, index == len(fake.RegionInfo.DocumentDBPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+type fakeNeptuneService struct {
+	neptuneiface.NeptuneAPI
+	RegionInfo *DataServiceRegionInfo
+}
+
+// Simulate the DescribeDBClustersPages function
+func (fake *fakeNeptuneService) DescribeDBClustersPages(input *neptune.DescribeDBClustersInput, fn func(*neptune.DescribeDBClustersOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.NeptunePages == nil {
+		return errors.New("DescribeDBClustersPages encountered an unexpected error: 1234")
+	}
+
+	// We only support filtering by the "neptune" engine
+	if len(input.Filters) != 1 || aws.StringValue(input.Filters[0].Name) != "engine" ||
+		len(input.Filters[0].Values) != 1 || aws.StringValue(input.Filters[0].Values[0]) != "neptune" {
+		return errors.New("The unit test only supports a DescribeDBClustersInput that filters by the neptune engine")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.NeptunePages {
+		if !fn(output, index == len(fake.RegionInfo.NeptunePages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeDataServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Get the data services of the supplied region (or the region associated with our factory)
+func (fsf fakeDataServiceFactory) regionInfo(regionName string) *DataServiceRegionInfo {
+	if regionName == "" {
+		return dataServicesPerRegion[fsf.RegionName]
+	}
+
+	return dataServicesPerRegion[regionName]
+}
+
+// Return our current region
+func (fsf fakeDataServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeDataServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return a specialized ElastiCacheService that returns pre-canned responses
+func (fsf fakeDataServiceFactory) GetElastiCacheService(regionName string) *ElastiCacheService {
+	return &ElastiCacheService{
+		Client: &fakeElastiCacheService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized RedshiftService that returns pre-canned responses
+func (fsf fakeDataServiceFactory) GetRedshiftService(regionName string) *RedshiftService {
+	return &RedshiftService{
+		Client: &fakeRedshiftService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized RedshiftServerlessService that returns pre-canned responses
+func (fsf fakeDataServiceFactory) GetRedshiftServerlessService(regionName string) *RedshiftServerlessService {
+	return &RedshiftServerlessService{
+		Client: &fakeRedshiftServerlessService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized OpenSearchService that returns pre-canned responses
+func (fsf fakeDataServiceFactory) GetOpenSearchService(regionName string) *OpenSearchService {
+	return &OpenSearchService{
+		Client: &fakeOpenSearchService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized DocumentDBService that returns pre-canned responses
+func (fsf fakeDataServiceFactory) GetDocumentDBService(regionName string) *DocumentDBService {
+	return &DocumentDBService{
+		Client: &fakeDocumentDBService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized NeptuneService that returns pre-canned responses
+func (fsf fakeDataServiceFactory) GetNeptuneService(regionName string) *NeptuneService {
+	return &NeptuneService{
+		Client: &fakeNeptuneService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for DataServices
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestDataServices(t *testing.T) {
+	// Describe all of our test cases: 2 failures and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts DataServiceCounts
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: DataServiceCounts{
+				CacheClusters:      1,
+				ReplicationGroups:  2,
+				RedshiftClusters:   2,
+				RedshiftWorkgroups: 1,
+				OpenSearchDomains:  2,
+				DocumentDBClusters: 3,
+				NeptuneClusters:    1,
+			},
+		}, {
+			RegionName: "us-east-2",
+			ExpectedCounts: DataServiceCounts{
+				RedshiftClusters:  1,
+				OpenSearchDomains: 1,
+			},
+		}, {
+			RegionName: "af-south-1",
+		}, {
+			RegionName:  "af-south-2",
+			ExpectError: true,
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions: true,
+			ExpectedCounts: DataServiceCounts{
+				CacheClusters:      1,
+				ReplicationGroups:  2,
+				RedshiftClusters:   3,
+				RedshiftWorkgroups: 1,
+				OpenSearchDomains:  3,
+				DocumentDBClusters: 3,
+				NeptuneClusters:    1,
+			},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeDataServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our Data Services function
+		actualCounts := DataServices(sf, mon, c.AllRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: DataServices returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
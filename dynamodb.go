@@ -0,0 +1,133 @@
+/******************************************************************************
+Cloud Resource Counter
+File: dynamodb.go
+
+Summary: Provides a count of all DynamoDB tables.
+******************************************************************************/
+
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	color "github.com/logrusorgru/aurora"
+)
+
+// DynamoDBTables retrieves the count of all DynamoDB tables either for all regions
+// (allRegions is true) or the region associated with the session. A global table
+// has a replica (a table of the same name) in each of its regions. Unless
+// countReplicas is true, each global table is counted once no matter how many
+// regions it is replicated into; this requires describing every table. (The tables
+// of legacy global tables, version 2017.11.29, do not list their replicas, so we
+// also list the legacy global tables of each region.) This method gives status
+// back to the user via the supplied ActivityMonitor instance.
+func DynamoDBTables(sf ServiceFactory, am ActivityMonitor, allRegions bool, countReplicas bool) int {
+	// Indicate activity
+	am.StartAction("Retrieving DynamoDB table counts")
+
+	// Should we get the counts for all regions?
+	tableCount := 0
+	globalTables := make(map[string]bool)
+	if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the DynamoDB counts for a specific region
+			tableCount += dynamoDBTablesForSingleRegion(sf.GetDynamoDBService(regionName), am, countReplicas, globalTables)
+		}
+	} else {
+		// Get the DynamoDB counts for the region selected by this session
+		tableCount = dynamoDBTablesForSingleRegion(sf.GetDynamoDBService(""), am, countReplicas, globalTables)
+	}
+
+	// Add in the global tables (each counted once)
+	tableCount += len(globalTables)
+
+	// Indicate end of activity
+	am.EndAction("OK (%d)", color.Bold(tableCount))
+
+	return tableCount
+}
+
+// Get the number of DynamoDB tables in a single region. Unless countReplicas is
+// true, the names of global tables are added to the supplied map (and are not
+// included in the returned count).
+func dynamoDBTablesForSingleRegion(ddbs *DynamoDBService, am ActivityMonitor, countReplicas bool, globalTables map[string]bool) int {
+	// Indicate activity
+	am.Message(".")
+
+	// Get the names of all tables
+	var tableNames []*string
+	err := ddbs.ListTables(&dynamodb.ListTablesInput{}, func(page *dynamodb.ListTablesOutput, lastPage bool) bool {
+		tableNames = append(tableNames, page.TableNames...)
+
+		return true
+	})
+
+	// Check for error
+	if am.CheckError(err) {
+		return 0
+	}
+
+	// If we are counting every replica, then there is nothing more to do
+	if countReplicas {
+		return len(tableNames)
+	}
+
+	// Get the names of the legacy global tables
+	legacyGlobalTables := dynamoDBLegacyGlobalTables(ddbs)
+
+	// Loop through the tables...
+	tableCount := 0
+	for _, tableName := range tableNames {
+		// Is this a replica of a legacy global table?
+		if legacyGlobalTables[*tableName] {
+			globalTables[*tableName] = true
+			continue
+		}
+
+		// Inspect the table
+		output, err := ddbs.InspectTable(&dynamodb.DescribeTableInput{
+			TableName: tableName,
+		})
+		if am.CheckError(err) {
+			break
+		}
+
+		// Is this a replica of a global table?
+		if output.Table != nil && len(output.Table.Replicas) > 0 {
+			globalTables[*tableName] = true
+		} else {
+			tableCount++
+		}
+	}
+
+	return tableCount
+}
+
+// Get the names of the legacy (version 2017.11.29) global tables. Legacy global
+// tables are only supported in some regions (and listing them needs its own
+// permission), so if we cannot list them, we rely on the replicas listed by each
+// table instead.
+func dynamoDBLegacyGlobalTables(ddbs *DynamoDBService) map[string]bool {
+	legacyGlobalTables := make(map[string]bool)
+	input := &dynamodb.ListGlobalTablesInput{}
+	for {
+		// Get a page of global tables
+		output, err := ddbs.ListGlobalTables(input)
+		if err != nil {
+			return legacyGlobalTables
+		}
+		for _, globalTable := range output.GlobalTables {
+			legacyGlobalTables[aws.StringValue(globalTable.GlobalTableName)] = true
+		}
+
+		// Is there another page?
+		if output.LastEvaluatedGlobalTableName == nil {
+			return legacyGlobalTables
+		}
+		input.ExclusiveStartGlobalTableName = output.LastEvaluatedGlobalTableName
+	}
+}
@@ -0,0 +1,317 @@
+/******************************************************************************
+Cloud Resource Counter
+File: dynamodb_test.go
+
+Summary: The Unit Test for dynamodb.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake DynamoDB Table Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// The regions into which our global table ("orders") is replicated
+var ordersReplicaRegions = []string{"us-east-1", "us-east-2"}
+
+// Our legacy global table ("carts") is replicated into the same regions, but its
+// tables do not list their replicas
+var cartsGlobalTable = &dynamodb.GlobalTable{
+	GlobalTableName: aws.String("carts"),
+}
+
+// DynamoDBRegionInfo describes the fake tables in a region. ListTables returns the
+// pages in ListOutputs; each table is described in Tables (by name). ListGlobalTables
+// returns the pages of legacy global tables in GlobalTableOutputs (or an error if
+// there are none).
+type DynamoDBRegionInfo struct {
+	ListOutputs        []*dynamodb.ListTablesOutput
+	Tables             map[string]*dynamodb.TableDescription
+	GlobalTableOutputs []*dynamodb.ListGlobalTablesOutput
+}
+
+// Helper function to construct a table description with replicas in the supplied regions
+func tableWithReplicas(tableName string, regionNames ...string) *dynamodb.TableDescription {
+	table := &dynamodb.TableDescription{
+		TableName: aws.String(tableName),
+	}
+	for _, regionName := range regionNames {
+		table.Replicas = append(table.Replicas, &dynamodb.ReplicaDescription{
+			RegionName: aws.String(regionName),
+		})
+	}
+
+	return table
+}
+
+// This is our map of regions and the tables in each
+var dynamoDBTablesPerRegion = map[string]*DynamoDBRegionInfo{
+	// US-EAST-1 illustrates a case where ListTablesPages returns two pages of results.
+	// First page: "users" and "orders" (a global table)
+	// Second page: "sessions" and "carts" (a legacy global table)
+	// ListGlobalTables also returns two pages: "carts" and "wishlists" (a legacy global
+	// table with no replica in this region)
+	"us-east-1": &DynamoDBRegionInfo{
+		ListOutputs: []*dynamodb.ListTablesOutput{
+			&dynamodb.ListTablesOutput{
+				TableNames: aws.StringSlice([]string{"users", "orders"}),
+			},
+			&dynamodb.ListTablesOutput{
+				TableNames: aws.StringSlice([]string{"sessions", "carts"}),
+			},
+		},
+		Tables: map[string]*dynamodb.TableDescription{
+			"users":    tableWithReplicas("users"),
+			"orders":   tableWithReplicas("orders", ordersReplicaRegions...),
+			"sessions": tableWithReplicas("sessions"),
+			"carts":    tableWithReplicas("carts"),
+		},
+		GlobalTableOutputs: []*dynamodb.ListGlobalTablesOutput{
+			&dynamodb.ListGlobalTablesOutput{
+				GlobalTables:                 []*dynamodb.GlobalTable{cartsGlobalTable},
+				LastEvaluatedGlobalTableName: aws.String("carts"),
+			},
+			&dynamodb.ListGlobalTablesOutput{
+				GlobalTables: []*dynamodb.GlobalTable{
+					&dynamodb.GlobalTable{
+						GlobalTableName: aws.String("wishlists"),
+					},
+				},
+			},
+		},
+	},
+	// US-EAST-2 has 3 tables: "orders" and "carts" (the other replicas of the global
+	// tables) and "audit"
+	"us-east-2": &DynamoDBRegionInfo{
+		ListOutputs: []*dynamodb.ListTablesOutput{
+			&dynamodb.ListTablesOutput{
+				TableNames: aws.StringSlice([]string{"orders", "audit", "carts"}),
+			},
+		},
+		Tables: map[string]*dynamodb.TableDescription{
+			"orders": tableWithReplicas("orders", ordersReplicaRegions...),
+			"audit":  tableWithReplicas("audit"),
+			"carts":  tableWithReplicas("carts"),
+		},
+		GlobalTableOutputs: []*dynamodb.ListGlobalTablesOutput{
+			&dynamodb.ListGlobalTablesOutput{
+				GlobalTables: []*dynamodb.GlobalTable{cartsGlobalTable},
+			},
+		},
+	},
+	// AF-SOUTH-1 has no tables (and does not support legacy global tables)
+	"af-south-1": &DynamoDBRegionInfo{
+		ListOutputs: []*dynamodb.ListTablesOutput{
+			&dynamodb.ListTablesOutput{},
+		},
+	},
+	// AF-SOUTH-2 has a table that cannot be described
+	"af-south-2": &DynamoDBRegionInfo{
+		ListOutputs: []*dynamodb.ListTablesOutput{
+			&dynamodb.ListTablesOutput{
+				TableNames: aws.StringSlice([]string{"forbidden"}),
+			},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake DynamoDB Service
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// To use this struct, the caller must supply a DynamoDBRegionInfo. If it is
+// missing, it will trigger the mock functions to simulate an error.
+type fakeDynamoDBService struct {
+	dynamodbiface.DynamoDBAPI
+	RegionInfo *DynamoDBRegionInfo
+}
+
+// Simulate the ListTablesPages function
+func (fake *fakeDynamoDBService) ListTablesPages(input *dynamodb.ListTablesInput, fn func(*dynamodb.ListTablesOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil {
+		return errors.New("ListTablesPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.ListOutputs {
+		// Are we looking at the last "page" of our output?
+		lastPage := index == len(fake.RegionInfo.ListOutputs)-1
+
+		// Invoke our fn
+		if !fn(output, lastPage) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Simulate the DescribeTable function
+func (fake *fakeDynamoDBService) DescribeTable(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	// Find the table
+	table, ok := fake.RegionInfo.Tables[aws.StringValue(input.TableName)]
+	if !ok {
+		return nil, fmt.Errorf("DescribeTable could not find table: %s", aws.StringValue(input.TableName))
+	}
+
+	return &dynamodb.DescribeTableOutput{
+		Table: table,
+	}, nil
+}
+
+// Simulate the ListGlobalTables function
+func (fake *fakeDynamoDBService) ListGlobalTables(input *dynamodb.ListGlobalTablesInput) (*dynamodb.ListGlobalTablesOutput, error) {
+	// If there are no legacy global tables, then simulate an error
+	if len(fake.RegionInfo.GlobalTableOutputs) == 0 {
+		return nil, errors.New("ListGlobalTables is not supported in this region")
+	}
+
+	// Find the page that follows the supplied table name
+	for _, output := range fake.RegionInfo.GlobalTableOutputs {
+		if input.ExclusiveStartGlobalTableName == nil {
+			return output, nil
+		}
+		if aws.StringValue(output.LastEvaluatedGlobalTableName) == aws.StringValue(input.ExclusiveStartGlobalTableName) {
+			input = &dynamodb.ListGlobalTablesInput{}
+		}
+	}
+
+	return nil, fmt.Errorf("ListGlobalTables could not find page after: %s", aws.StringValue(input.ExclusiveStartGlobalTableName))
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeDynamoDBServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Return our current region
+func (fsf fakeDynamoDBServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeDynamoDBServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return a specialized DynamoDBService that returns pre-canned responses
+func (fsf fakeDynamoDBServiceFactory) GetDynamoDBService(regionName string) *DynamoDBService {
+	// If the caller failed to specify a region, then use what is associated with our factory
+	var resolvedRegionName string
+	if regionName == "" {
+		resolvedRegionName = fsf.RegionName
+	} else {
+		resolvedRegionName = regionName
+	}
+
+	return &DynamoDBService{
+		Client: &fakeDynamoDBService{
+			RegionInfo: dynamoDBTablesPerRegion[resolvedRegionName],
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for DynamoDBTables
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestDynamoDBTables(t *testing.T) {
+	// Describe all of our test cases: 3 failures and 8 success cases
+	cases := []struct {
+		RegionName    string
+		AllRegions    bool
+		CountReplicas bool
+		ExpectedCount int
+		ExpectError   bool
+	}{
+		{
+			RegionName:    "us-east-1",
+			ExpectedCount: 4,
+		}, {
+			RegionName:    "us-east-2",
+			ExpectedCount: 3,
+		}, {
+			RegionName:    "af-south-1",
+			ExpectedCount: 0,
+		}, {
+			RegionName:  "af-south-2",
+			ExpectError: true,
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions:    true,
+			ExpectedCount: 5,
+		}, {
+			RegionName:    "us-east-1",
+			CountReplicas: true,
+			ExpectedCount: 4,
+		}, {
+			RegionName:    "af-south-2",
+			CountReplicas: true,
+			ExpectedCount: 1,
+		}, {
+			RegionName:    "undefined-region",
+			CountReplicas: true,
+			ExpectError:   true,
+		}, {
+			AllRegions:    true,
+			CountReplicas: true,
+			ExpectedCount: 7,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeDynamoDBServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our DynamoDB Tables function
+		actualCount := DynamoDBTables(sf, mon, c.AllRegions, c.CountReplicas)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCount != c.ExpectedCount {
+			t.Errorf("Error: DynamoDBTables returned %d; expected %d", actualCount, c.ExpectedCount)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
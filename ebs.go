@@ -2,24 +2,70 @@
 Cloud Resource Counter
 File: ebs.go
 
-Summary: Count the number of EBS Volumes
+Summary: Count the number of EBS Volumes (and, optionally, their sizes and the
+         number of EBS snapshots)
 ******************************************************************************/
 
 package main
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	color "github.com/logrusorgru/aurora"
 )
 
+// EBSVolumeTypes lists the volume types that we report the attached storage of, in
+// the order in which they are reported.
+var EBSVolumeTypes = []string{
+	ec2.VolumeTypeGp2,
+	ec2.VolumeTypeGp3,
+	ec2.VolumeTypeIo1,
+	ec2.VolumeTypeIo2,
+	ec2.VolumeTypeSt1,
+	ec2.VolumeTypeSc1,
+	ec2.VolumeTypeStandard,
+}
+
+// EBSCounts holds the number of attached EBS volumes. The remaining fields are
+// only filled in when details are requested: the size (in GiB) of the attached
+// volumes (in total and by volume type), the number and size of the unattached
+// volumes and the number of snapshots owned by this account.
+type EBSCounts struct {
+	Volumes           int
+	AttachedGiB       int
+	VolumeTypeGiB     map[string]int
+	UnattachedVolumes int
+	UnattachedGiB     int
+	Snapshots         int
+}
+
+// Add the supplied counts into our struct.
+func (ec *EBSCounts) Add(other EBSCounts) {
+	ec.Volumes += other.Volumes
+	ec.AttachedGiB += other.AttachedGiB
+	ec.UnattachedVolumes += other.UnattachedVolumes
+	ec.UnattachedGiB += other.UnattachedGiB
+	ec.Snapshots += other.Snapshots
+
+	// Merge the volume type sizes
+	for volumeType, size := range other.VolumeTypeGiB {
+		if ec.VolumeTypeGiB == nil {
+			ec.VolumeTypeGiB = make(map[string]int)
+		}
+		ec.VolumeTypeGiB[volumeType] += size
+	}
+}
+
 // EBSVolumes returns a count of all EBS volumes in the current region (if allRegions
 // is false) or in all regions associated with this account (if allRegions is true).
-func EBSVolumes(sf ServiceFactory, am ActivityMonitor, allRegions bool) int {
+// If detail is true, then the sizes of the volumes and the number of snapshots are
+// also retrieved.
+func EBSVolumes(sf ServiceFactory, am ActivityMonitor, allRegions bool, detail bool) EBSCounts {
 	// Indicate activity
 	am.StartAction("Retrieving EBS volume counts")
 
 	// Should we get the counts for all regions?
-	instanceCount := 0
+	var ebsCounts EBSCounts
 	if allRegions {
 		// Get the list of all enabled regions for this account
 		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
@@ -27,20 +73,25 @@ func EBSVolumes(sf ServiceFactory, am ActivityMonitor, allRegions bool) int {
 		// Loop through all of the regions
 		for _, regionName := range regionsSlice {
 			// Get the EBS Volume counts for a specific region
-			instanceCount += ebsVolumesForSingleRegion(sf.GetEC2InstanceService(regionName), am)
+			ebsCounts.Add(ebsVolumesForSingleRegion(sf.GetEC2InstanceService(regionName), am, detail))
 		}
 	} else {
 		// Get the EBS Volume counts for the region selected by this session
-		instanceCount = ebsVolumesForSingleRegion(sf.GetEC2InstanceService(""), am)
+		ebsCounts = ebsVolumesForSingleRegion(sf.GetEC2InstanceService(""), am, detail)
 	}
 
 	// Indicate end of activity
-	am.EndAction("OK (%d)", color.Bold(instanceCount))
+	if detail {
+		am.EndAction("OK (%d, %d GiB, %d unattached, %d snapshots)", color.Bold(ebsCounts.Volumes),
+			color.Bold(ebsCounts.AttachedGiB), color.Bold(ebsCounts.UnattachedVolumes), color.Bold(ebsCounts.Snapshots))
+	} else {
+		am.EndAction("OK (%d)", color.Bold(ebsCounts.Volumes))
+	}
 
-	return instanceCount
+	return ebsCounts
 }
 
-func ebsVolumesForSingleRegion(ec2is *EC2InstanceService, am ActivityMonitor) int {
+func ebsVolumesForSingleRegion(ec2is *EC2InstanceService, am ActivityMonitor, detail bool) EBSCounts {
 	// Indicate activity
 	am.Message(".")
 
@@ -48,21 +99,45 @@ func ebsVolumesForSingleRegion(ec2is *EC2InstanceService, am ActivityMonitor) in
 	input := &ec2.DescribeVolumesInput{}
 
 	// Invoke our service
-	instanceCount := 0
+	ebsCounts := EBSCounts{
+		VolumeTypeGiB: make(map[string]int),
+	}
 	err := ec2is.InspectVolumes(input, func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
 		// Loop through each Volume
 		for _, volume := range page.Volumes {
+			size := int(aws.Int64Value(volume.Size))
+
 			// Do we have a non-nil, non-empty Attachments array?
 			if volume.Attachments != nil && len(volume.Attachments) > 0 {
-				instanceCount++
+				ebsCounts.Volumes++
+				ebsCounts.AttachedGiB += size
+				ebsCounts.VolumeTypeGiB[aws.StringValue(volume.VolumeType)] += size
+			} else {
+				ebsCounts.UnattachedVolumes++
+				ebsCounts.UnattachedGiB += size
 			}
 		}
 
 		return true
 	})
 
+	// Check for error
+	if am.CheckError(err) || !detail {
+		return ebsCounts
+	}
+
+	// Count the snapshots owned by this account. (Without an owner, the public
+	// snapshots of all accounts would be counted as well.)
+	err = ec2is.InspectSnapshots(&ec2.DescribeSnapshotsInput{
+		OwnerIds: []*string{aws.String("self")},
+	}, func(page *ec2.DescribeSnapshotsOutput, lastPage bool) bool {
+		ebsCounts.Snapshots += len(page.Snapshots)
+
+		return true
+	})
+
 	// Check for error
 	am.CheckError(err)
 
-	return instanceCount
+	return ebsCounts
 }
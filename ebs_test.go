@@ -9,6 +9,7 @@ package main
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -23,11 +24,14 @@ import (
 // This is our map of regions and the instances in each
 var ebsVolumesPerRegion = map[string][]*ec2.DescribeVolumesOutput{
 	// US-EAST-1 illustrates a case where DescribeVolumesPages returns 1 page
-	// of results: 3 volumes, but only 2 are attached.
+	// of results: 3 volumes, but only 2 are attached (108 GiB). The unattached
+	// volume is 50 GiB.
 	"us-east-1": []*ec2.DescribeVolumesOutput{
 		&ec2.DescribeVolumesOutput{
 			Volumes: []*ec2.Volume{
 				{
+					Size:       aws.Int64(100),
+					VolumeType: aws.String("gp3"),
 					Attachments: []*ec2.VolumeAttachment{
 						{
 							InstanceId: aws.String("some-instance-id"),
@@ -35,9 +39,13 @@ var ebsVolumesPerRegion = map[string][]*ec2.DescribeVolumesOutput{
 					},
 				},
 				{
+					Size:        aws.Int64(50),
+					VolumeType:  aws.String("gp2"),
 					Attachments: []*ec2.VolumeAttachment{},
 				},
 				{
+					Size:       aws.Int64(8),
+					VolumeType: aws.String("gp2"),
 					Attachments: []*ec2.VolumeAttachment{
 						{
 							InstanceId: aws.String("yet-another-instance-id"),
@@ -59,11 +67,14 @@ var ebsVolumesPerRegion = map[string][]*ec2.DescribeVolumesOutput{
 	// AF-SOUTH-1 is an "opted in" region (Cape Town, Africa). We are going to
 	// simulate the case when DescribeVolumesPages returns three pages of
 	// results. First page has 3 (all attached), second page has 3 (2 attached)
-	// and the third page has 1 (attached).
+	// and the third page has 1 (attached). The attached volumes total 1865 GiB;
+	// the unattached volume is 30 GiB.
 	"af-south-1": []*ec2.DescribeVolumesOutput{
 		&ec2.DescribeVolumesOutput{
 			Volumes: []*ec2.Volume{
 				{
+					Size:       aws.Int64(20),
+					VolumeType: aws.String("gp3"),
 					Attachments: []*ec2.VolumeAttachment{
 						{
 							InstanceId: aws.String("some-instance-id"),
@@ -71,6 +82,8 @@ var ebsVolumesPerRegion = map[string][]*ec2.DescribeVolumesOutput{
 					},
 				},
 				{
+					Size:       aws.Int64(20),
+					VolumeType: aws.String("gp3"),
 					Attachments: []*ec2.VolumeAttachment{
 						{
 							InstanceId: aws.String("another-instance-id"),
@@ -78,6 +91,8 @@ var ebsVolumesPerRegion = map[string][]*ec2.DescribeVolumesOutput{
 					},
 				},
 				{
+					Size:       aws.Int64(500),
+					VolumeType: aws.String("io1"),
 					Attachments: []*ec2.VolumeAttachment{
 						{
 							InstanceId: aws.String("yet-another-instance-id"),
@@ -89,6 +104,8 @@ var ebsVolumesPerRegion = map[string][]*ec2.DescribeVolumesOutput{
 		&ec2.DescribeVolumesOutput{
 			Volumes: []*ec2.Volume{
 				{
+					Size:       aws.Int64(1000),
+					VolumeType: aws.String("st1"),
 					Attachments: []*ec2.VolumeAttachment{
 						{
 							InstanceId: aws.String("and-another-instance-id"),
@@ -96,9 +113,13 @@ var ebsVolumesPerRegion = map[string][]*ec2.DescribeVolumesOutput{
 					},
 				},
 				{
+					Size:        aws.Int64(30),
+					VolumeType:  aws.String("gp2"),
 					Attachments: []*ec2.VolumeAttachment{},
 				},
 				{
+					Size:       aws.Int64(200),
+					VolumeType: aws.String("io2"),
 					Attachments: []*ec2.VolumeAttachment{
 						{
 							InstanceId: aws.String("more-instance-id"),
@@ -110,6 +131,8 @@ var ebsVolumesPerRegion = map[string][]*ec2.DescribeVolumesOutput{
 		&ec2.DescribeVolumesOutput{
 			Volumes: []*ec2.Volume{
 				{
+					Size:       aws.Int64(125),
+					VolumeType: aws.String("sc1"),
 					Attachments: []*ec2.VolumeAttachment{
 						{
 							InstanceId: aws.String("final-instance-id"),
@@ -119,6 +142,69 @@ var ebsVolumesPerRegion = map[string][]*ec2.DescribeVolumesOutput{
 			},
 		},
 	},
+
+	// EU-WEST-1 has a single attached volume (but no snapshot data, so asking for
+	// snapshots simulates an error).
+	"eu-west-1": []*ec2.DescribeVolumesOutput{
+		&ec2.DescribeVolumesOutput{
+			Volumes: []*ec2.Volume{
+				{
+					Size:       aws.Int64(10),
+					VolumeType: aws.String("gp3"),
+					Attachments: []*ec2.VolumeAttachment{
+						{
+							InstanceId: aws.String("lonely-instance-id"),
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// This is our map of regions and the snapshots (owned by this account) in each
+var ebsSnapshotsPerRegion = map[string][]*ec2.DescribeSnapshotsOutput{
+	// US-EAST-1 has 1 page of results: 2 snapshots
+	"us-east-1": []*ec2.DescribeSnapshotsOutput{
+		&ec2.DescribeSnapshotsOutput{
+			Snapshots: []*ec2.Snapshot{
+				{
+					SnapshotId: aws.String("snap-1"),
+				},
+				{
+					SnapshotId: aws.String("snap-2"),
+				},
+			},
+		},
+	},
+
+	// US-EAST-2 has no snapshots
+	"us-east-2": []*ec2.DescribeSnapshotsOutput{
+		&ec2.DescribeSnapshotsOutput{
+			Snapshots: []*ec2.Snapshot{},
+		},
+	},
+
+	// AF-SOUTH-1 has 2 pages of results: 3 snapshots
+	"af-south-1": []*ec2.DescribeSnapshotsOutput{
+		&ec2.DescribeSnapshotsOutput{
+			Snapshots: []*ec2.Snapshot{
+				{
+					SnapshotId: aws.String("snap-3"),
+				},
+				{
+					SnapshotId: aws.String("snap-4"),
+				},
+			},
+		},
+		&ec2.DescribeSnapshotsOutput{
+			Snapshots: []*ec2.Snapshot{
+				{
+					SnapshotId: aws.String("snap-5"),
+				},
+			},
+		},
+	},
 }
 
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -131,6 +217,7 @@ var ebsVolumesPerRegion = map[string][]*ec2.DescribeVolumesOutput{
 type fakeEBSService struct {
 	ec2iface.EC2API
 	DVOResponse []*ec2.DescribeVolumesOutput
+	DSOResponse []*ec2.DescribeSnapshotsOutput
 	DRResponse  *ec2.DescribeRegionsOutput
 }
 
@@ -177,6 +264,32 @@ func (fake *fakeEBSService) DescribeVolumesPages(input *ec2.DescribeVolumesInput
 	return nil
 }
 
+// Simulate the DescribeSnapshotsPages function
+func (fake *fakeEBSService) DescribeSnapshotsPages(input *ec2.DescribeSnapshotsInput,
+	fn func(*ec2.DescribeSnapshotsOutput, bool) bool) error {
+	// If the supplied response is nil, then simulate an error
+	if fake.DSOResponse == nil {
+		return errors.New("DescribeSnapshots encountered an unexpected error: 4321")
+	}
+
+	// Our code only asks for the snapshots owned by this account. To prevent
+	// unexpected cases, any other input fails the unit test.
+	if len(input.OwnerIds) != 1 || aws.StringValue(input.OwnerIds[0]) != "self" ||
+		input.Filters != nil || input.RestorableByUserIds != nil || input.SnapshotIds != nil {
+		return errors.New("The unit test only supports a DescribeSnapshotsInput with OwnerIds of 'self'")
+	}
+
+	// Loop through the slice, invoking the supplied function
+	for index, output := range fake.DSOResponse {
+		// Invoke our fn (are we looking at the last "page" of our output?)
+		if !fn(output, index == len(fake.DSOResponse)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 // Fake Service Factory
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -184,23 +297,16 @@ func (fake *fakeEBSService) DescribeVolumesPages(input *ec2.DescribeVolumesInput
 // This structure simulates the AWS Service Factory by storing some pregenerated
 // responses (that would come from AWS)
 type fakeEBSServiceFactory struct {
+	baseFakeServiceFactory
 	RegionName string
 	DRResponse *ec2.DescribeRegionsOutput
 }
 
-// Don't need to implement
-func (fsf fakeEBSServiceFactory) Init() {}
-
 // Return our current region
 func (fsf fakeEBSServiceFactory) GetCurrentRegion() string {
 	return fsf.RegionName
 }
 
-// Don't need to implement
-func (fsf fakeEBSServiceFactory) GetAccountIDService() *AccountIDService {
-	return nil
-}
-
 // Basic implementation
 func (fsf fakeEBSServiceFactory) GetEC2InstanceService(regionName string) *EC2InstanceService {
 	// If the caller failed to specify a region, then use what is associated with our factory
@@ -214,42 +320,18 @@ func (fsf fakeEBSServiceFactory) GetEC2InstanceService(regionName string) *EC2In
 	return &EC2InstanceService{
 		Client: &fakeEBSService{
 			DVOResponse: ebsVolumesPerRegion[resolvedRegionName],
+			DSOResponse: ebsSnapshotsPerRegion[resolvedRegionName],
 			DRResponse:  fsf.DRResponse,
 		},
 	}
 }
 
-// Don't need to implement
-func (fsf fakeEBSServiceFactory) GetRDSInstanceService(string) *RDSInstanceService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeEBSServiceFactory) GetS3Service() *S3Service {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeEBSServiceFactory) GetLambdaService(string) *LambdaService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeEBSServiceFactory) GetContainerService(string) *ContainerService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeEBSServiceFactory) GetLightsailService(string) *LightsailService {
-	return nil
-}
-
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 // Unit Test for EBSVolumes
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 
 func TestEBSVolumes(t *testing.T) {
-	// Describe all of our test cases: 1 failure and 4 success cases
+	// Describe all of our test cases: 1 failure and 5 success cases
 	cases := []struct {
 		RegionName    string
 		AllRegions    bool
@@ -265,6 +347,9 @@ func TestEBSVolumes(t *testing.T) {
 		}, {
 			RegionName:    "af-south-1",
 			ExpectedCount: 6,
+		}, {
+			RegionName:    "eu-west-1",
+			ExpectedCount: 1,
 		}, {
 			RegionName:  "undefined-region",
 			ExpectError: true,
@@ -286,7 +371,7 @@ func TestEBSVolumes(t *testing.T) {
 		mon := &mock.ActivityMonitorImpl{}
 
 		// Invoke our EBSVolumes function
-		actualCount := EBSVolumes(sf, mon, c.AllRegions)
+		actualCount := EBSVolumes(sf, mon, c.AllRegions, false).Volumes
 
 		// Did we expect an error?
 		if c.ExpectError {
@@ -303,3 +388,105 @@ func TestEBSVolumes(t *testing.T) {
 		}
 	}
 }
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for EBSVolumes (with detail)
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestEBSVolumesDetail(t *testing.T) {
+	// Describe all of our test cases: 2 failures and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts EBSCounts
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: EBSCounts{
+				Volumes:     2,
+				AttachedGiB: 108,
+				VolumeTypeGiB: map[string]int{
+					"gp2": 8,
+					"gp3": 100,
+				},
+				UnattachedVolumes: 1,
+				UnattachedGiB:     50,
+				Snapshots:         2,
+			},
+		}, {
+			RegionName: "us-east-2",
+			ExpectedCounts: EBSCounts{
+				VolumeTypeGiB: map[string]int{},
+			},
+		}, {
+			RegionName: "af-south-1",
+			ExpectedCounts: EBSCounts{
+				Volumes:     6,
+				AttachedGiB: 1865,
+				VolumeTypeGiB: map[string]int{
+					"gp3": 40,
+					"io1": 500,
+					"io2": 200,
+					"st1": 1000,
+					"sc1": 125,
+				},
+				UnattachedVolumes: 1,
+				UnattachedGiB:     30,
+				Snapshots:         3,
+			},
+		}, {
+			AllRegions: true,
+			ExpectedCounts: EBSCounts{
+				Volumes:     8,
+				AttachedGiB: 1973,
+				VolumeTypeGiB: map[string]int{
+					"gp2": 8,
+					"gp3": 140,
+					"io1": 500,
+					"io2": 200,
+					"st1": 1000,
+					"sc1": 125,
+				},
+				UnattachedVolumes: 2,
+				UnattachedGiB:     80,
+				Snapshots:         5,
+			},
+		}, {
+			RegionName:  "eu-west-1",
+			ExpectError: true,
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeEBSServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our EBSVolumes function
+		actualCounts := EBSVolumes(sf, mon, c.AllRegions, true)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if !reflect.DeepEqual(actualCounts, c.ExpectedCounts) {
+			t.Errorf("Error: EBSVolumes returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
@@ -2,7 +2,8 @@
 Cloud Resource Counter
 File: ec2.go
 
-Summary: Provides a count of all (non-spot) EC2 instances.
+Summary: Provides a count of all running EC2 instances, both by instance
+         lifecycle and by what (if anything) is orchestrating them.
 ******************************************************************************/
 
 package main
@@ -14,16 +15,30 @@ import (
 	color "github.com/logrusorgru/aurora"
 )
 
-// EC2Counts retrieves the count of all EC2 instances either for all
-// regions (allRegions is true) or the region associated with the
-// session. This method gives status back to the user via the supplied
-// ActivityMonitor instance.
-func EC2Counts(sf ServiceFactory, am ActivityMonitor, allRegions bool) int {
+// EC2InstanceCounts holds the number of running EC2 instances by lifecycle and by
+// node classification. Both are gathered from the same pass over the instances.
+type EC2InstanceCounts struct {
+	Lifecycles EC2LifecycleCounts
+	Nodes      EC2NodeCounts
+}
+
+// Add the supplied counts into our struct.
+func (eic *EC2InstanceCounts) Add(other EC2InstanceCounts) {
+	eic.Lifecycles.Add(other.Lifecycles)
+	eic.Nodes.Add(other.Nodes)
+}
+
+// EC2Instances retrieves the count of all running EC2 instances, by lifecycle and
+// by node classification, either for all regions (allRegions is true) or the region
+// associated with the session. The instances of each region are described just
+// once. This method gives status back to the user via the supplied ActivityMonitor
+// instance.
+func EC2Instances(sf ServiceFactory, am ActivityMonitor, allRegions bool) EC2InstanceCounts {
 	// Indicate activity
 	am.StartAction("Retrieving EC2 counts")
 
 	// Should we get the counts for all regions?
-	instanceCount := 0
+	var instanceCounts EC2InstanceCounts
 	if allRegions {
 		// Get the list of all enabled regions for this account
 		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
@@ -31,25 +46,35 @@ func EC2Counts(sf ServiceFactory, am ActivityMonitor, allRegions bool) int {
 		// Loop through all of the regions
 		for _, regionName := range regionsSlice {
 			// Get the EC2 counts for a specific region
-			instanceCount += ec2CountForSingleRegion(sf.GetEC2InstanceService(regionName), am)
+			instanceCounts.Add(ec2InstancesForSingleRegion(sf.GetEC2InstanceService(regionName), sf.GetContainerService(regionName), am))
 		}
 	} else {
 		// Get the EC2 counts for the region selected by this session
-		instanceCount = ec2CountForSingleRegion(sf.GetEC2InstanceService(""), am)
+		instanceCounts = ec2InstancesForSingleRegion(sf.GetEC2InstanceService(""), sf.GetContainerService(""), am)
 	}
 
 	// Indicate end of activity
-	am.EndAction("OK (%d)", color.Bold(instanceCount))
+	lifecycleCounts, nodeCounts := instanceCounts.Lifecycles, instanceCounts.Nodes
+	am.EndAction("OK (%d on-demand, %d spot, %d scheduled, %d capacity block; %d standalone, %d orchestrated)",
+		color.Bold(lifecycleCounts.OnDemand), color.Bold(lifecycleCounts.Spot),
+		color.Bold(lifecycleCounts.Scheduled), color.Bold(lifecycleCounts.CapacityBlock),
+		color.Bold(nodeCounts.Standalone), color.Bold(nodeCounts.Orchestrated()))
 
-	return instanceCount
+	return instanceCounts
 }
 
-// Get the EC2 Instance count for a single region
-func ec2CountForSingleRegion(ec2is *EC2InstanceService, am ActivityMonitor) int {
+// Get the EC2 Instance counts for a single region
+func ec2InstancesForSingleRegion(ec2is *EC2InstanceService, cs *ContainerService, am ActivityMonitor) EC2InstanceCounts {
 	// Indicate activity
 	am.Message(".")
 
-	// Construct our input to find only RUNNING EC2 instances
+	// Get the set of EC2 instances registered as ECS container instances
+	ecsInstanceIDs, ok := ecsContainerInstanceIDsForSingleRegion(cs, am)
+	if !ok {
+		return EC2InstanceCounts{}
+	}
+
+	// Construct our input to find only RUNNING EC2 instances (of any lifecycle)
 	input := &ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{
 			{
@@ -62,16 +87,13 @@ func ec2CountForSingleRegion(ec2is *EC2InstanceService, am ActivityMonitor) int
 	}
 
 	// Invoke our service
-	instanceCount := 0
+	var instanceCounts EC2InstanceCounts
 	err := ec2is.InspectInstances(input, func(dio *ec2.DescribeInstancesOutput, lastPage bool) bool {
 		// Loop through each reservation, instance
 		for _, reservation := range dio.Reservations {
 			for _, instance := range reservation.Instances {
-				// Is this a valid instance? Spot instances have an InstanceLifecycle of "spot".
-				// Similarly, Scheduled instances have an InstanceLifecycle of "scheduled".
-				if instance.InstanceLifecycle == nil {
-					instanceCount++
-				}
+				instanceCounts.Lifecycles.AddInstance(instance)
+				instanceCounts.Nodes.AddInstance(instance, ecsInstanceIDs)
 			}
 		}
 
@@ -81,5 +103,5 @@ func ec2CountForSingleRegion(ec2is *EC2InstanceService, am ActivityMonitor) int
 	// Check for error
 	am.CheckError(err)
 
-	return instanceCount
+	return instanceCounts
 }
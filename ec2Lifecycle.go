@@ -0,0 +1,61 @@
+/******************************************************************************
+Cloud Resource Counter
+File: ec2Lifecycle.go
+
+Summary: Breaks down running EC2 instances by their instance lifecycle
+         (on-demand, spot, scheduled and capacity-block).
+******************************************************************************/
+
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// Instance lifecycle of instances launched into an EC2 Capacity Block. (This is
+// not yet an enum value in our version of the AWS SDK.)
+const instanceLifecycleCapacityBlock = "capacity-block"
+
+// EC2LifecycleCounts holds the number of running EC2 instances for each instance
+// lifecycle. On-demand instances are those without an InstanceLifecycle. Any
+// lifecycle that we do not (yet) recognize is counted as Other so that every
+// running instance is counted exactly once.
+type EC2LifecycleCounts struct {
+	OnDemand      int
+	Spot          int
+	Scheduled     int
+	CapacityBlock int
+	Other         int
+}
+
+// Total returns the number of running instances across all lifecycles.
+func (elc EC2LifecycleCounts) Total() int {
+	return elc.OnDemand + elc.Spot + elc.Scheduled + elc.CapacityBlock + elc.Other
+}
+
+// Add the supplied counts into our struct.
+func (elc *EC2LifecycleCounts) Add(other EC2LifecycleCounts) {
+	elc.OnDemand += other.OnDemand
+	elc.Spot += other.Spot
+	elc.Scheduled += other.Scheduled
+	elc.CapacityBlock += other.CapacityBlock
+	elc.Other += other.Other
+}
+
+// AddInstance counts the supplied (running) instance under its lifecycle.
+func (elc *EC2LifecycleCounts) AddInstance(instance *ec2.Instance) {
+	// Switch on the instance lifecycle
+	switch aws.StringValue(instance.InstanceLifecycle) {
+	case "":
+		elc.OnDemand++
+	case ec2.InstanceLifecycleTypeSpot:
+		elc.Spot++
+	case ec2.InstanceLifecycleTypeScheduled:
+		elc.Scheduled++
+	case instanceLifecycleCapacityBlock:
+		elc.CapacityBlock++
+	default:
+		elc.Other++
+	}
+}
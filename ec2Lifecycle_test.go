@@ -0,0 +1,244 @@
+/******************************************************************************
+Cloud Resource Counter
+File: ec2Lifecycle_test.go
+
+Summary: The Unit Test for ec2Lifecycle.
+******************************************************************************/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake EC2 Lifecycle Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// Helper function to construct an EC2 instance with the supplied lifecycle and state
+func instanceWithLifecycle(lifecycle string, state string) *ec2.Instance {
+	instance := &ec2.Instance{
+		State: &ec2.InstanceState{
+			Name: aws.String(state),
+		},
+	}
+	if lifecycle != "" {
+		instance.InstanceLifecycle = aws.String(lifecycle)
+	}
+
+	return instance
+}
+
+// This is our map of regions and the instances in each
+var ec2LifecyclesPerRegion = map[string][]*ec2.DescribeInstancesOutput{
+	// US-EAST-1 illustrates a case where DescribeInstancesPages returns two pages of results.
+	// First page: 2 on-demand, 1 spot and 1 scheduled instance (all running)
+	// Second page: 1 capacity block, 1 stopped scheduled instance and 1 running instance
+	// with a lifecycle that we do not recognize.
+	"us-east-1": []*ec2.DescribeInstancesOutput{
+		&ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				&ec2.Reservation{
+					Instances: []*ec2.Instance{
+						instanceWithLifecycle("", "running"),
+						instanceWithLifecycle("spot", "running"),
+					},
+				},
+				&ec2.Reservation{
+					Instances: []*ec2.Instance{
+						instanceWithLifecycle("", "running"),
+						instanceWithLifecycle("scheduled", "running"),
+					},
+				},
+			},
+		},
+		&ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				&ec2.Reservation{
+					Instances: []*ec2.Instance{
+						instanceWithLifecycle("capacity-block", "running"),
+						instanceWithLifecycle("scheduled", "stopped"),
+						instanceWithLifecycle("some-future-lifecycle", "running"),
+					},
+				},
+			},
+		},
+	},
+	// US-EAST-2 has 1 page of data: 2 scheduled and 2 capacity block instances
+	"us-east-2": []*ec2.DescribeInstancesOutput{
+		&ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				&ec2.Reservation{
+					Instances: []*ec2.Instance{
+						instanceWithLifecycle("scheduled", "running"),
+						instanceWithLifecycle("scheduled", "running"),
+						instanceWithLifecycle("capacity-block", "running"),
+						instanceWithLifecycle("capacity-block", "running"),
+						instanceWithLifecycle("", "stopped"),
+					},
+				},
+			},
+		},
+	},
+	// AF-SOUTH-1 has no instances
+	"af-south-1": []*ec2.DescribeInstancesOutput{
+		&ec2.DescribeInstancesOutput{},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeEC2LifecycleServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Return our current region
+func (fsf fakeEC2LifecycleServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// Implement a way to return EC2 Regions and instances found in each
+func (fsf fakeEC2LifecycleServiceFactory) GetEC2InstanceService(regionName string) *EC2InstanceService {
+	// If the caller failed to specify a region, then use what is associated with our factory
+	var resolvedRegionName string
+	if regionName == "" {
+		resolvedRegionName = fsf.RegionName
+	} else {
+		resolvedRegionName = regionName
+	}
+
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DIPResponse: ec2LifecyclesPerRegion[resolvedRegionName],
+			DRResponse:  fsf.DRResponse,
+		},
+	}
+}
+
+// Return a container service without any ECS clusters
+func (fsf fakeEC2LifecycleServiceFactory) GetContainerService(regionName string) *ContainerService {
+	return &ContainerService{
+		Client: &fakeContainerService{
+			ClusterInfo: &ClusterInfo{},
+		},
+	}
+}
+
+// Helper function that counts the running instances in our fake data for a region
+func runningInstancesInRegion(regionName string) int {
+	var count int
+	for _, output := range ec2LifecyclesPerRegion[regionName] {
+		for _, reservation := range output.Reservations {
+			for _, instance := range reservation.Instances {
+				if *instance.State.Name == "running" {
+					count++
+				}
+			}
+		}
+	}
+
+	return count
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for the lifecycle counts of EC2Instances
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestEC2InstancesLifecycles(t *testing.T) {
+	// Describe all of our test cases: 1 failure and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts EC2LifecycleCounts
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: EC2LifecycleCounts{
+				OnDemand:      2,
+				Spot:          1,
+				Scheduled:     1,
+				CapacityBlock: 1,
+				Other:         1,
+			},
+		}, {
+			RegionName: "us-east-2",
+			ExpectedCounts: EC2LifecycleCounts{
+				Scheduled:     2,
+				CapacityBlock: 2,
+			},
+		}, {
+			RegionName: "af-south-1",
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions: true,
+			ExpectedCounts: EC2LifecycleCounts{
+				OnDemand:      2,
+				Spot:          1,
+				Scheduled:     3,
+				CapacityBlock: 3,
+				Other:         1,
+			},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeEC2LifecycleServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our EC2 Instances function
+		actualCounts := EC2Instances(sf, mon, c.AllRegions).Lifecycles
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: EC2Instances returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
+
+func TestEC2InstancesLifecyclesCountEachInstanceOnce(t *testing.T) {
+	// Loop through each of our regions
+	for regionName := range ec2LifecyclesPerRegion {
+		// Create our fake service factory
+		sf := fakeEC2LifecycleServiceFactory{
+			RegionName: regionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Invoke our EC2 Instances function
+		actualCounts := EC2Instances(sf, &mock.ActivityMonitorImpl{}, false).Lifecycles
+
+		// Every running instance should land in exactly one lifecycle
+		if expectedTotal := runningInstancesInRegion(regionName); actualCounts.Total() != expectedTotal {
+			t.Errorf("Error: EC2Instances counted %d instances in %s; expected %d running instances", actualCounts.Total(), regionName, expectedTotal)
+		}
+	}
+}
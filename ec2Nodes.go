@@ -0,0 +1,157 @@
+/******************************************************************************
+Cloud Resource Counter
+File: ec2Nodes.go
+
+Summary: Classifies running EC2 instances (of every lifecycle) as standalone
+         instances or as nodes orchestrated by EKS, ECS or an Auto Scaling group.
+******************************************************************************/
+
+package main
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// EC2NodeCounts holds the number of running EC2 instances, broken down by what
+// (if anything) is orchestrating them. Each instance is counted exactly once: an
+// EKS worker node that is also part of an Auto Scaling group is counted as an
+// EKS node only.
+type EC2NodeCounts struct {
+	Standalone  int
+	EKSNodes    int
+	ECSNodes    int
+	AutoScaling int
+}
+
+// Orchestrated returns the number of instances that are managed by EKS, ECS or
+// an Auto Scaling group.
+func (enc EC2NodeCounts) Orchestrated() int {
+	return enc.EKSNodes + enc.ECSNodes + enc.AutoScaling
+}
+
+// Add the supplied counts into our struct.
+func (enc *EC2NodeCounts) Add(other EC2NodeCounts) {
+	enc.Standalone += other.Standalone
+	enc.EKSNodes += other.EKSNodes
+	enc.ECSNodes += other.ECSNodes
+	enc.AutoScaling += other.AutoScaling
+}
+
+// AddInstance classifies the supplied (running) instance. Every instance is
+// classified, whatever its lifecycle (spot nodes are common in EKS and ECS
+// clusters), so the sum of all classifications matches the total of the lifecycle
+// counts. The caller supplies the IDs of the instances that are registered as ECS
+// container instances.
+func (enc *EC2NodeCounts) AddInstance(instance *ec2.Instance, ecsInstanceIDs map[string]bool) {
+	// What is orchestrating this instance?
+	switch {
+	case isEKSNode(instance):
+		enc.EKSNodes++
+	case isECSNode(instance, ecsInstanceIDs):
+		enc.ECSNodes++
+	case isAutoScalingNode(instance):
+		enc.AutoScaling++
+	default:
+		enc.Standalone++
+	}
+}
+
+// Get the set of EC2 instance IDs that back an ECS container instance (in any
+// cluster) for a single region. The boolean result is false if an error occurred.
+func ecsContainerInstanceIDsForSingleRegion(cs *ContainerService, am ActivityMonitor) (map[string]bool, bool) {
+	// Get the list of clusters
+	clusterArns, ok := ecsClusterArnsForSingleRegion(cs, am)
+	if !ok {
+		return nil, false
+	}
+
+	// Loop through the clusters...
+	instanceIDs := make(map[string]bool)
+	for _, clusterArn := range clusterArns {
+		// Construct our input to find all container instances in this cluster
+		input := &ecs.ListContainerInstancesInput{
+			Cluster: clusterArn,
+		}
+
+		// Each page holds at most 100 ARNs, which is what DescribeContainerInstances accepts
+		var innerErr error
+		err := cs.ListContainerInstances(input, func(page *ecs.ListContainerInstancesOutput, lastPage bool) bool {
+			// Anything to describe?
+			if len(page.ContainerInstanceArns) == 0 {
+				return true
+			}
+
+			// Inspect the container instances
+			var output *ecs.DescribeContainerInstancesOutput
+			output, innerErr = cs.InspectContainerInstances(&ecs.DescribeContainerInstancesInput{
+				Cluster:            clusterArn,
+				ContainerInstances: page.ContainerInstanceArns,
+			})
+			if innerErr != nil {
+				// Stop iterating
+				return false
+			}
+
+			// Record the EC2 instance backing each container instance
+			for _, ci := range output.ContainerInstances {
+				if ci.Ec2InstanceId != nil {
+					instanceIDs[*ci.Ec2InstanceId] = true
+				}
+			}
+
+			return true
+		})
+
+		// Check for either error
+		if am.CheckError(err) || am.CheckError(innerErr) {
+			return nil, false
+		}
+	}
+
+	return instanceIDs, true
+}
+
+// Is the instance an EKS worker node? Managed node groups carry the
+// "eks:cluster-name" tag while self-managed nodes carry the
+// "kubernetes.io/cluster/<name>" tag.
+func isEKSNode(instance *ec2.Instance) bool {
+	for _, tag := range instance.Tags {
+		if tag.Key == nil {
+			continue
+		}
+		if *tag.Key == "eks:cluster-name" || strings.HasPrefix(*tag.Key, "kubernetes.io/cluster/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Is the instance an ECS container instance? It either has been registered with
+// an ECS cluster or it carries one of the "aws:ecs:*" tags.
+func isECSNode(instance *ec2.Instance, ecsInstanceIDs map[string]bool) bool {
+	if instance.InstanceId != nil && ecsInstanceIDs[*instance.InstanceId] {
+		return true
+	}
+	for _, tag := range instance.Tags {
+		if tag.Key != nil && strings.HasPrefix(*tag.Key, "aws:ecs:") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Is the instance a member of an Auto Scaling group?
+func isAutoScalingNode(instance *ec2.Instance) bool {
+	for _, tag := range instance.Tags {
+		if tag.Key != nil && *tag.Key == "aws:autoscaling:groupName" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,253 @@
+/******************************************************************************
+Cloud Resource Counter
+File: ec2Nodes_test.go
+
+Summary: The Unit Test for ec2Nodes.
+******************************************************************************/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake EC2 Node Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// Helper function to construct a running EC2 instance with the supplied ID and tag keys
+func runningInstanceWithTags(instanceID string, tagKeys ...string) *ec2.Instance {
+	instance := &ec2.Instance{
+		InstanceId: aws.String(instanceID),
+		State: &ec2.InstanceState{
+			Name: aws.String("running"),
+		},
+	}
+	for _, key := range tagKeys {
+		instance.Tags = append(instance.Tags, &ec2.Tag{
+			Key:   aws.String(key),
+			Value: aws.String("some-value"),
+		})
+	}
+
+	return instance
+}
+
+// This is our map of regions and the instances in each
+var ec2NodesPerRegion = map[string][]*ec2.DescribeInstancesOutput{
+	// US-EAST-1 illustrates a mix of every kind of node: 1 standalone, 2 EKS nodes (one
+	// managed, also in an Auto Scaling group, and one self-managed), 1 ECS container instance
+	// (known only through ECS), 1 plain Auto Scaling instance, a spot EKS node (which is
+	// classified like any other node) and a stopped instance (which is not counted).
+	"us-east-1": []*ec2.DescribeInstancesOutput{
+		&ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				&ec2.Reservation{
+					Instances: []*ec2.Instance{
+						runningInstanceWithTags("i-1", "Name"),
+						runningInstanceWithTags("i-2", "eks:cluster-name", "aws:autoscaling:groupName"),
+						runningInstanceWithTags("i-3"),
+					},
+				},
+			},
+		},
+		&ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				&ec2.Reservation{
+					Instances: []*ec2.Instance{
+						runningInstanceWithTags("i-4", "aws:autoscaling:groupName"),
+						&ec2.Instance{
+							InstanceId:        aws.String("i-5"),
+							InstanceLifecycle: aws.String("spot"),
+							State: &ec2.InstanceState{
+								Name: aws.String("running"),
+							},
+							Tags: []*ec2.Tag{
+								{
+									Key:   aws.String("eks:cluster-name"),
+									Value: aws.String("my-cluster"),
+								},
+							},
+						},
+						&ec2.Instance{
+							InstanceId: aws.String("i-6"),
+							State: &ec2.InstanceState{
+								Name: aws.String("stopped"),
+							},
+						},
+						runningInstanceWithTags("i-7", "kubernetes.io/cluster/my-cluster"),
+					},
+				},
+			},
+		},
+	},
+	// US-EAST-2 has 1 ECS node (identified by tag only) and 1 standalone instance
+	"us-east-2": []*ec2.DescribeInstancesOutput{
+		&ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				&ec2.Reservation{
+					Instances: []*ec2.Instance{
+						runningInstanceWithTags("i-8", "aws:ecs:clusterName"),
+						runningInstanceWithTags("i-9"),
+					},
+				},
+			},
+		},
+	},
+	// AF-SOUTH-1 has no instances
+	"af-south-1": []*ec2.DescribeInstancesOutput{
+		&ec2.DescribeInstancesOutput{},
+	},
+}
+
+// This is our map of regions and the ECS clusters in each
+var ecsClustersPerRegion = map[string]*ClusterInfo{
+	// US-EAST-1 has a single cluster with one container instance backed by i-3
+	"us-east-1": &ClusterInfo{
+		ContainerInstances: map[string][]*ecs.ContainerInstance{
+			"arn:aws:ecs:us-east-1:123456789012:cluster/my-cluster": []*ecs.ContainerInstance{
+				&ecs.ContainerInstance{
+					ContainerInstanceArn: aws.String("arn:aws:ecs:us-east-1:123456789012:container-instance/abc"),
+					Ec2InstanceId:        aws.String("i-3"),
+				},
+			},
+		},
+	},
+	// US-EAST-2 has no clusters at all
+	"us-east-2": &ClusterInfo{},
+	// AF-SOUTH-1 has a cluster without any container instances (e.g., Fargate only)
+	"af-south-1": &ClusterInfo{
+		ContainerInstances: map[string][]*ecs.ContainerInstance{
+			"arn:aws:ecs:af-south-1:123456789012:cluster/fargate": []*ecs.ContainerInstance{},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeEC2NodeServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Return our current region
+func (fsf fakeEC2NodeServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// Implement a way to return EC2 Regions and instances found in each
+func (fsf fakeEC2NodeServiceFactory) GetEC2InstanceService(regionName string) *EC2InstanceService {
+	// If the caller failed to specify a region, then use what is associated with our factory
+	var resolvedRegionName string
+	if regionName == "" {
+		resolvedRegionName = fsf.RegionName
+	} else {
+		resolvedRegionName = regionName
+	}
+
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DIPResponse: ec2NodesPerRegion[resolvedRegionName],
+			DRResponse:  fsf.DRResponse,
+		},
+	}
+}
+
+// Implement a way to return the ECS clusters found in a specific region
+func (fsf fakeEC2NodeServiceFactory) GetContainerService(regionName string) *ContainerService {
+	// If the caller failed to specify a region, then use what is associated with our factory
+	var resolvedRegionName string
+	if regionName == "" {
+		resolvedRegionName = fsf.RegionName
+	} else {
+		resolvedRegionName = regionName
+	}
+
+	return &ContainerService{
+		Client: &fakeContainerService{
+			ClusterInfo: ecsClustersPerRegion[resolvedRegionName],
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for the node classification of EC2Instances
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestEC2InstancesNodes(t *testing.T) {
+	// Describe all of our test cases: 1 failure and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts EC2NodeCounts
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: EC2NodeCounts{
+				Standalone:  1,
+				EKSNodes:    3,
+				ECSNodes:    1,
+				AutoScaling: 1,
+			},
+		}, {
+			RegionName: "us-east-2",
+			ExpectedCounts: EC2NodeCounts{
+				Standalone: 1,
+				ECSNodes:   1,
+			},
+		}, {
+			RegionName: "af-south-1",
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions: true,
+			ExpectedCounts: EC2NodeCounts{
+				Standalone:  2,
+				EKSNodes:    3,
+				ECSNodes:    2,
+				AutoScaling: 1,
+			},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeEC2NodeServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our EC2 Instances function
+		actualCounts := EC2Instances(sf, mon, c.AllRegions).Nodes
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: EC2Instances returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
@@ -182,6 +182,9 @@ type fakeEC2Service struct {
 	ec2iface.EC2API
 	DIPResponse []*ec2.DescribeInstancesOutput
 	DRResponse  *ec2.DescribeRegionsOutput
+
+	// If supplied, this is incremented for each call to DescribeInstancesPages
+	DIPCalls *int
 }
 
 // Simulate the DescribeRegions function
@@ -345,6 +348,11 @@ func applyDescribeInstancesInputFiltering(input *ec2.DescribeInstancesInput, out
 
 // Simulate the DescribeInstancePages function
 func (fake *fakeEC2Service) DescribeInstancesPages(input *ec2.DescribeInstancesInput, fn func(*ec2.DescribeInstancesOutput, bool) bool) error {
+	// Count our calls
+	if fake.DIPCalls != nil {
+		*fake.DIPCalls++
+	}
+
 	// If the supplied response is nil, then simulate an error
 	if fake.DIPResponse == nil {
 		return errors.New("DescribeInstancePages encountered an unexpected error: 1234")
@@ -377,23 +385,17 @@ func (fake *fakeEC2Service) DescribeInstancesPages(input *ec2.DescribeInstancesI
 // This structure simulates the AWS Service Factory by storing some pregenerated
 // responses (that would come from AWS)
 type fakeEC2ServiceFactory struct {
+	baseFakeServiceFactory
 	RegionName string
 	DRResponse *ec2.DescribeRegionsOutput
+	DIPCalls   *int
 }
 
-// Don't need to implement
-func (fsf fakeEC2ServiceFactory) Init() {}
-
 // Return our current region
 func (fsf fakeEC2ServiceFactory) GetCurrentRegion() string {
 	return fsf.RegionName
 }
 
-// Don't need to implement
-func (fsf fakeEC2ServiceFactory) GetAccountIDService() *AccountIDService {
-	return nil
-}
-
 // Implement a way to return EC2 Regions and instances found in each
 func (fsf fakeEC2ServiceFactory) GetEC2InstanceService(regionName string) *EC2InstanceService {
 	// If the caller failed to specify a region, then use what is associated with our factory
@@ -408,78 +410,78 @@ func (fsf fakeEC2ServiceFactory) GetEC2InstanceService(regionName string) *EC2In
 		Client: &fakeEC2Service{
 			DIPResponse: ec2InstancesPerRegion[resolvedRegionName],
 			DRResponse:  fsf.DRResponse,
+			DIPCalls:    fsf.DIPCalls,
 		},
 	}
 }
 
-// Don't need to implement
-func (fsf fakeEC2ServiceFactory) GetRDSInstanceService(string) *RDSInstanceService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeEC2ServiceFactory) GetS3Service() *S3Service {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeEC2ServiceFactory) GetLambdaService(string) *LambdaService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeEC2ServiceFactory) GetContainerService(string) *ContainerService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeEC2ServiceFactory) GetLightsailService(string) *LightsailService {
-	return nil
+// Return a container service without any ECS clusters
+func (fsf fakeEC2ServiceFactory) GetContainerService(regionName string) *ContainerService {
+	return &ContainerService{
+		Client: &fakeContainerService{
+			ClusterInfo: &ClusterInfo{},
+		},
+	}
 }
 
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
-// Unit Test for EC2Counts
+// Unit Test for EC2Instances
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 
-func TestEC2Counts(t *testing.T) {
+func TestEC2Instances(t *testing.T) {
 	// Describe all of our test cases: 1 failure and 4 success cases
 	cases := []struct {
-		RegionName    string
-		AllRegions    bool
-		ExpectedCount int
-		ExpectError   bool
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts EC2InstanceCounts
+		ExpectedCalls  int
+		ExpectError    bool
 	}{
 		{
-			RegionName:    "us-east-1",
-			ExpectedCount: 3,
+			RegionName: "us-east-1",
+			ExpectedCounts: EC2InstanceCounts{
+				Lifecycles: EC2LifecycleCounts{OnDemand: 3, Spot: 1},
+				Nodes:      EC2NodeCounts{Standalone: 4},
+			},
+			ExpectedCalls: 1,
 		}, {
-			RegionName:    "us-east-2",
-			ExpectedCount: 5,
+			RegionName: "us-east-2",
+			ExpectedCounts: EC2InstanceCounts{
+				Lifecycles: EC2LifecycleCounts{OnDemand: 5},
+				Nodes:      EC2NodeCounts{Standalone: 5},
+			},
+			ExpectedCalls: 1,
 		}, {
 			RegionName:    "af-south-1",
-			ExpectedCount: 0,
+			ExpectedCalls: 1,
 		}, {
 			RegionName:  "undefined-region",
 			ExpectError: true,
 		}, {
-			AllRegions:    true,
-			ExpectedCount: 8,
+			AllRegions: true,
+			ExpectedCounts: EC2InstanceCounts{
+				Lifecycles: EC2LifecycleCounts{OnDemand: 8, Spot: 1},
+				Nodes:      EC2NodeCounts{Standalone: 9},
+			},
+			ExpectedCalls: 3,
 		},
 	}
 
 	// Loop through each test case
 	for _, c := range cases {
-		// Create our fake service factory
+		// Create our fake service factory (counting calls to DescribeInstances)
+		var calls int
 		sf := fakeEC2ServiceFactory{
 			RegionName: c.RegionName,
 			DRResponse: ec2Regions,
+			DIPCalls:   &calls,
 		}
 
 		// Create a mock activity monitor
 		mon := &mock.ActivityMonitorImpl{}
 
-		// Invoke our EC2 Counter function
-		actualCount := EC2Counts(sf, mon, c.AllRegions)
+		// Invoke our EC2 Instances function
+		actualCounts := EC2Instances(sf, mon, c.AllRegions)
 
 		// Did we expect an error?
 		if c.ExpectError {
@@ -489,8 +491,10 @@ func TestEC2Counts(t *testing.T) {
 			}
 		} else if mon.ErrorOccured {
 			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
-		} else if actualCount != c.ExpectedCount {
-			t.Errorf("Error: EC2Counts returned %d; expected %d", actualCount, c.ExpectedCount)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: EC2Instances returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if calls != c.ExpectedCalls {
+			t.Errorf("Error: EC2Instances described instances %d times; expected %d (once per region)", calls, c.ExpectedCalls)
 		} else if mon.ProgramExited {
 			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
 		}
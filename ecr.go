@@ -0,0 +1,103 @@
+/******************************************************************************
+Cloud Resource Counter
+File: ecr.go
+
+Summary: Provides a count of ECR repositories and the images stored in them.
+******************************************************************************/
+
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/service/ecr"
+	color "github.com/logrusorgru/aurora"
+)
+
+// ECRCounts holds the number of ECR repositories along with the number of images
+// (distinct digests) and image tags stored in them.
+type ECRCounts struct {
+	Repositories int
+	Images       int
+	Tags         int
+}
+
+// Add the supplied counts into our struct.
+func (ec *ECRCounts) Add(other ECRCounts) {
+	ec.Repositories += other.Repositories
+	ec.Images += other.Images
+	ec.Tags += other.Tags
+}
+
+// ECRRepositories retrieves the counts of all ECR repositories, images and image
+// tags either for all regions (allRegions is true) or the region associated with
+// the session. This method gives status back to the user via the supplied
+// ActivityMonitor instance.
+func ECRRepositories(sf ServiceFactory, am ActivityMonitor, allRegions bool) ECRCounts {
+	// Indicate activity
+	am.StartAction("Retrieving ECR repository counts")
+
+	// Should we get the counts for all regions?
+	var ecrCounts ECRCounts
+	if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the ECR counts for a specific region
+			ecrCounts.Add(ecrRepositoriesForSingleRegion(sf.GetECRService(regionName), am))
+		}
+	} else {
+		// Get the ECR counts for the region selected by this session
+		ecrCounts = ecrRepositoriesForSingleRegion(sf.GetECRService(""), am)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d repositories, %d images)", color.Bold(ecrCounts.Repositories), color.Bold(ecrCounts.Images))
+
+	return ecrCounts
+}
+
+// Get the ECR counts for a single region
+func ecrRepositoriesForSingleRegion(ecrs *ECRService, am ActivityMonitor) ECRCounts {
+	// Indicate activity
+	am.Message(".")
+
+	// Get the names of all repositories
+	var repositoryNames []*string
+	err := ecrs.DescribeRepositories(&ecr.DescribeRepositoriesInput{}, func(page *ecr.DescribeRepositoriesOutput, lastPage bool) bool {
+		for _, repository := range page.Repositories {
+			repositoryNames = append(repositoryNames, repository.RepositoryName)
+		}
+
+		return true
+	})
+
+	// Check for error
+	var ecrCounts ECRCounts
+	if am.CheckError(err) {
+		return ecrCounts
+	}
+	ecrCounts.Repositories = len(repositoryNames)
+
+	// Loop through the repositories...
+	for _, repositoryName := range repositoryNames {
+		// Each image detail describes a distinct image digest along with its tags
+		err = ecrs.DescribeImages(&ecr.DescribeImagesInput{
+			RepositoryName: repositoryName,
+		}, func(page *ecr.DescribeImagesOutput, lastPage bool) bool {
+			for _, imageDetail := range page.ImageDetails {
+				ecrCounts.Images++
+				ecrCounts.Tags += len(imageDetail.ImageTags)
+			}
+
+			return true
+		})
+
+		// Check for error
+		if am.CheckError(err) {
+			break
+		}
+	}
+
+	return ecrCounts
+}
@@ -0,0 +1,294 @@
+/******************************************************************************
+Cloud Resource Counter
+File: ecr_test.go
+
+Summary: The Unit Test for ecr.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake ECR Repository Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// ECRRegionInfo describes the fake repositories in a region. DescribeRepositories
+// returns the pages in RepositoryPages; the images of each repository (in pages)
+// are found in Images, keyed by repository name.
+type ECRRegionInfo struct {
+	RepositoryPages []*ecr.DescribeRepositoriesOutput
+	Images          map[string][]*ecr.DescribeImagesOutput
+}
+
+// Helper function to construct an image detail with the supplied tags
+func imageWithTags(tags ...string) *ecr.ImageDetail {
+	return &ecr.ImageDetail{
+		ImageTags: aws.StringSlice(tags),
+	}
+}
+
+// This is our map of regions and the repositories in each
+var ecrRepositoriesPerRegion = map[string]*ECRRegionInfo{
+	// US-EAST-1 illustrates a case where DescribeRepositoriesPages returns two pages of
+	// results. The "web" repository returns 2 pages of images: 3 images (with 4 tags) in
+	// total. The "worker" repository has 1 untagged image. The "empty" repository has none.
+	"us-east-1": &ECRRegionInfo{
+		RepositoryPages: []*ecr.DescribeRepositoriesOutput{
+			&ecr.DescribeRepositoriesOutput{
+				Repositories: []*ecr.Repository{
+					&ecr.Repository{RepositoryName: aws.String("web")},
+					&ecr.Repository{RepositoryName: aws.String("worker")},
+				},
+			},
+			&ecr.DescribeRepositoriesOutput{
+				Repositories: []*ecr.Repository{
+					&ecr.Repository{RepositoryName: aws.String("empty")},
+				},
+			},
+		},
+		Images: map[string][]*ecr.DescribeImagesOutput{
+			"web": []*ecr.DescribeImagesOutput{
+				&ecr.DescribeImagesOutput{
+					ImageDetails: []*ecr.ImageDetail{
+						imageWithTags("latest", "v2"),
+						imageWithTags("v1"),
+					},
+				},
+				&ecr.DescribeImagesOutput{
+					ImageDetails: []*ecr.ImageDetail{
+						imageWithTags("v0"),
+					},
+				},
+			},
+			"worker": []*ecr.DescribeImagesOutput{
+				&ecr.DescribeImagesOutput{
+					ImageDetails: []*ecr.ImageDetail{
+						imageWithTags(),
+					},
+				},
+			},
+			"empty": []*ecr.DescribeImagesOutput{
+				&ecr.DescribeImagesOutput{},
+			},
+		},
+	},
+	// US-EAST-2 has a single repository with 2 images (with 2 tags)
+	"us-east-2": &ECRRegionInfo{
+		RepositoryPages: []*ecr.DescribeRepositoriesOutput{
+			&ecr.DescribeRepositoriesOutput{
+				Repositories: []*ecr.Repository{
+					&ecr.Repository{RepositoryName: aws.String("api")},
+				},
+			},
+		},
+		Images: map[string][]*ecr.DescribeImagesOutput{
+			"api": []*ecr.DescribeImagesOutput{
+				&ecr.DescribeImagesOutput{
+					ImageDetails: []*ecr.ImageDetail{
+						imageWithTags("prod"),
+						imageWithTags("staging"),
+					},
+				},
+			},
+		},
+	},
+	// AF-SOUTH-1 has no repositories
+	"af-south-1": &ECRRegionInfo{
+		RepositoryPages: []*ecr.DescribeRepositoriesOutput{
+			&ecr.DescribeRepositoriesOutput{},
+		},
+	},
+	// AF-SOUTH-2 has a repository whose images cannot be described
+	"af-south-2": &ECRRegionInfo{
+		RepositoryPages: []*ecr.DescribeRepositoriesOutput{
+			&ecr.DescribeRepositoriesOutput{
+				Repositories: []*ecr.Repository{
+					&ecr.Repository{RepositoryName: aws.String("forbidden")},
+				},
+			},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake ECR Service
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// To use this struct, the caller must supply an ECRRegionInfo. If it is missing,
+// it will trigger the mock functions to simulate an error.
+type fakeECRService struct {
+	ecriface.ECRAPI
+	RegionInfo *ECRRegionInfo
+}
+
+// Simulate the DescribeRepositoriesPages function
+func (fake *fakeECRService) DescribeRepositoriesPages(input *ecr.DescribeRepositoriesInput, fn func(*ecr.DescribeRepositoriesOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil {
+		return errors.New("DescribeRepositoriesPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.RepositoryPages {
+		// Are we looking at the last "page" of our output?
+		lastPage := index == len(fake.RegionInfo.RepositoryPages)-1
+
+		// Invoke our fn
+		if !fn(output, lastPage) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Simulate the DescribeImagesPages function
+func (fake *fakeECRService) DescribeImagesPages(input *ecr.DescribeImagesInput, fn func(*ecr.DescribeImagesOutput, bool) bool) error {
+	// Find the images of the repository
+	pages, ok := fake.RegionInfo.Images[aws.StringValue(input.RepositoryName)]
+	if !ok {
+		return fmt.Errorf("DescribeImagesPages could not find repository: %s", aws.StringValue(input.RepositoryName))
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range pages {
+		// Are we looking at the last "page" of our output?
+		lastPage := index == len(pages)-1
+
+		// Invoke our fn
+		if !fn(output, lastPage) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeECRServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Return our current region
+func (fsf fakeECRServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeECRServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return a specialized ECRService that returns pre-canned responses
+func (fsf fakeECRServiceFactory) GetECRService(regionName string) *ECRService {
+	// If the caller failed to specify a region, then use what is associated with our factory
+	var resolvedRegionName string
+	if regionName == "" {
+		resolvedRegionName = fsf.RegionName
+	} else {
+		resolvedRegionName = regionName
+	}
+
+	return &ECRService{
+		Client: &fakeECRService{
+			RegionInfo: ecrRepositoriesPerRegion[resolvedRegionName],
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for ECRRepositories
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestECRRepositories(t *testing.T) {
+	// Describe all of our test cases: 2 failures and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts ECRCounts
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: ECRCounts{
+				Repositories: 3,
+				Images:       4,
+				Tags:         4,
+			},
+		}, {
+			RegionName: "us-east-2",
+			ExpectedCounts: ECRCounts{
+				Repositories: 1,
+				Images:       2,
+				Tags:         2,
+			},
+		}, {
+			RegionName: "af-south-1",
+		}, {
+			RegionName:  "af-south-2",
+			ExpectError: true,
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions: true,
+			ExpectedCounts: ECRCounts{
+				Repositories: 4,
+				Images:       6,
+				Tags:         6,
+			},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeECRServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our ECR Repositories function
+		actualCounts := ECRRepositories(sf, mon, c.AllRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: ECRRepositories returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+/******************************************************************************
+Cloud Resource Counter
+File: eks.go
+
+Summary: Provides a count of EKS clusters, their managed node groups (and the
+         desired number of nodes in each) and their Fargate profiles.
+******************************************************************************/
+
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	color "github.com/logrusorgru/aurora"
+)
+
+// EKSCounts holds the number of EKS clusters along with the managed node groups,
+// desired node capacity and Fargate profiles across those clusters.
+type EKSCounts struct {
+	Clusters        int
+	NodeGroups      int
+	DesiredNodes    int
+	FargateProfiles int
+}
+
+// Add the supplied counts into our struct.
+func (ec *EKSCounts) Add(other EKSCounts) {
+	ec.Clusters += other.Clusters
+	ec.NodeGroups += other.NodeGroups
+	ec.DesiredNodes += other.DesiredNodes
+	ec.FargateProfiles += other.FargateProfiles
+}
+
+// EKSClusters retrieves the counts of all EKS clusters, managed node groups,
+// desired nodes and Fargate profiles either for all regions (allRegions is true)
+// or the region associated with the session. Clusters and node groups that are
+// being deleted are not counted. This method gives status back to the user via
+// the supplied ActivityMonitor instance.
+func EKSClusters(sf ServiceFactory, am ActivityMonitor, allRegions bool) EKSCounts {
+	// Indicate activity
+	am.StartAction("Retrieving EKS cluster counts")
+
+	// Should we get the counts for all regions?
+	var eksCounts EKSCounts
+	if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the EKS counts for a specific region
+			eksCounts.Add(eksClustersForSingleRegion(sf.GetEKSService(regionName), am))
+		}
+	} else {
+		// Get the EKS counts for the region selected by this session
+		eksCounts = eksClustersForSingleRegion(sf.GetEKSService(""), am)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d clusters, %d node groups, %d nodes, %d Fargate profiles)",
+		color.Bold(eksCounts.Clusters), color.Bold(eksCounts.NodeGroups),
+		color.Bold(eksCounts.DesiredNodes), color.Bold(eksCounts.FargateProfiles))
+
+	return eksCounts
+}
+
+// Get the EKS counts for a single region
+func eksClustersForSingleRegion(eksvc *EKSService, am ActivityMonitor) EKSCounts {
+	// Indicate activity
+	am.Message(".")
+
+	// Get the names of all clusters
+	var clusterNames []*string
+	err := eksvc.ListClusters(&eks.ListClustersInput{}, func(page *eks.ListClustersOutput, lastPage bool) bool {
+		clusterNames = append(clusterNames, page.Clusters...)
+
+		return true
+	})
+
+	// Check for error
+	var eksCounts EKSCounts
+	if am.CheckError(err) {
+		return eksCounts
+	}
+
+	// Loop through the clusters...
+	for _, clusterName := range clusterNames {
+		// Inspect the cluster
+		output, err := eksvc.InspectCluster(&eks.DescribeClusterInput{
+			Name: clusterName,
+		})
+		if am.CheckError(err) {
+			return eksCounts
+		}
+
+		// Skip any cluster that is going away
+		if output.Cluster == nil || aws.StringValue(output.Cluster.Status) == eks.ClusterStatusDeleting {
+			continue
+		}
+		eksCounts.Clusters++
+
+		// Count the managed node groups (and their desired sizes)
+		nodeGroups, desiredNodes, ok := eksNodegroupsForCluster(eksvc, am, clusterName)
+		if !ok {
+			return eksCounts
+		}
+		eksCounts.NodeGroups += nodeGroups
+		eksCounts.DesiredNodes += desiredNodes
+
+		// Count the Fargate profiles
+		err = eksvc.ListFargateProfiles(&eks.ListFargateProfilesInput{
+			ClusterName: clusterName,
+		}, func(page *eks.ListFargateProfilesOutput, lastPage bool) bool {
+			eksCounts.FargateProfiles += len(page.FargateProfileNames)
+
+			return true
+		})
+		if am.CheckError(err) {
+			return eksCounts
+		}
+	}
+
+	return eksCounts
+}
+
+// Get the number of managed node groups in a cluster and the sum of their desired
+// sizes. The boolean result is false if an error occurred.
+func eksNodegroupsForCluster(eksvc *EKSService, am ActivityMonitor, clusterName *string) (int, int, bool) {
+	// Get the names of all node groups in this cluster
+	var nodegroupNames []*string
+	err := eksvc.ListNodegroups(&eks.ListNodegroupsInput{
+		ClusterName: clusterName,
+	}, func(page *eks.ListNodegroupsOutput, lastPage bool) bool {
+		nodegroupNames = append(nodegroupNames, page.Nodegroups...)
+
+		return true
+	})
+
+	// Check for error
+	if am.CheckError(err) {
+		return 0, 0, false
+	}
+
+	// Loop through the node groups...
+	var nodeGroups, desiredNodes int
+	for _, nodegroupName := range nodegroupNames {
+		// Inspect the node group
+		output, err := eksvc.InspectNodegroup(&eks.DescribeNodegroupInput{
+			ClusterName:   clusterName,
+			NodegroupName: nodegroupName,
+		})
+		if am.CheckError(err) {
+			return 0, 0, false
+		}
+
+		// Skip any node group that is going away
+		if output.Nodegroup == nil || aws.StringValue(output.Nodegroup.Status) == eks.NodegroupStatusDeleting {
+			continue
+		}
+		nodeGroups++
+
+		// Add its desired size
+		if output.Nodegroup.ScalingConfig != nil {
+			desiredNodes += int(aws.Int64Value(output.Nodegroup.ScalingConfig.DesiredSize))
+		}
+	}
+
+	return nodeGroups, desiredNodes, true
+}
@@ -0,0 +1,338 @@
+/******************************************************************************
+Cloud Resource Counter
+File: eks_test.go
+
+Summary: The Unit Test for eks.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake EKS Cluster Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// EKSClusterInfo describes a single fake cluster: its status, its managed node
+// groups (keyed by name) and the names of its Fargate profiles.
+type EKSClusterInfo struct {
+	Status          string
+	Nodegroups      map[string]*eks.Nodegroup
+	FargateProfiles []string
+}
+
+// EKSRegionInfo describes the fake clusters in a region. ListClusters returns
+// the pages of cluster names in ClusterPages; each name is described in Clusters.
+type EKSRegionInfo struct {
+	ClusterPages [][]string
+	Clusters     map[string]*EKSClusterInfo
+}
+
+// Helper function to construct a node group with the supplied status and desired size
+func nodegroupWithDesiredSize(status string, desiredSize int64) *eks.Nodegroup {
+	return &eks.Nodegroup{
+		Status: aws.String(status),
+		ScalingConfig: &eks.NodegroupScalingConfig{
+			DesiredSize: aws.Int64(desiredSize),
+		},
+	}
+}
+
+// This is our map of regions and the clusters in each
+var eksClustersPerRegion = map[string]*EKSRegionInfo{
+	// US-EAST-1 illustrates a case where ListClustersPages returns two pages of results.
+	// First page: "prod" (2 node groups totaling 5 nodes, 1 Fargate profile) and "dev"
+	// (1 node group of 1 node, plus a node group being deleted)
+	// Second page: "old" which is being deleted (and is not counted)
+	"us-east-1": &EKSRegionInfo{
+		ClusterPages: [][]string{
+			{"prod", "dev"},
+			{"old"},
+		},
+		Clusters: map[string]*EKSClusterInfo{
+			"prod": &EKSClusterInfo{
+				Status: eks.ClusterStatusActive,
+				Nodegroups: map[string]*eks.Nodegroup{
+					"general": nodegroupWithDesiredSize(eks.NodegroupStatusActive, 3),
+					"gpu":     nodegroupWithDesiredSize(eks.NodegroupStatusActive, 2),
+				},
+				FargateProfiles: []string{"kube-system"},
+			},
+			"dev": &EKSClusterInfo{
+				Status: eks.ClusterStatusUpdating,
+				Nodegroups: map[string]*eks.Nodegroup{
+					"small":   nodegroupWithDesiredSize(eks.NodegroupStatusActive, 1),
+					"retired": nodegroupWithDesiredSize(eks.NodegroupStatusDeleting, 4),
+				},
+			},
+			"old": &EKSClusterInfo{
+				Status: eks.ClusterStatusDeleting,
+				Nodegroups: map[string]*eks.Nodegroup{
+					"general": nodegroupWithDesiredSize(eks.NodegroupStatusDeleting, 3),
+				},
+				FargateProfiles: []string{"default"},
+			},
+		},
+	},
+	// US-EAST-2 has a single Fargate-only cluster with 3 Fargate profiles
+	"us-east-2": &EKSRegionInfo{
+		ClusterPages: [][]string{
+			{"serverless"},
+		},
+		Clusters: map[string]*EKSClusterInfo{
+			"serverless": &EKSClusterInfo{
+				Status:          eks.ClusterStatusActive,
+				FargateProfiles: []string{"default", "kube-system", "batch"},
+			},
+		},
+	},
+	// AF-SOUTH-1 has no clusters
+	"af-south-1": &EKSRegionInfo{
+		ClusterPages: [][]string{
+			{},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake EKS Service
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// To use this struct, the caller must supply an EKSRegionInfo. If it is missing,
+// it will trigger the mock functions to simulate an error.
+type fakeEKSService struct {
+	eksiface.EKSAPI
+	RegionInfo *EKSRegionInfo
+}
+
+// Find the named cluster (or return an error)
+func (fake *fakeEKSService) cluster(clusterName *string) (*EKSClusterInfo, error) {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil {
+		return nil, errors.New("EKS encountered an unexpected error: 1234")
+	}
+
+	// Find the cluster
+	clusterInfo, ok := fake.RegionInfo.Clusters[aws.StringValue(clusterName)]
+	if !ok {
+		return nil, fmt.Errorf("No cluster found: %s", aws.StringValue(clusterName))
+	}
+
+	return clusterInfo, nil
+}
+
+// Simulate the ListClustersPages function
+func (fake *fakeEKSService) ListClustersPages(input *eks.ListClustersInput, fn func(*eks.ListClustersOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil {
+		return errors.New("ListClustersPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the pages of cluster names, invoking the supplied function
+	for index, clusterNames := range fake.RegionInfo.ClusterPages {
+		// Are we looking at the last "page" of our output?
+		lastPage := index == len(fake.RegionInfo.ClusterPages)-1
+
+		// Invoke our fn
+		if !fn(&eks.ListClustersOutput{Clusters: aws.StringSlice(clusterNames)}, lastPage) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Simulate the DescribeCluster function
+func (fake *fakeEKSService) DescribeCluster(input *eks.DescribeClusterInput) (*eks.DescribeClusterOutput, error) {
+	// Find the cluster
+	clusterInfo, err := fake.cluster(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eks.DescribeClusterOutput{
+		Cluster: &eks.Cluster{
+			Name:   input.Name,
+			Status: aws.String(clusterInfo.Status),
+		},
+	}, nil
+}
+
+// Simulate the ListNodegroupsPages function (as a single page)
+func (fake *fakeEKSService) ListNodegroupsPages(input *eks.ListNodegroupsInput, fn func(*eks.ListNodegroupsOutput, bool) bool) error {
+	// Find the cluster
+	clusterInfo, err := fake.cluster(input.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	// Collect the node group names
+	var nodegroupNames []string
+	for nodegroupName := range clusterInfo.Nodegroups {
+		nodegroupNames = append(nodegroupNames, nodegroupName)
+	}
+
+	// Invoke our fn
+	fn(&eks.ListNodegroupsOutput{Nodegroups: aws.StringSlice(nodegroupNames)}, true)
+
+	return nil
+}
+
+// Simulate the DescribeNodegroup function
+func (fake *fakeEKSService) DescribeNodegroup(input *eks.DescribeNodegroupInput) (*eks.DescribeNodegroupOutput, error) {
+	// Find the cluster
+	clusterInfo, err := fake.cluster(input.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the node group
+	nodegroup, ok := clusterInfo.Nodegroups[aws.StringValue(input.NodegroupName)]
+	if !ok {
+		return nil, fmt.Errorf("No node group found: %s", aws.StringValue(input.NodegroupName))
+	}
+
+	return &eks.DescribeNodegroupOutput{
+		Nodegroup: nodegroup,
+	}, nil
+}
+
+// Simulate the ListFargateProfilesPages function (as a single page)
+func (fake *fakeEKSService) ListFargateProfilesPages(input *eks.ListFargateProfilesInput, fn func(*eks.ListFargateProfilesOutput, bool) bool) error {
+	// Find the cluster
+	clusterInfo, err := fake.cluster(input.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	// Invoke our fn
+	fn(&eks.ListFargateProfilesOutput{FargateProfileNames: aws.StringSlice(clusterInfo.FargateProfiles)}, true)
+
+	return nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeEKSServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Return our current region
+func (fsf fakeEKSServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeEKSServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return a specialized EKSService that returns pre-canned responses
+func (fsf fakeEKSServiceFactory) GetEKSService(regionName string) *EKSService {
+	// If the caller failed to specify a region, then use what is associated with our factory
+	var resolvedRegionName string
+	if regionName == "" {
+		resolvedRegionName = fsf.RegionName
+	} else {
+		resolvedRegionName = regionName
+	}
+
+	return &EKSService{
+		Client: &fakeEKSService{
+			RegionInfo: eksClustersPerRegion[resolvedRegionName],
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for EKSClusters
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestEKSClusters(t *testing.T) {
+	// Describe all of our test cases: 1 failure and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts EKSCounts
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: EKSCounts{
+				Clusters:        2,
+				NodeGroups:      3,
+				DesiredNodes:    6,
+				FargateProfiles: 1,
+			},
+		}, {
+			RegionName: "us-east-2",
+			ExpectedCounts: EKSCounts{
+				Clusters:        1,
+				FargateProfiles: 3,
+			},
+		}, {
+			RegionName: "af-south-1",
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions: true,
+			ExpectedCounts: EKSCounts{
+				Clusters:        3,
+				NodeGroups:      3,
+				DesiredNodes:    6,
+				FargateProfiles: 4,
+			},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeEKSServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our EKS Clusters function
+		actualCounts := EKSClusters(sf, mon, c.AllRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: EKSClusters returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
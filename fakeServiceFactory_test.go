@@ -0,0 +1,185 @@
+/******************************************************************************
+Cloud Resource Counter
+File: fakeServiceFactory_test.go
+
+Summary: A base fake ServiceFactory for unit tests. Each test's fake factory
+         embeds it and overrides only the methods that the test needs.
+******************************************************************************/
+
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// baseFakeServiceFactory implements every method of ServiceFactory, returning the
+// zero value of each.
+type baseFakeServiceFactory struct{}
+
+// Init does nothing
+func (fsf baseFakeServiceFactory) Init() {}
+
+// GetCurrentRegion does nothing
+func (fsf baseFakeServiceFactory) GetCurrentRegion() string {
+	return ""
+}
+
+// GetCredentials does nothing
+func (fsf baseFakeServiceFactory) GetCredentials() *credentials.Credentials {
+	return nil
+}
+
+// GetAccountIDService does nothing
+func (fsf baseFakeServiceFactory) GetAccountIDService() *AccountIDService {
+	return nil
+}
+
+// GetEC2InstanceService does nothing
+func (fsf baseFakeServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return nil
+}
+
+// GetRDSInstanceService does nothing
+func (fsf baseFakeServiceFactory) GetRDSInstanceService(string) *RDSInstanceService {
+	return nil
+}
+
+// GetS3Service does nothing
+func (fsf baseFakeServiceFactory) GetS3Service() *S3Service {
+	return nil
+}
+
+// GetLambdaService does nothing
+func (fsf baseFakeServiceFactory) GetLambdaService(string) *LambdaService {
+	return nil
+}
+
+// GetContainerService does nothing
+func (fsf baseFakeServiceFactory) GetContainerService(string) *ContainerService {
+	return nil
+}
+
+// GetLightsailService does nothing
+func (fsf baseFakeServiceFactory) GetLightsailService(string) *LightsailService {
+	return nil
+}
+
+// GetEKSService does nothing
+func (fsf baseFakeServiceFactory) GetEKSService(string) *EKSService {
+	return nil
+}
+
+// GetECRService does nothing
+func (fsf baseFakeServiceFactory) GetECRService(string) *ECRService {
+	return nil
+}
+
+// GetDynamoDBService does nothing
+func (fsf baseFakeServiceFactory) GetDynamoDBService(string) *DynamoDBService {
+	return nil
+}
+
+// GetElastiCacheService does nothing
+func (fsf baseFakeServiceFactory) GetElastiCacheService(string) *ElastiCacheService {
+	return nil
+}
+
+// GetRedshiftService does nothing
+func (fsf baseFakeServiceFactory) GetRedshiftService(string) *RedshiftService {
+	return nil
+}
+
+// GetRedshiftServerlessService does nothing
+func (fsf baseFakeServiceFactory) GetRedshiftServerlessService(string) *RedshiftServerlessService {
+	return nil
+}
+
+// GetOpenSearchService does nothing
+func (fsf baseFakeServiceFactory) GetOpenSearchService(string) *OpenSearchService {
+	return nil
+}
+
+// GetDocumentDBService does nothing
+func (fsf baseFakeServiceFactory) GetDocumentDBService(string) *DocumentDBService {
+	return nil
+}
+
+// GetNeptuneService does nothing
+func (fsf baseFakeServiceFactory) GetNeptuneService(string) *NeptuneService {
+	return nil
+}
+
+// GetELBService does nothing
+func (fsf baseFakeServiceFactory) GetELBService(string) *ELBService {
+	return nil
+}
+
+// GetELBv2Service does nothing
+func (fsf baseFakeServiceFactory) GetELBv2Service(string) *ELBv2Service {
+	return nil
+}
+
+// GetCloudFrontService does nothing
+func (fsf baseFakeServiceFactory) GetCloudFrontService() *CloudFrontService {
+	return nil
+}
+
+// GetStepFunctionsService does nothing
+func (fsf baseFakeServiceFactory) GetStepFunctionsService(string) *StepFunctionsService {
+	return nil
+}
+
+// GetAPIGatewayService does nothing
+func (fsf baseFakeServiceFactory) GetAPIGatewayService(string) *APIGatewayService {
+	return nil
+}
+
+// GetAPIGatewayV2Service does nothing
+func (fsf baseFakeServiceFactory) GetAPIGatewayV2Service(string) *APIGatewayV2Service {
+	return nil
+}
+
+// GetSQSService does nothing
+func (fsf baseFakeServiceFactory) GetSQSService(string) *SQSService {
+	return nil
+}
+
+// GetSNSService does nothing
+func (fsf baseFakeServiceFactory) GetSNSService(string) *SNSService {
+	return nil
+}
+
+// GetEventBridgeService does nothing
+func (fsf baseFakeServiceFactory) GetEventBridgeService(string) *EventBridgeService {
+	return nil
+}
+
+// GetCloudWatchService does nothing
+func (fsf baseFakeServiceFactory) GetCloudWatchService(string) *CloudWatchService {
+	return nil
+}
+
+// GetCloudControlService does nothing
+func (fsf baseFakeServiceFactory) GetCloudControlService(string) *CloudControlService {
+	return nil
+}
+
+// GetConfigService does nothing
+func (fsf baseFakeServiceFactory) GetConfigService(string) *ConfigService {
+	return nil
+}
+
+// GetResourceExplorerService does nothing
+func (fsf baseFakeServiceFactory) GetResourceExplorerService(string) *ResourceExplorerService {
+	return nil
+}
+
+// GetTaggingService does nothing
+func (fsf baseFakeServiceFactory) GetTaggingService(string) *TaggingService {
+	return nil
+}
+
+// GetGenericService does nothing
+func (fsf baseFakeServiceFactory) GetGenericService(string, string) *GenericService {
+	return nil
+}
@@ -0,0 +1,165 @@
+/******************************************************************************
+Cloud Resource Counter
+File: fargate.go
+
+Summary: Provides a count of running ECS Fargate tasks along with the vCPU and
+         memory reserved by them.
+******************************************************************************/
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	color "github.com/logrusorgru/aurora"
+)
+
+// The number of CPU units in a single vCPU
+const cpuUnitsPerVCPU = 1024
+
+// FargateCounts holds the number of running Fargate tasks and the resources
+// that they have reserved.
+type FargateCounts struct {
+	Tasks     int
+	CPUUnits  int
+	MemoryMiB int
+}
+
+// VCPUs returns the reserved CPU as a number of vCPUs.
+func (fc FargateCounts) VCPUs() float64 {
+	return float64(fc.CPUUnits) / cpuUnitsPerVCPU
+}
+
+// Add the supplied counts into our struct.
+func (fc *FargateCounts) Add(other FargateCounts) {
+	fc.Tasks += other.Tasks
+	fc.CPUUnits += other.CPUUnits
+	fc.MemoryMiB += other.MemoryMiB
+}
+
+// FargateTasks retrieves the count of all running ECS tasks launched on Fargate,
+// along with their total vCPU and memory reservations, either for all regions
+// (allRegions is true) or the region associated with the session. This method
+// gives status back to the user via the supplied ActivityMonitor instance.
+func FargateTasks(sf ServiceFactory, am ActivityMonitor, allRegions bool) FargateCounts {
+	// Indicate activity
+	am.StartAction("Retrieving Fargate task counts")
+
+	// Should we get the counts for all regions?
+	var fargateCounts FargateCounts
+	if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the Fargate counts for a specific region
+			fargateCounts.Add(fargateTasksForSingleRegion(sf.GetContainerService(regionName), am))
+		}
+	} else {
+		// Get the Fargate counts for the region selected by this session
+		fargateCounts = fargateTasksForSingleRegion(sf.GetContainerService(""), am)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d tasks, %v vCPU, %d MiB)", color.Bold(fargateCounts.Tasks),
+		color.Bold(fargateCounts.VCPUs()), color.Bold(fargateCounts.MemoryMiB))
+
+	return fargateCounts
+}
+
+// Get the Fargate counts for a single region
+func fargateTasksForSingleRegion(cs *ContainerService, am ActivityMonitor) FargateCounts {
+	// Indicate activity
+	am.Message(".")
+
+	// Get the list of clusters
+	var fargateCounts FargateCounts
+	clusterArns, ok := ecsClusterArnsForSingleRegion(cs, am)
+	if !ok {
+		return fargateCounts
+	}
+
+	// Loop through the clusters...
+	for _, clusterArn := range clusterArns {
+		// Each page holds at most 100 task ARNs, which is what DescribeTasks accepts.
+		// (By default, ListTasks only returns tasks whose desired status is RUNNING.)
+		var innerErr error
+		err := cs.ListTasks(&ecs.ListTasksInput{
+			Cluster:    clusterArn,
+			LaunchType: aws.String(ecs.LaunchTypeFargate),
+		}, func(page *ecs.ListTasksOutput, lastPage bool) bool {
+			// Anything to describe?
+			if len(page.TaskArns) == 0 {
+				return true
+			}
+
+			// Inspect the tasks
+			var output *ecs.DescribeTasksOutput
+			output, innerErr = cs.InspectTasks(&ecs.DescribeTasksInput{
+				Cluster: clusterArn,
+				Tasks:   page.TaskArns,
+			})
+			if innerErr != nil {
+				// Stop iterating
+				return false
+			}
+
+			// Add up the reservations of each running task
+			for _, task := range output.Tasks {
+				if aws.StringValue(task.LastStatus) == ecs.DesiredStatusRunning {
+					fargateCounts.Tasks++
+					fargateCounts.CPUUnits += parseFargateCPU(aws.StringValue(task.Cpu))
+					fargateCounts.MemoryMiB += parseFargateMemory(aws.StringValue(task.Memory))
+				}
+			}
+
+			return true
+		})
+
+		// Check for either error
+		if am.CheckError(err) || am.CheckError(innerErr) {
+			break
+		}
+	}
+
+	return fargateCounts
+}
+
+// Convert a task's CPU reservation into CPU units. The reservation is normally
+// expressed in CPU units (e.g., "256"), but may also be expressed in vCPUs (e.g.,
+// "0.25 vCPU"). An unrecognized value counts as zero.
+func parseFargateCPU(cpu string) int {
+	return parseFargateReservation(cpu, "vcpu", cpuUnitsPerVCPU)
+}
+
+// Convert a task's memory reservation into MiB. The reservation is normally
+// expressed in MiB (e.g., "512"), but may also be expressed in GB (e.g., "0.5 GB").
+// An unrecognized value counts as zero.
+func parseFargateMemory(memory string) int {
+	return parseFargateReservation(memory, "gb", 1024)
+}
+
+// Convert a reservation into base units. If the value ends with the supplied
+// (lowercase) suffix, the number is multiplied by unitsPerSuffix.
+func parseFargateReservation(value string, unitSuffix string, unitsPerSuffix float64) int {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	// Is the value expressed with a suffix?
+	multiplier := 1.0
+	if strings.HasSuffix(value, unitSuffix) {
+		value = strings.TrimSpace(strings.TrimSuffix(value, unitSuffix))
+		multiplier = unitsPerSuffix
+	}
+
+	// Convert the number
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+
+	return int(number * multiplier)
+}
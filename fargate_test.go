@@ -0,0 +1,189 @@
+/******************************************************************************
+Cloud Resource Counter
+File: fargate_test.go
+
+Summary: The Unit Test for fargate.
+******************************************************************************/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Fargate Task Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// Helper function to construct a task with the supplied launch type, status and reservations
+func taskWithReservations(taskArn string, launchType string, lastStatus string, cpu string, memory string) *ecs.Task {
+	return &ecs.Task{
+		TaskArn:    aws.String(taskArn),
+		LaunchType: aws.String(launchType),
+		LastStatus: aws.String(lastStatus),
+		Cpu:        aws.String(cpu),
+		Memory:     aws.String(memory),
+	}
+}
+
+// This is our map of regions and the tasks running in each
+var fargateTasksPerRegion = map[string]*ClusterInfo{
+	// US-EAST-1 has two clusters. The first has 2 running Fargate tasks (0.25 vCPU/512 MiB
+	// and 1 vCPU/2 GB) and an EC2 task (not counted). The second has 1 running Fargate task
+	// (0.5 vCPU/1024 MiB) and 1 Fargate task that is still provisioning (not counted).
+	"us-east-1": &ClusterInfo{
+		Tasks: map[string][]*ecs.Task{
+			"arn:aws:ecs:us-east-1:123456789012:cluster/web": []*ecs.Task{
+				taskWithReservations("task-1", ecs.LaunchTypeFargate, "RUNNING", "256", "512"),
+				taskWithReservations("task-2", ecs.LaunchTypeFargate, "RUNNING", "1 vCPU", "2 GB"),
+				taskWithReservations("task-3", ecs.LaunchTypeEc2, "RUNNING", "2048", "4096"),
+			},
+			"arn:aws:ecs:us-east-1:123456789012:cluster/batch": []*ecs.Task{
+				taskWithReservations("task-4", ecs.LaunchTypeFargate, "RUNNING", "512", "1024"),
+				taskWithReservations("task-5", ecs.LaunchTypeFargate, "PROVISIONING", "512", "1024"),
+			},
+		},
+	},
+	// US-EAST-2 has a single cluster with only EC2 tasks
+	"us-east-2": &ClusterInfo{
+		Tasks: map[string][]*ecs.Task{
+			"arn:aws:ecs:us-east-2:123456789012:cluster/legacy": []*ecs.Task{
+				taskWithReservations("task-6", ecs.LaunchTypeEc2, "RUNNING", "1024", "2048"),
+			},
+		},
+	},
+	// AF-SOUTH-1 has a single cluster with 1 large Fargate task (4 vCPU/8192 MiB)
+	"af-south-1": &ClusterInfo{
+		Tasks: map[string][]*ecs.Task{
+			"arn:aws:ecs:af-south-1:123456789012:cluster/analytics": []*ecs.Task{
+				taskWithReservations("task-7", ecs.LaunchTypeFargate, "RUNNING", "4096", "8192"),
+			},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeFargateServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Return our current region
+func (fsf fakeFargateServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeFargateServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Implement a way to return the ECS tasks found in a specific region
+func (fsf fakeFargateServiceFactory) GetContainerService(regionName string) *ContainerService {
+	// If the caller failed to specify a region, then use what is associated with our factory
+	var resolvedRegionName string
+	if regionName == "" {
+		resolvedRegionName = fsf.RegionName
+	} else {
+		resolvedRegionName = regionName
+	}
+
+	return &ContainerService{
+		Client: &fakeContainerService{
+			ClusterInfo: fargateTasksPerRegion[resolvedRegionName],
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for FargateTasks
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestFargateTasks(t *testing.T) {
+	// Describe all of our test cases: 1 failure and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts FargateCounts
+		ExpectedVCPUs  float64
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: FargateCounts{
+				Tasks:     3,
+				CPUUnits:  1792,
+				MemoryMiB: 3584,
+			},
+			ExpectedVCPUs: 1.75,
+		}, {
+			RegionName: "us-east-2",
+		}, {
+			RegionName: "af-south-1",
+			ExpectedCounts: FargateCounts{
+				Tasks:     1,
+				CPUUnits:  4096,
+				MemoryMiB: 8192,
+			},
+			ExpectedVCPUs: 4,
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions: true,
+			ExpectedCounts: FargateCounts{
+				Tasks:     4,
+				CPUUnits:  5888,
+				MemoryMiB: 11776,
+			},
+			ExpectedVCPUs: 5.75,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeFargateServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our Fargate Tasks function
+		actualCounts := FargateTasks(sf, mon, c.AllRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: FargateTasks returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if actualCounts.VCPUs() != c.ExpectedVCPUs {
+			t.Errorf("Error: FargateTasks returned %v vCPUs; expected %v", actualCounts.VCPUs(), c.ExpectedVCPUs)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
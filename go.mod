@@ -3,6 +3,7 @@ module github.com/expel-io/cloud-resource-counter
 go 1.15
 
 require (
-	github.com/aws/aws-sdk-go v1.43.0
+	github.com/aws/aws-sdk-go v1.44.133
+	github.com/jmespath/go-jmespath v0.4.0
 	github.com/logrusorgru/aurora v2.0.3+incompatible
 )
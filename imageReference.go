@@ -0,0 +1,202 @@
+/******************************************************************************
+Cloud Resource Counter
+File: imageReference.go
+
+Summary: Parses and canonicalizes container image references so that different
+         spellings of the same image are only counted once.
+******************************************************************************/
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The registry that Docker uses when an image reference does not name one.
+const defaultImageRegistry = "docker.io"
+
+// The tag that Docker uses when an image reference has neither a tag nor a digest.
+const defaultImageTag = "latest"
+
+// The registry of an account's (private) ECR repositories is named after the account
+// and region, such as "123456789012.dkr.ecr.us-east-1.amazonaws.com".
+var ecrRegistryRegex = regexp.MustCompile(`^([0-9]{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// ImageReference is a container image reference broken into its parts. For
+// example, "nginx" is parsed into the registry "docker.io", the repository
+// "library/nginx" and the tag "latest".
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference parses the supplied image reference (as found in an ECS
+// container definition) and canonicalizes its registry, repository, tag and
+// digest using the same defaults as the Docker CLI.
+func ParseImageReference(image string) ImageReference {
+	var ref ImageReference
+
+	// Remove any surrounding whitespace and URL scheme
+	remainder := strings.TrimSpace(image)
+	remainder = strings.TrimPrefix(remainder, "https://")
+	remainder = strings.TrimPrefix(remainder, "http://")
+
+	// Split off the digest (if any)
+	if at := strings.Index(remainder, "@"); at >= 0 {
+		ref.Digest = strings.ToLower(remainder[at+1:])
+		remainder = remainder[:at]
+	}
+
+	// Split off the tag (if any). It follows the last colon after the last slash,
+	// so that we don't confuse it with a registry port number.
+	if colon := strings.LastIndex(remainder, ":"); colon > strings.LastIndex(remainder, "/") {
+		ref.Tag = remainder[colon+1:]
+		remainder = remainder[:colon]
+	}
+
+	// Does the first component name a registry? It does if it looks like a hostname
+	// (contains a dot or a port) or is "localhost".
+	if slash := strings.Index(remainder, "/"); slash >= 0 {
+		firstComponent := remainder[:slash]
+		if strings.ContainsAny(firstComponent, ".:") || firstComponent == "localhost" {
+			ref.Registry = strings.ToLower(firstComponent)
+			remainder = remainder[slash+1:]
+		}
+	}
+	ref.Repository = strings.ToLower(remainder)
+
+	// Apply Docker Hub defaults
+	switch ref.Registry {
+	case "", "index.docker.io", "registry-1.docker.io":
+		ref.Registry = defaultImageRegistry
+	}
+	if ref.Registry == defaultImageRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+
+	// Without a tag or a digest, Docker pulls the "latest" tag
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = defaultImageTag
+	}
+
+	return ref
+}
+
+// Name returns the fully qualified repository name (registry and repository).
+func (ir ImageReference) Name() string {
+	return ir.Registry + "/" + ir.Repository
+}
+
+// IsECR checks whether the image is stored in an account's own (private) ECR
+// registry, rather than in a public or third-party registry.
+func (ir ImageReference) IsECR() bool {
+	_, _, ok := ir.ECRRegistry()
+	return ok
+}
+
+// ECRRegistry returns the account ID (registry ID) and region of the image's ECR
+// registry. The boolean result is false if the image is not stored in ECR.
+func (ir ImageReference) ECRRegistry() (string, string, bool) {
+	matches := ecrRegistryRegex.FindStringSubmatch(ir.Registry)
+	if matches == nil {
+		return "", "", false
+	}
+
+	return matches[1], matches[2], true
+}
+
+// String returns the canonical form of the image reference.
+func (ir ImageReference) String() string {
+	canonical := ir.Name()
+	if ir.Tag != "" {
+		canonical += ":" + ir.Tag
+	}
+	if ir.Digest != "" {
+		canonical += "@" + ir.Digest
+	}
+
+	return canonical
+}
+
+// ImageDedupeMode determines when two container images are considered the same.
+type ImageDedupeMode int
+
+const (
+	// DedupeByReference treats images as the same when their canonical
+	// references are identical (e.g., "nginx" and "docker.io/library/nginx:latest").
+	DedupeByReference ImageDedupeMode = iota
+
+	// DedupeRaw treats images as the same only when their image strings are
+	// identical.
+	DedupeRaw
+
+	// DedupeByRepository treats images from the same repository as the same,
+	// ignoring tags and digests.
+	DedupeByRepository
+
+	// DedupeByPinnedDigest treats images pinned to the same digest as the same. Only
+	// the digest written in the image reference is used: an image referenced by tag
+	// alone is not resolved to its digest (that would mean querying its registry), so
+	// it falls back to its canonical reference. A tag and a digest of the same image
+	// are therefore counted as two images.
+	DedupeByPinnedDigest
+
+	// DedupeByDigest treats images with the same digest as the same, like
+	// DedupeByPinnedDigest. In addition, the tag of an image stored in the account's
+	// own ECR registries is resolved to the digest that it points to (see
+	// UniqueContainerImages), so that a tag and its digest are counted once.
+	DedupeByDigest
+)
+
+// The names of each ImageDedupeMode (as supplied on the command line)
+var imageDedupeModeNames = map[ImageDedupeMode]string{
+	DedupeByReference:    "reference",
+	DedupeRaw:            "raw",
+	DedupeByRepository:   "repository",
+	DedupeByPinnedDigest: "pinned-digest",
+	DedupeByDigest:       "digest",
+}
+
+// ParseImageDedupeMode converts the supplied name into an ImageDedupeMode. The
+// boolean result is false if the name is not recognized.
+func ParseImageDedupeMode(name string) (ImageDedupeMode, bool) {
+	for mode, modeName := range imageDedupeModeNames {
+		if modeName == name {
+			return mode, true
+		}
+	}
+
+	return DedupeByReference, false
+}
+
+// String returns the name of the mode.
+func (mode ImageDedupeMode) String() string {
+	return imageDedupeModeNames[mode]
+}
+
+// Key returns the string used to decide whether the supplied image is the same
+// as another image.
+func (mode ImageDedupeMode) Key(image string) string {
+	// Do we want the image unaltered?
+	if mode == DedupeRaw {
+		return image
+	}
+
+	// Parse the image reference
+	ref := ParseImageReference(image)
+
+	// Switch on our mode
+	switch mode {
+	case DedupeByRepository:
+		return ref.Name()
+	case DedupeByPinnedDigest, DedupeByDigest:
+		if ref.Digest != "" {
+			return ref.Digest
+		}
+	}
+
+	return ref.String()
+}
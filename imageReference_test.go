@@ -0,0 +1,185 @@
+/******************************************************************************
+Cloud Resource Counter
+File: imageReference_test.go
+
+Summary: The Unit Test for imageReference.
+******************************************************************************/
+
+package main
+
+import (
+	"testing"
+)
+
+// A digest that we use repeatedly in our test cases
+const testImageDigest = "sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for ParseImageReference
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestParseImageReference(t *testing.T) {
+	// Describe all of our test cases
+	cases := []struct {
+		Image             string
+		ExpectedReference ImageReference
+		ExpectedCanonical string
+		ExpectedECR       bool
+	}{
+		{
+			Image: "nginx",
+			ExpectedReference: ImageReference{
+				Registry:   "docker.io",
+				Repository: "library/nginx",
+				Tag:        "latest",
+			},
+			ExpectedCanonical: "docker.io/library/nginx:latest",
+		}, {
+			Image: "docker.io/library/nginx:latest",
+			ExpectedReference: ImageReference{
+				Registry:   "docker.io",
+				Repository: "library/nginx",
+				Tag:        "latest",
+			},
+			ExpectedCanonical: "docker.io/library/nginx:latest",
+		}, {
+			Image: "registry-1.docker.io/bitnami/redis:7.0",
+			ExpectedReference: ImageReference{
+				Registry:   "docker.io",
+				Repository: "bitnami/redis",
+				Tag:        "7.0",
+			},
+			ExpectedCanonical: "docker.io/bitnami/redis:7.0",
+		}, {
+			Image: "nginx@" + testImageDigest,
+			ExpectedReference: ImageReference{
+				Registry:   "docker.io",
+				Repository: "library/nginx",
+				Digest:     testImageDigest,
+			},
+			ExpectedCanonical: "docker.io/library/nginx@" + testImageDigest,
+		}, {
+			Image: "nginx:1.25@" + testImageDigest,
+			ExpectedReference: ImageReference{
+				Registry:   "docker.io",
+				Repository: "library/nginx",
+				Tag:        "1.25",
+				Digest:     testImageDigest,
+			},
+			ExpectedCanonical: "docker.io/library/nginx:1.25@" + testImageDigest,
+		}, {
+			Image: "123456789012.dkr.ecr.us-east-1.amazonaws.com/team/service:v2",
+			ExpectedReference: ImageReference{
+				Registry:   "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+				Repository: "team/service",
+				Tag:        "v2",
+			},
+			ExpectedCanonical: "123456789012.dkr.ecr.us-east-1.amazonaws.com/team/service:v2",
+			ExpectedECR:       true,
+		}, {
+			Image: "localhost:5000/myimage",
+			ExpectedReference: ImageReference{
+				Registry:   "localhost:5000",
+				Repository: "myimage",
+				Tag:        "latest",
+			},
+			ExpectedCanonical: "localhost:5000/myimage:latest",
+		}, {
+			Image: "localhost/myimage:1",
+			ExpectedReference: ImageReference{
+				Registry:   "localhost",
+				Repository: "myimage",
+				Tag:        "1",
+			},
+			ExpectedCanonical: "localhost/myimage:1",
+		}, {
+			Image: "https://github.com/docker-library/mongo:4.0",
+			ExpectedReference: ImageReference{
+				Registry:   "github.com",
+				Repository: "docker-library/mongo",
+				Tag:        "4.0",
+			},
+			ExpectedCanonical: "github.com/docker-library/mongo:4.0",
+		}, {
+			Image: " Public.ECR.aws/Nginx/Nginx:Stable ",
+			ExpectedReference: ImageReference{
+				Registry:   "public.ecr.aws",
+				Repository: "nginx/nginx",
+				Tag:        "Stable",
+			},
+			ExpectedCanonical: "public.ecr.aws/nginx/nginx:Stable",
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Invoke our ParseImageReference function
+		actualReference := ParseImageReference(c.Image)
+
+		// Did we get what we expected?
+		if actualReference != c.ExpectedReference {
+			t.Errorf("Error: ParseImageReference(%q) returned %+v; expected %+v", c.Image, actualReference, c.ExpectedReference)
+		} else if actualReference.String() != c.ExpectedCanonical {
+			t.Errorf("Error: ParseImageReference(%q).String() returned %s; expected %s", c.Image, actualReference.String(), c.ExpectedCanonical)
+		} else if actualReference.IsECR() != c.ExpectedECR {
+			t.Errorf("Error: ParseImageReference(%q).IsECR() returned %v; expected %v", c.Image, actualReference.IsECR(), c.ExpectedECR)
+		}
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for ImageDedupeMode
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestImageDedupeModeKey(t *testing.T) {
+	// Describe all of our test cases
+	cases := []struct {
+		ModeName    string
+		Image       string
+		ExpectedKey string
+	}{
+		{
+			ModeName:    "raw",
+			Image:       "nginx",
+			ExpectedKey: "nginx",
+		}, {
+			ModeName:    "reference",
+			Image:       "nginx",
+			ExpectedKey: "docker.io/library/nginx:latest",
+		}, {
+			ModeName:    "repository",
+			Image:       "nginx:1.25@" + testImageDigest,
+			ExpectedKey: "docker.io/library/nginx",
+		}, {
+			ModeName:    "pinned-digest",
+			Image:       "nginx:1.25@" + testImageDigest,
+			ExpectedKey: testImageDigest,
+		}, {
+			ModeName:    "pinned-digest",
+			Image:       "nginx:1.25",
+			ExpectedKey: "docker.io/library/nginx:1.25",
+		}, {
+			ModeName:    "digest",
+			Image:       "nginx:1.25@" + testImageDigest,
+			ExpectedKey: testImageDigest,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Parse the mode
+		mode, ok := ParseImageDedupeMode(c.ModeName)
+		if !ok {
+			t.Errorf("Error: ParseImageDedupeMode did not recognize %s", c.ModeName)
+		} else if mode.String() != c.ModeName {
+			t.Errorf("Error: ImageDedupeMode.String() returned %s; expected %s", mode.String(), c.ModeName)
+		} else if actualKey := mode.Key(c.Image); actualKey != c.ExpectedKey {
+			t.Errorf("Error: %s Key(%q) returned %s; expected %s", c.ModeName, c.Image, actualKey, c.ExpectedKey)
+		}
+	}
+
+	// Ensure that an unknown mode is rejected
+	if _, ok := ParseImageDedupeMode("bingo-pajamas"); ok {
+		t.Error("Error: ParseImageDedupeMode recognized an invalid mode")
+	}
+}
@@ -0,0 +1,269 @@
+/******************************************************************************
+Cloud Resource Counter
+File: indexBackend.go
+
+Summary: Provides quick, approximate counts of resources by type from an index:
+         the AWS Resource Explorer index or, if it is not available, the
+         Resource Groups Tagging API.
+******************************************************************************/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/resourceexplorer2"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	color "github.com/logrusorgru/aurora"
+)
+
+// IndexColumn maps a Results column to the resource type that it counts (as named
+// by Resource Explorer and the Resource Groups Tagging API, such as "ec2:instance").
+type IndexColumn struct {
+	Name         string
+	ResourceType string
+
+	// Is this a global resource type? If so, it is not limited to the selected
+	// region.
+	Global bool
+}
+
+// IndexColumns lists the columns that are counted by the index backend. Each name
+// says that the count is "Indexed", so that it cannot be confused with the count of
+// the API backend. (For example, the index counts EC2 instances in every state,
+// not just those that are running.)
+var IndexColumns = []IndexColumn{
+	{Name: "# of EC2 Instances (Indexed)", ResourceType: "ec2:instance"},
+	{Name: "# of EBS Volumes (Indexed)", ResourceType: "ec2:volume"},
+	{Name: "# of ECR Repositories (Indexed)", ResourceType: "ecr:repository"},
+	{Name: "# of EKS Clusters (Indexed)", ResourceType: "eks:cluster"},
+	{Name: "# of Lambda Functions (Indexed)", ResourceType: "lambda:function"},
+	{Name: "# of Step Functions State Machines (Indexed)", ResourceType: "states:stateMachine"},
+	{Name: "# of SQS Queues (Indexed)", ResourceType: "sqs:queue"},
+	{Name: "# of SNS Topics (Indexed)", ResourceType: "sns:topic"},
+	{Name: "# of EventBridge Rules (Indexed)", ResourceType: "events:rule"},
+	{Name: "# of DynamoDB Tables (Indexed)", ResourceType: "dynamodb:table"},
+	{Name: "# of RDS Instances (Indexed)", ResourceType: "rds:db"},
+	{Name: "# of ElastiCache Clusters (Indexed)", ResourceType: "elasticache:cluster"},
+	{Name: "# of Redshift Clusters (Indexed)", ResourceType: "redshift:cluster"},
+	{Name: "# of OpenSearch Domains (Indexed)", ResourceType: "es:domain"},
+	{Name: "# of NAT Gateways (Indexed)", ResourceType: "ec2:natgateway"},
+	{Name: "# of Elastic IPs (Indexed)", ResourceType: "ec2:elastic-ip"},
+	{Name: "# of VPCs (Indexed)", ResourceType: "ec2:vpc"},
+	{Name: "# of Subnets (Indexed)", ResourceType: "ec2:subnet"},
+	{Name: "# of Transit Gateway Attachments (Indexed)", ResourceType: "ec2:transit-gateway-attachment"},
+	{Name: "# of VPC Endpoints (Indexed)", ResourceType: "ec2:vpc-endpoint"},
+	{Name: "# of S3 Buckets (Indexed)", ResourceType: "s3:bucket"},
+	{Name: "# of CloudFront Distributions (Indexed)", ResourceType: "cloudfront:distribution", Global: true},
+}
+
+// The sources of the indexed counts
+const (
+	ResourceExplorerSource = "AWS Resource Explorer"
+	TaggingAPISource       = "Resource Groups Tagging API"
+)
+
+// IndexCounts holds the count of each of the IndexColumns, along with the source of
+// the counts. If Resource Explorer could not be searched, then FallbackReason holds
+// the error that it returned. Resource Explorer stops counting at 1,000 resources,
+// so the columns that reached that limit (even after searching each region
+// separately) are listed as Incomplete: their counts are a lower bound.
+type IndexCounts struct {
+	Counts         []int
+	Source         string
+	FallbackReason error
+	Incomplete     []string
+}
+
+// IndexResources retrieves the approximate count of each of the IndexColumns either
+// for all regions (allRegions is true) or the region associated with the session.
+// It searches the Resource Explorer index (using the default view of the session's
+// region or, when counting all regions, of the region that holds the aggregator
+// index) and, if that fails, lists the resources of each region with the Resource
+// Groups Tagging API (which only knows about resources that have been tagged).
+//
+// This method gives status back to the user via the supplied ActivityMonitor
+// instance.
+func IndexResources(sf ServiceFactory, am ActivityMonitor, allRegions bool) IndexCounts {
+	// Indicate activity
+	am.StartAction("Retrieving indexed resource counts")
+
+	// Which regions are we counting?
+	var regionsSlice []string
+	if allRegions {
+		regionsSlice = GetEC2Regions(sf.GetEC2InstanceService(""), am)
+	} else {
+		regionsSlice = []string{sf.GetCurrentRegion()}
+	}
+
+	// Search the Resource Explorer index. If we can't, then fall back to the
+	// Resource Groups Tagging API.
+	var indexCounts IndexCounts
+	if res, err := resourceExplorerIndexService(sf, allRegions); err != nil {
+		indexCounts.FallbackReason = err
+	} else {
+		indexCounts = resourceExplorerCounts(res, am, allRegions, regionsSlice)
+	}
+	if indexCounts.FallbackReason != nil {
+		indexCounts.Counts = taggingAPICounts(sf, am, regionsSlice)
+		indexCounts.Source = TaggingAPISource
+		indexCounts.Incomplete = nil
+	}
+
+	// Indicate end of activity
+	total := 0
+	for _, count := range indexCounts.Counts {
+		total += count
+	}
+	am.EndAction("OK (%d resources, from %s)", color.Bold(total), indexCounts.Source)
+
+	return indexCounts
+}
+
+// Get the Resource Explorer service of the region whose index we search. A LOCAL index
+// only holds the resources of its own region (searching it for the resources of
+// another region finds none, yet reports a complete count), so to count all regions
+// we need the AGGREGATOR index. If the session's region does not hold it, then we
+// search the region that does. An error is returned if there is no suitable index.
+func resourceExplorerIndexService(sf ServiceFactory, allRegions bool) (*ResourceExplorerService, error) {
+	// Get the index of the session's region
+	res := sf.GetResourceExplorerService("")
+	index, err := res.GetIndex(&resourceexplorer2.GetIndexInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Is it enough for what we are counting?
+	if !allRegions || aws.StringValue(index.Type) == resourceexplorer2.IndexTypeAggregator {
+		return res, nil
+	}
+
+	// Find the region of the aggregator index (an account has at most one)
+	output, err := res.ListIndexes(&resourceexplorer2.ListIndexesInput{
+		Type: aws.String(resourceexplorer2.IndexTypeAggregator),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Indexes) == 0 {
+		return nil, fmt.Errorf("there is no %s index to search all regions (the index of this region is %s)",
+			resourceexplorer2.IndexTypeAggregator, aws.StringValue(index.Type))
+	}
+
+	return sf.GetResourceExplorerService(aws.StringValue(output.Indexes[0].Region)), nil
+}
+
+// Get the count of each column from Resource Explorer. If a search fails, then the
+// FallbackReason of the returned counts is set.
+func resourceExplorerCounts(res *ResourceExplorerService, am ActivityMonitor, allRegions bool, regionNames []string) IndexCounts {
+	indexCounts := IndexCounts{
+		Counts: make([]int, len(IndexColumns)),
+		Source: ResourceExplorerSource,
+	}
+
+	// Loop through the columns...
+	for index, column := range IndexColumns {
+		// Indicate activity
+		am.Message(".")
+
+		// Search for all resources of the type (or just those in our region)
+		var regionName string
+		if !allRegions && !column.Global {
+			regionName = regionNames[0]
+		}
+		count, complete, err := resourceExplorerCount(res, column.ResourceType, regionName)
+
+		// If the count is incomplete, then try again one region at a time
+		if err == nil && !complete && regionName == "" && !column.Global {
+			count, complete = 0, true
+			for _, regionName = range regionNames {
+				regionCount, regionComplete, regionErr := resourceExplorerCount(res, column.ResourceType, regionName)
+				if regionErr != nil {
+					err = regionErr
+					break
+				}
+				count += regionCount
+				complete = complete && regionComplete
+			}
+		}
+
+		// Did the search fail?
+		if err != nil {
+			indexCounts.FallbackReason = err
+			return indexCounts
+		}
+
+		// Record the count
+		indexCounts.Counts[index] = count
+		if !complete {
+			indexCounts.Incomplete = append(indexCounts.Incomplete, column.Name)
+		}
+	}
+
+	return indexCounts
+}
+
+// Search Resource Explorer for the resources of the supplied type (in the supplied
+// region, if any), returning their count and whether it is complete.
+func resourceExplorerCount(res *ResourceExplorerService, resourceType string, regionName string) (int, bool, error) {
+	// Construct the query
+	query := "resourcetype:" + resourceType
+	if regionName != "" {
+		query += " region:" + regionName
+	}
+
+	// We only need the count, not the resources themselves
+	output, err := res.Search(&resourceexplorer2.SearchInput{
+		QueryString: aws.String(query),
+		MaxResults:  aws.Int64(1),
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Did we get a count?
+	if output.Count == nil {
+		return len(output.Resources), false, nil
+	}
+
+	return int(aws.Int64Value(output.Count.TotalResources)), aws.BoolValue(output.Count.Complete), nil
+}
+
+// Get the count of each column from the Resource Groups Tagging API. The resources
+// of global types are listed in the default region. We stop at the first error.
+func taggingAPICounts(sf ServiceFactory, am ActivityMonitor, regionNames []string) []int {
+	counts := make([]int, len(IndexColumns))
+
+	// Loop through the columns...
+	for index, column := range IndexColumns {
+		// Which regions do we list?
+		columnRegions := regionNames
+		if column.Global {
+			columnRegions = []string{DefaultRegion}
+		}
+
+		// Loop through the regions...
+		for _, regionName := range columnRegions {
+			// Indicate activity
+			am.Message(".")
+
+			// List the resources of the type
+			input := &resourcegroupstaggingapi.GetResourcesInput{
+				ResourceTypeFilters: []*string{aws.String(column.ResourceType)},
+			}
+			err := sf.GetTaggingService(regionName).GetResources(input, func(page *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
+				counts[index] += len(page.ResourceTagMappingList)
+
+				return true
+			})
+
+			// Check for error
+			if am.CheckError(err) {
+				return counts
+			}
+		}
+	}
+
+	return counts
+}
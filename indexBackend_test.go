@@ -0,0 +1,405 @@
+/******************************************************************************
+Cloud Resource Counter
+File: indexBackend_test.go
+
+Summary: The Unit Test for indexBackend.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/resourceexplorer2"
+	"github.com/aws/aws-sdk-go/service/resourceexplorer2/resourceexplorer2iface"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Index Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This is our map of regions and the number of resources of each type that are in
+// the Resource Explorer index. (Global resources are in the "global" region.)
+var indexedResources = map[string]map[string]int{
+	"us-east-1": {
+		"ec2:instance":    3,
+		"lambda:function": 900,
+		"sns:topic":       1500,
+		"s3:bucket":       2,
+	},
+	"us-east-2": {
+		"ec2:instance":    1,
+		"lambda:function": 300,
+	},
+	"af-south-1": {
+		"sqs:queue": 4,
+	},
+	"global": {
+		"cloudfront:distribution": 2,
+	},
+}
+
+// This is our map of regions and the number of (tagged) resources of each type that
+// are listed by the Resource Groups Tagging API. A missing region simulates an error.
+var taggedResources = map[string]map[string]int{
+	"us-east-1": {
+		"ec2:instance":            2,
+		"lambda:function":         250,
+		"cloudfront:distribution": 1,
+	},
+	"us-east-2": {
+		"ec2:instance": 1,
+	},
+	"af-south-1": {},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Resource Explorer and Tagging Services
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This struct searches the index of a region. The index is the AGGREGATOR index if
+// the region is the AggregatorRegion; otherwise, it is a LOCAL index, which only
+// holds the resources of its own region. If the index is not available, then it
+// simulates an error.
+type fakeResourceExplorerService struct {
+	resourceexplorer2iface.ResourceExplorer2API
+	RegionName       string
+	AggregatorRegion string
+	Unavailable      bool
+}
+
+// Simulate the GetIndex function
+func (fake *fakeResourceExplorerService) GetIndex(input *resourceexplorer2.GetIndexInput) (*resourceexplorer2.GetIndexOutput, error) {
+	// Is the index available?
+	if fake.Unavailable {
+		return nil, errors.New("ResourceNotFoundException: Resource Explorer is not turned on in this region")
+	}
+
+	// Which type of index is it?
+	indexType := resourceexplorer2.IndexTypeLocal
+	if fake.RegionName == fake.AggregatorRegion {
+		indexType = resourceexplorer2.IndexTypeAggregator
+	}
+
+	return &resourceexplorer2.GetIndexOutput{
+		Type: aws.String(indexType),
+	}, nil
+}
+
+// Simulate the ListIndexes function (for the AGGREGATOR index only)
+func (fake *fakeResourceExplorerService) ListIndexes(input *resourceexplorer2.ListIndexesInput) (*resourceexplorer2.ListIndexesOutput, error) {
+	// We only support looking for the aggregator index
+	if aws.StringValue(input.Type) != resourceexplorer2.IndexTypeAggregator {
+		return nil, errors.New("ValidationException: unexpected index type")
+	}
+
+	// Is there an aggregator index?
+	output := &resourceexplorer2.ListIndexesOutput{}
+	if fake.AggregatorRegion != "" {
+		output.Indexes = []*resourceexplorer2.Index{
+			{
+				Region: aws.String(fake.AggregatorRegion),
+				Type:   aws.String(resourceexplorer2.IndexTypeAggregator),
+			},
+		}
+	}
+
+	return output, nil
+}
+
+// Simulate the Search function, which (like Resource Explorer) stops counting at
+// 1,000 resources
+func (fake *fakeResourceExplorerService) Search(input *resourceexplorer2.SearchInput) (*resourceexplorer2.SearchOutput, error) {
+	// Is the index available?
+	if fake.Unavailable {
+		return nil, errors.New("UnauthorizedException: Resource Explorer is not turned on in this region")
+	}
+
+	// Parse the query: a resource type and an optional region
+	var resourceType, regionName string
+	for _, term := range strings.Fields(aws.StringValue(input.QueryString)) {
+		if strings.HasPrefix(term, "resourcetype:") {
+			resourceType = strings.TrimPrefix(term, "resourcetype:")
+		} else if strings.HasPrefix(term, "region:") {
+			regionName = strings.TrimPrefix(term, "region:")
+		} else {
+			return nil, errors.New("ValidationException: unexpected query term: " + term)
+		}
+	}
+
+	// Count the matching resources (in the regions of our index)
+	var total int64
+	for region, resources := range indexedResources {
+		if fake.RegionName != fake.AggregatorRegion && region != fake.RegionName {
+			continue
+		}
+		if regionName == "" || regionName == region {
+			total += int64(resources[resourceType])
+		}
+	}
+
+	// Stop counting at 1,000
+	count := &resourceexplorer2.ResourceCount{
+		Complete:       aws.Bool(total <= 1000),
+		TotalResources: aws.Int64(total),
+	}
+	if total > 1000 {
+		count.TotalResources = aws.Int64(1000)
+	}
+
+	return &resourceexplorer2.SearchOutput{
+		Count: count,
+	}, nil
+}
+
+// This struct lists the tagged resources of a region. If the region's resources are
+// missing, it simulates an error.
+type fakeTaggingService struct {
+	resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+	Resources map[string]int
+}
+
+// Simulate the GetResourcesPages function (returning up to 100 resources a page)
+func (fake *fakeTaggingService) GetResourcesPages(input *resourcegroupstaggingapi.GetResourcesInput,
+	fn func(*resourcegroupstaggingapi.GetResourcesOutput, bool) bool) error {
+	// If the supplied resources are nil, then simulate an error
+	if fake.Resources == nil {
+		return errors.New("GetResources encountered an unexpected error: 1357")
+	}
+
+	// Return each page of the resources of the requested types
+	for _, resourceType := range input.ResourceTypeFilters {
+		remaining := fake.Resources[aws.StringValue(resourceType)]
+		for remaining > 0 {
+			pageSize := remaining
+			if pageSize > 100 {
+				pageSize = 100
+			}
+			remaining -= pageSize
+			if !fn(&resourcegroupstaggingapi.GetResourcesOutput{
+				ResourceTagMappingList: make([]*resourcegroupstaggingapi.ResourceTagMapping, pageSize),
+			}, remaining == 0) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeIndexBackendServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName         string
+	DRResponse         *ec2.DescribeRegionsOutput
+	NoResourceExplorer bool
+	AggregatorRegion   string
+}
+
+// Return our current region
+func (fsf fakeIndexBackendServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeIndexBackendServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return a ResourceExplorerService that searches the index of the supplied region
+// (if it is available)
+func (fsf fakeIndexBackendServiceFactory) GetResourceExplorerService(regionName string) *ResourceExplorerService {
+	if regionName == "" {
+		regionName = fsf.RegionName
+	}
+
+	return &ResourceExplorerService{
+		Client: &fakeResourceExplorerService{
+			RegionName:       regionName,
+			AggregatorRegion: fsf.AggregatorRegion,
+			Unavailable:      fsf.NoResourceExplorer,
+		},
+	}
+}
+
+// Return a TaggingService that lists the tagged resources of the supplied region
+func (fsf fakeIndexBackendServiceFactory) GetTaggingService(regionName string) *TaggingService {
+	if regionName == "" {
+		regionName = fsf.RegionName
+	}
+
+	return &TaggingService{
+		Client: &fakeTaggingService{
+			Resources: taggedResources[regionName],
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for IndexResources
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestIndexResources(t *testing.T) {
+	// Describe all of our test cases: 1 failure and 7 successes
+	cases := []struct {
+		RegionName         string
+		AllRegions         bool
+		NoResourceExplorer bool
+		AggregatorRegion   string
+		ExpectedCounts     map[string]int
+		ExpectedSource     string
+		ExpectedIncomplete []string
+		ExpectError        bool
+	}{
+		{
+			RegionName:       "us-east-1",
+			AggregatorRegion: "us-east-1",
+			ExpectedCounts: map[string]int{
+				"# of EC2 Instances (Indexed)":            3,
+				"# of Lambda Functions (Indexed)":         900,
+				"# of SNS Topics (Indexed)":               1000,
+				"# of S3 Buckets (Indexed)":               2,
+				"# of CloudFront Distributions (Indexed)": 2,
+			},
+			ExpectedSource:     ResourceExplorerSource,
+			ExpectedIncomplete: []string{"# of SNS Topics (Indexed)"},
+		}, {
+			RegionName:       "us-east-1",
+			AllRegions:       true,
+			AggregatorRegion: "us-east-1",
+			ExpectedCounts: map[string]int{
+				"# of EC2 Instances (Indexed)":            4,
+				"# of Lambda Functions (Indexed)":         1200,
+				"# of SNS Topics (Indexed)":               1000,
+				"# of SQS Queues (Indexed)":               4,
+				"# of S3 Buckets (Indexed)":               2,
+				"# of CloudFront Distributions (Indexed)": 2,
+			},
+			ExpectedSource:     ResourceExplorerSource,
+			ExpectedIncomplete: []string{"# of SNS Topics (Indexed)"},
+		}, {
+			// The session's region holds a LOCAL index, so we search the aggregator index
+			RegionName:       "us-east-2",
+			AllRegions:       true,
+			AggregatorRegion: "us-east-1",
+			ExpectedCounts: map[string]int{
+				"# of EC2 Instances (Indexed)":            4,
+				"# of Lambda Functions (Indexed)":         1200,
+				"# of SNS Topics (Indexed)":               1000,
+				"# of SQS Queues (Indexed)":               4,
+				"# of S3 Buckets (Indexed)":               2,
+				"# of CloudFront Distributions (Indexed)": 2,
+			},
+			ExpectedSource:     ResourceExplorerSource,
+			ExpectedIncomplete: []string{"# of SNS Topics (Indexed)"},
+		}, {
+			// A LOCAL index is enough to count its own region
+			RegionName: "us-east-2",
+			ExpectedCounts: map[string]int{
+				"# of EC2 Instances (Indexed)":    1,
+				"# of Lambda Functions (Indexed)": 300,
+			},
+			ExpectedSource: ResourceExplorerSource,
+		}, {
+			// There is no aggregator index, so we fall back to the Tagging API
+			RegionName: "us-east-1",
+			AllRegions: true,
+			ExpectedCounts: map[string]int{
+				"# of EC2 Instances (Indexed)":            3,
+				"# of Lambda Functions (Indexed)":         250,
+				"# of CloudFront Distributions (Indexed)": 1,
+			},
+			ExpectedSource: TaggingAPISource,
+		}, {
+			AllRegions:         true,
+			NoResourceExplorer: true,
+			ExpectedCounts: map[string]int{
+				"# of EC2 Instances (Indexed)":            3,
+				"# of Lambda Functions (Indexed)":         250,
+				"# of CloudFront Distributions (Indexed)": 1,
+			},
+			ExpectedSource: TaggingAPISource,
+		}, {
+			RegionName:         "us-east-2",
+			NoResourceExplorer: true,
+			ExpectedCounts: map[string]int{
+				"# of EC2 Instances (Indexed)":            1,
+				"# of CloudFront Distributions (Indexed)": 1,
+			},
+			ExpectedSource: TaggingAPISource,
+		}, {
+			RegionName:         "undefined-region",
+			NoResourceExplorer: true,
+			ExpectError:        true,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeIndexBackendServiceFactory{
+			RegionName:         c.RegionName,
+			DRResponse:         ec2Regions,
+			NoResourceExplorer: c.NoResourceExplorer,
+			AggregatorRegion:   c.AggregatorRegion,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our IndexResources function
+		actualCounts := IndexResources(sf, mon, c.AllRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+			continue
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+			continue
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+			continue
+		}
+
+		// Convert the counts into a map of the (non-zero) columns
+		actualMap := make(map[string]int)
+		for index, count := range actualCounts.Counts {
+			if count != 0 {
+				actualMap[IndexColumns[index].Name] = count
+			}
+		}
+
+		// Do they match?
+		if !reflect.DeepEqual(actualMap, c.ExpectedCounts) {
+			t.Errorf("Error: IndexResources returned %v; expected %v", actualMap, c.ExpectedCounts)
+		} else if actualCounts.Source != c.ExpectedSource {
+			t.Errorf("Error: IndexResources used %s; expected %s", actualCounts.Source, c.ExpectedSource)
+		} else if (actualCounts.FallbackReason != nil) != (c.ExpectedSource == TaggingAPISource) {
+			t.Errorf("Error: IndexResources returned an unexpected fallback reason: %v", actualCounts.FallbackReason)
+		} else if !reflect.DeepEqual(actualCounts.Incomplete, c.ExpectedIncomplete) {
+			t.Errorf("Error: IndexResources returned incomplete columns %v; expected %v", actualCounts.Incomplete, c.ExpectedIncomplete)
+		}
+	}
+}
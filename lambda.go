@@ -2,27 +2,154 @@
 Cloud Resource Counter
 File: lambda.go
 
-Summary: Provides a count of all Lambda functions.
+Summary: Provides a count of all Lambda functions, broken down by runtime and
+         architecture.
 ******************************************************************************/
 
 package main
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/lambda"
 
 	color "github.com/logrusorgru/aurora"
 )
 
+// LambdaRuntimeFamilies lists the runtime families that we report on, in the order
+// in which they are reported. Functions packaged as container images have no runtime
+// and are counted as "image". Any other runtime is counted as "other".
+var LambdaRuntimeFamilies = []string{
+	"nodejs",
+	"python",
+	"java",
+	"dotnet",
+	"ruby",
+	"go",
+	"provided",
+	"image",
+	"other",
+}
+
+// LambdaArchitectures lists the instruction set architectures that we report on.
+var LambdaArchitectures = []string{
+	lambda.ArchitectureX8664,
+	lambda.ArchitectureArm64,
+}
+
+// LambdaDeprecatedRuntimes is the set of runtimes that AWS has deprecated (and for
+// which security patches are no longer applied). This list needs to be updated as
+// AWS deprecates further runtimes.
+var LambdaDeprecatedRuntimes = map[string]bool{
+	"dotnet5.0":      true,
+	"dotnet6":        true,
+	"dotnet7":        true,
+	"dotnetcore1.0":  true,
+	"dotnetcore2.0":  true,
+	"dotnetcore2.1":  true,
+	"dotnetcore3.1":  true,
+	"go1.x":          true,
+	"java8":          true,
+	"nodejs":         true,
+	"nodejs4.3":      true,
+	"nodejs4.3-edge": true,
+	"nodejs6.10":     true,
+	"nodejs8.10":     true,
+	"nodejs10.x":     true,
+	"nodejs12.x":     true,
+	"nodejs14.x":     true,
+	"nodejs16.x":     true,
+	"nodejs18.x":     true,
+	"provided":       true,
+	"python2.7":      true,
+	"python3.6":      true,
+	"python3.7":      true,
+	"python3.8":      true,
+	"python3.9":      true,
+	"ruby2.5":        true,
+	"ruby2.7":        true,
+}
+
+// The version that ListFunctions reports for the unpublished version of a function
+const lambdaLatestVersion = "$LATEST"
+
+// LambdaCounts holds the number of Lambda functions (and, optionally, published
+// versions). The functions are broken down by runtime family (see
+// LambdaRuntimeFamilies), by runtime (such as "python3.12"; functions packaged as
+// container images have none) and architecture (see LambdaArchitectures); published
+// versions are not included in these breakdowns.
+type LambdaCounts struct {
+	Functions          int
+	Versions           int
+	DeprecatedRuntimes int
+	Runtimes           map[string]int
+	RuntimeVersions    map[string]int
+	Architectures      map[string]int
+}
+
+// Add the supplied counts into our struct.
+func (lc *LambdaCounts) Add(other LambdaCounts) {
+	lc.Functions += other.Functions
+	lc.Versions += other.Versions
+	lc.DeprecatedRuntimes += other.DeprecatedRuntimes
+
+	// Merge the runtime and architecture counts
+	for family, count := range other.Runtimes {
+		if lc.Runtimes == nil {
+			lc.Runtimes = make(map[string]int)
+		}
+		lc.Runtimes[family] += count
+	}
+	for runtime, count := range other.RuntimeVersions {
+		if lc.RuntimeVersions == nil {
+			lc.RuntimeVersions = make(map[string]int)
+		}
+		lc.RuntimeVersions[runtime] += count
+	}
+	for arch, count := range other.Architectures {
+		if lc.Architectures == nil {
+			lc.Architectures = make(map[string]int)
+		}
+		lc.Architectures[arch] += count
+	}
+}
+
+// RuntimeVersionSummary returns the number of functions on each runtime as a single
+// value, such as "nodejs20.x=3; python3.12=2; python3.8=1" (ordered by runtime name).
+// As the runtimes differ from account to account, they are stored in one column so
+// that the columns of our results do not change.
+func (lc LambdaCounts) RuntimeVersionSummary() string {
+	// Sort the runtimes
+	var runtimes []string
+	for runtime := range lc.RuntimeVersions {
+		runtimes = append(runtimes, runtime)
+	}
+	sort.Strings(runtimes)
+
+	// Build the summary
+	var summary []string
+	for _, runtime := range runtimes {
+		summary = append(summary, fmt.Sprintf("%s=%d", runtime, lc.RuntimeVersions[runtime]))
+	}
+
+	return strings.Join(summary, "; ")
+}
+
 // LambdaFunctions retrieves the count of all lambda function
 // either for all regions (allRegions is true) or the region
-// associated with the session.  This method gives status back
-// to the user via the supplied ActivityMonitor instance.
-func LambdaFunctions(sf ServiceFactory, am ActivityMonitor, allRegions bool) int {
+// associated with the session. If includeVersions is true, then
+// the published versions of each function are counted as well.
+// This method gives status back to the user via the supplied
+// ActivityMonitor instance.
+func LambdaFunctions(sf ServiceFactory, am ActivityMonitor, allRegions bool, includeVersions bool) LambdaCounts {
 	// Indicate activity
 	am.StartAction("Retrieving Lambda function counts")
 
 	// Should we get the counts for all regions?
-	instanceCount := 0
+	var functionCounts LambdaCounts
 	if allRegions {
 		// Get the list of all enabled regions for this account
 		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
@@ -30,30 +157,68 @@ func LambdaFunctions(sf ServiceFactory, am ActivityMonitor, allRegions bool) int
 		// Loop through all of the regions
 		for _, regionName := range regionsSlice {
 			// Get the Lambda counts for a specific region
-			instanceCount += lambdaFunctionsForSingleRegion(sf.GetLambdaService(regionName), am)
+			functionCounts.Add(lambdaFunctionsForSingleRegion(sf.GetLambdaService(regionName), am, includeVersions))
 		}
 	} else {
 		// Get the Lambda counts for the region selected by this session
-		instanceCount = lambdaFunctionsForSingleRegion(sf.GetLambdaService(""), am)
+		functionCounts = lambdaFunctionsForSingleRegion(sf.GetLambdaService(""), am, includeVersions)
 	}
 
 	// Indicate end of activity
-	am.EndAction("OK (%d)", color.Bold(instanceCount))
+	if includeVersions {
+		am.EndAction("OK (%d, %d versions)", color.Bold(functionCounts.Functions), color.Bold(functionCounts.Versions))
+	} else {
+		am.EndAction("OK (%d)", color.Bold(functionCounts.Functions))
+	}
 
-	return instanceCount
+	return functionCounts
 }
 
-func lambdaFunctionsForSingleRegion(ls *LambdaService, am ActivityMonitor) int {
+func lambdaFunctionsForSingleRegion(ls *LambdaService, am ActivityMonitor, includeVersions bool) LambdaCounts {
 	// Construct our input to find all Lambda instances
 	input := &lambda.ListFunctionsInput{}
+	if includeVersions {
+		input.FunctionVersion = aws.String(lambda.FunctionVersionAll)
+	}
 
 	// Indicate activity
 	am.Message(".")
 
 	// Invoke our service
-	functionCounts := 0
+	functionCounts := LambdaCounts{
+		Runtimes:        make(map[string]int),
+		RuntimeVersions: make(map[string]int),
+		Architectures:   make(map[string]int),
+	}
 	err := ls.ListFunctions(input, func(page *lambda.ListFunctionsOutput, lastPage bool) bool {
-		functionCounts += len(page.Functions)
+		for _, fc := range page.Functions {
+			// Is this a published version (rather than the function itself)?
+			if version := aws.StringValue(fc.Version); version != "" && version != lambdaLatestVersion {
+				functionCounts.Versions++
+				continue
+			}
+			functionCounts.Functions++
+
+			// Count the function by runtime
+			runtime := aws.StringValue(fc.Runtime)
+			if aws.StringValue(fc.PackageType) == lambda.PackageTypeImage {
+				functionCounts.Runtimes["image"]++
+			} else {
+				functionCounts.Runtimes[lambdaRuntimeFamily(runtime)]++
+				functionCounts.RuntimeVersions[runtime]++
+			}
+			if LambdaDeprecatedRuntimes[runtime] {
+				functionCounts.DeprecatedRuntimes++
+			}
+
+			// Count the function by architecture (which defaults to x86_64)
+			if len(fc.Architectures) == 0 {
+				functionCounts.Architectures[lambda.ArchitectureX8664]++
+			}
+			for _, arch := range fc.Architectures {
+				functionCounts.Architectures[aws.StringValue(arch)]++
+			}
+		}
 
 		return true
 	})
@@ -63,3 +228,26 @@ func lambdaFunctionsForSingleRegion(ls *LambdaService, am ActivityMonitor) int {
 
 	return functionCounts
 }
+
+// Convert a Lambda runtime (e.g., "python3.12" or "provided.al2") into one of our
+// runtime families.
+func lambdaRuntimeFamily(runtime string) string {
+	switch {
+	case strings.HasPrefix(runtime, "nodejs"):
+		return "nodejs"
+	case strings.HasPrefix(runtime, "python"):
+		return "python"
+	case strings.HasPrefix(runtime, "java"):
+		return "java"
+	case strings.HasPrefix(runtime, "dotnet"):
+		return "dotnet"
+	case strings.HasPrefix(runtime, "ruby"):
+		return "ruby"
+	case strings.HasPrefix(runtime, "go"):
+		return "go"
+	case strings.HasPrefix(runtime, "provided"):
+		return "provided"
+	default:
+		return "other"
+	}
+}
@@ -9,8 +9,10 @@ package main
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
@@ -70,6 +72,41 @@ var lambdaFnsPerRegion = map[string][]*lambda.ListFunctionsOutput{
 			},
 		},
 	},
+	// EU-WEST-1 (which is not one of our enabled regions) describes its functions
+	// in detail: 5 functions (2 on deprecated runtimes, 1 packaged as an image) and
+	// 3 published versions.
+	"eu-west-1": []*lambda.ListFunctionsOutput{
+		&lambda.ListFunctionsOutput{
+			Functions: []*lambda.FunctionConfiguration{
+				lambdaFunction("$LATEST", "nodejs20.x", "arm64"),
+				lambdaFunction("1", "nodejs20.x", "arm64"),
+				lambdaFunction("2", "nodejs20.x", "arm64"),
+				lambdaFunction("$LATEST", "python3.7"),
+				lambdaFunction("$LATEST", "go1.x", "x86_64"),
+			},
+		},
+		&lambda.ListFunctionsOutput{
+			Functions: []*lambda.FunctionConfiguration{
+				lambdaFunction("$LATEST", "provided.al2023", "arm64"),
+				lambdaFunction("1", "provided.al2023", "arm64"),
+				&lambda.FunctionConfiguration{
+					Version:       aws.String("$LATEST"),
+					PackageType:   aws.String("Image"),
+					Architectures: aws.StringSlice([]string{"x86_64"}),
+				},
+			},
+		},
+	},
+}
+
+// Helper function to construct a (zip-packaged) function configuration
+func lambdaFunction(version string, runtime string, architectures ...string) *lambda.FunctionConfiguration {
+	return &lambda.FunctionConfiguration{
+		Version:       aws.String(version),
+		Runtime:       aws.String(runtime),
+		PackageType:   aws.String("Zip"),
+		Architectures: aws.StringSlice(architectures),
+	}
 }
 
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -92,11 +129,14 @@ func (fake *fakeLambdaService) ListFunctionsPages(input *lambda.ListFunctionsInp
 	}
 
 	// Apply filtering to the supplied response
-	// NOTE: I have not implemented this feature as our code does not require it.
-	// To prevent unexpected cases, if the caller supplies an input other then
-	// the "zero" input, the unit test fails.
-	if input.FunctionVersion != nil || input.Marker != nil || input.MasterRegion != nil || input.MaxItems != nil {
-		return errors.New("The unit test does not support a ListFunctionsInput other than 'zero' (no parameters)")
+	// NOTE: I have only implemented the FunctionVersion (ALL) parameter as our code
+	// does not require the others. To prevent unexpected cases, if the caller supplies
+	// any other parameter, the unit test fails.
+	if input.Marker != nil || input.MasterRegion != nil || input.MaxItems != nil {
+		return errors.New("The unit test does not support a ListFunctionsInput other than FunctionVersion")
+	}
+	if input.FunctionVersion != nil && *input.FunctionVersion != lambda.FunctionVersionAll {
+		return errors.New("The unit test only supports a FunctionVersion of ALL")
 	}
 
 	// Loop through the slice of responses, invoking the supplied function
@@ -104,6 +144,17 @@ func (fake *fakeLambdaService) ListFunctionsPages(input *lambda.ListFunctionsInp
 		// Are we looking at the last "page" of our output?
 		lastPage := index == len(fake.LFOResponse)-1
 
+		// Unless all versions were requested, remove the published versions
+		if input.FunctionVersion == nil {
+			filteredOutput := &lambda.ListFunctionsOutput{}
+			for _, fc := range output.Functions {
+				if fc.Version == nil || *fc.Version == lambdaLatestVersion {
+					filteredOutput.Functions = append(filteredOutput.Functions, fc)
+				}
+			}
+			output = filteredOutput
+		}
+
 		// Invoke our fn
 		cont := fn(output, lastPage)
 
@@ -123,23 +174,16 @@ func (fake *fakeLambdaService) ListFunctionsPages(input *lambda.ListFunctionsInp
 // This structure simulates the AWS Service Factory by storing some pregenerated
 // responses (that would come from AWS).
 type fakeLambdaServiceFactory struct {
+	baseFakeServiceFactory
 	RegionName string
 	DRResponse *ec2.DescribeRegionsOutput
 }
 
-// Don't need to implement
-func (fsf fakeLambdaServiceFactory) Init() {}
-
 // Return our current region
 func (fsf fakeLambdaServiceFactory) GetCurrentRegion() string {
 	return fsf.RegionName
 }
 
-// Don't need to implement
-func (fsf fakeLambdaServiceFactory) GetAccountIDService() *AccountIDService {
-	return nil
-}
-
 // This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
 // only.
 func (fsf fakeLambdaServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
@@ -150,16 +194,6 @@ func (fsf fakeLambdaServiceFactory) GetEC2InstanceService(string) *EC2InstanceSe
 	}
 }
 
-// Don't need to implement
-func (fsf fakeLambdaServiceFactory) GetRDSInstanceService(string) *RDSInstanceService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeLambdaServiceFactory) GetS3Service() *S3Service {
-	return nil
-}
-
 // Return a specialize LambdaService that returns a pre-canned response
 func (fsf fakeLambdaServiceFactory) GetLambdaService(regionName string) *LambdaService {
 	// If the caller failed to specify a region, then use what is associated with our factory
@@ -177,16 +211,6 @@ func (fsf fakeLambdaServiceFactory) GetLambdaService(regionName string) *LambdaS
 	}
 }
 
-// Don't need to implement
-func (fsf fakeLambdaServiceFactory) GetContainerService(string) *ContainerService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeLambdaServiceFactory) GetLightsailService(string) *LightsailService {
-	return nil
-}
-
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 // Unit Test for LambdaFunctions
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -229,7 +253,7 @@ func TestLambdaFunctions(t *testing.T) {
 		mon := &mock.ActivityMonitorImpl{}
 
 		// Invoke our Lambda Functions function
-		actualCount := LambdaFunctions(sf, mon, c.AllRegions)
+		actualCount := LambdaFunctions(sf, mon, c.AllRegions, false).Functions
 
 		// Did we expect an error?
 		if c.ExpectError {
@@ -246,3 +270,108 @@ func TestLambdaFunctions(t *testing.T) {
 		}
 	}
 }
+
+func TestLambdaFunctionDetail(t *testing.T) {
+	// The runtimes of the functions (in either case)
+	const expectedRuntimeSummary = "go1.x=1; nodejs20.x=1; provided.al2023=1; python3.7=1"
+
+	// Describe all of our test cases: 2 success cases
+	cases := []struct {
+		IncludeVersions bool
+		ExpectedCounts  LambdaCounts
+	}{
+		{
+			ExpectedCounts: LambdaCounts{
+				Functions:          5,
+				DeprecatedRuntimes: 2,
+				Runtimes: map[string]int{
+					"nodejs":   1,
+					"python":   1,
+					"go":       1,
+					"provided": 1,
+					"image":    1,
+				},
+				RuntimeVersions: map[string]int{
+					"nodejs20.x":      1,
+					"python3.7":       1,
+					"go1.x":           1,
+					"provided.al2023": 1,
+				},
+				Architectures: map[string]int{
+					"x86_64": 3,
+					"arm64":  2,
+				},
+			},
+		}, {
+			IncludeVersions: true,
+			ExpectedCounts: LambdaCounts{
+				Functions:          5,
+				Versions:           3,
+				DeprecatedRuntimes: 2,
+				Runtimes: map[string]int{
+					"nodejs":   1,
+					"python":   1,
+					"go":       1,
+					"provided": 1,
+					"image":    1,
+				},
+				RuntimeVersions: map[string]int{
+					"nodejs20.x":      1,
+					"python3.7":       1,
+					"go1.x":           1,
+					"provided.al2023": 1,
+				},
+				Architectures: map[string]int{
+					"x86_64": 3,
+					"arm64":  2,
+				},
+			},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeLambdaServiceFactory{
+			RegionName: "eu-west-1",
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our Lambda Functions function
+		actualCounts := LambdaFunctions(sf, mon, false, c.IncludeVersions)
+
+		// Check the result
+		if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if !reflect.DeepEqual(actualCounts, c.ExpectedCounts) {
+			t.Errorf("Error: LambdaFunctions returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if summary := actualCounts.RuntimeVersionSummary(); summary != expectedRuntimeSummary {
+			t.Errorf("Error: RuntimeVersionSummary returned %q; expected %q", summary, expectedRuntimeSummary)
+		}
+	}
+}
+
+func TestLambdaRuntimeFamily(t *testing.T) {
+	// Describe all of our test cases
+	cases := map[string]string{
+		"nodejs20.x":      "nodejs",
+		"python3.12":      "python",
+		"java21":          "java",
+		"dotnet8":         "dotnet",
+		"dotnetcore3.1":   "dotnet",
+		"ruby3.3":         "ruby",
+		"go1.x":           "go",
+		"provided.al2023": "provided",
+		"":                "other",
+		"cobol":           "other",
+	}
+
+	for runtime, expectedFamily := range cases {
+		if actualFamily := lambdaRuntimeFamily(runtime); actualFamily != expectedFamily {
+			t.Errorf("Error: lambdaRuntimeFamily(%q) returned %s; expected %s", runtime, actualFamily, expectedFamily)
+		}
+	}
+}
@@ -2,21 +2,40 @@
 Cloud Resource Counter
 File: lightsail.go
 
-Summary: Counts the number of Lightsail instances.
+Summary: Counts the number of Lightsail instances, databases, container
+         services and load balancers.
 ******************************************************************************/
 
 package main
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/lightsail"
 	color "github.com/logrusorgru/aurora"
 )
 
-// LightsailInstances returns a count of Lightsail instances in the current region
+// LightsailCounts holds the number of (running) Lightsail instances along with the
+// number of Lightsail databases, container services and load balancers.
+type LightsailCounts struct {
+	Instances         int
+	Databases         int
+	ContainerServices int
+	LoadBalancers     int
+}
+
+// Add the supplied counts into our struct.
+func (lc *LightsailCounts) Add(other LightsailCounts) {
+	lc.Instances += other.Instances
+	lc.Databases += other.Databases
+	lc.ContainerServices += other.ContainerServices
+	lc.LoadBalancers += other.LoadBalancers
+}
+
+// LightsailResources returns the counts of Lightsail resources in the current region
 // (allRegions = false) or for all regions (allRegions = true)
-func LightsailInstances(sf ServiceFactory, am ActivityMonitor, allRegions bool) int {
+func LightsailResources(sf ServiceFactory, am ActivityMonitor, allRegions bool) LightsailCounts {
 	// Indicate activity
-	am.StartAction("Retrieving Lightsail instance counts")
+	am.StartAction("Retrieving Lightsail resource counts")
 
 	// Input for the list of regions...
 	input := &lightsail.GetRegionsInput{}
@@ -29,16 +48,16 @@ func LightsailInstances(sf ServiceFactory, am ActivityMonitor, allRegions bool)
 
 	// If error, then get out now!
 	if am.CheckError(err) {
-		return 0
+		return LightsailCounts{}
 	}
 
 	// Should we get the counts for all regions?
-	instanceCount := 0
+	var lightsailCounts LightsailCounts
 	if allRegions {
 		// Loop through all of the regions
 		for _, region := range response.Regions {
-			// Get the Lightsail instances counts for a specific region
-			instanceCount += lightsailInstancesForSingleRegion(sf.GetLightsailService(*region.Name), am)
+			// Get the Lightsail counts for a specific region
+			lightsailCounts.Add(lightsailResourcesForSingleRegion(sf.GetLightsailService(*region.Name), am))
 		}
 	} else {
 		// Is the current region supported by Lightsail?
@@ -50,40 +69,65 @@ func LightsailInstances(sf ServiceFactory, am ActivityMonitor, allRegions bool)
 		}
 
 		if validLightsailRegion {
-			// Get the Lightsail instances counts for the region selected by this session
-			instanceCount = lightsailInstancesForSingleRegion(sf.GetLightsailService(""), am)
+			// Get the Lightsail counts for the region selected by this session
+			lightsailCounts = lightsailResourcesForSingleRegion(sf.GetLightsailService(""), am)
 		}
 	}
 
 	// Indicate end of activity
-	am.EndAction("OK (%d)", color.Bold(instanceCount))
+	am.EndAction("OK (%d instances, %d databases, %d container services, %d load balancers)",
+		color.Bold(lightsailCounts.Instances), color.Bold(lightsailCounts.Databases),
+		color.Bold(lightsailCounts.ContainerServices), color.Bold(lightsailCounts.LoadBalancers))
 
-	return instanceCount
+	return lightsailCounts
 }
 
-func lightsailInstancesForSingleRegion(lss *LightsailService, am ActivityMonitor) int {
-	// Construct our input to find all Lightsail instances
-	input := &lightsail.GetInstancesInput{}
-
+// Get the Lightsail counts for a single region. We stop at the first error.
+func lightsailResourcesForSingleRegion(lss *LightsailService, am ActivityMonitor) LightsailCounts {
 	// Indicate activity
 	am.Message(".")
 
-	// Invoke our service
-	response, err := lss.InspectInstances(input)
+	var lightsailCounts LightsailCounts
+
+	// Count the running instances
+	err := lss.InspectInstances(&lightsail.GetInstancesInput{}, func(page *lightsail.GetInstancesOutput, lastPage bool) bool {
+		for _, inst := range page.Instances {
+			// Is the instance running?
+			if inst.State != nil && aws.StringValue(inst.State.Name) == "running" {
+				lightsailCounts.Instances++
+			}
+		}
+
+		return true
+	})
+	if am.CheckError(err) {
+		return lightsailCounts
+	}
+
+	// Count the databases
+	err = lss.InspectRelationalDatabases(&lightsail.GetRelationalDatabasesInput{}, func(page *lightsail.GetRelationalDatabasesOutput, lastPage bool) bool {
+		lightsailCounts.Databases += len(page.RelationalDatabases)
 
-	// Check for error
+		return true
+	})
 	if am.CheckError(err) {
-		return 0
+		return lightsailCounts
 	}
 
-	// Loop through the instances...
-	var instanceCount int
-	for _, inst := range response.Instances {
-		// Is the instance running?
-		if inst.State != nil && inst.State.Name != nil && *inst.State.Name == "running" {
-			instanceCount++
-		}
+	// Count the container services
+	containerServices, err := lss.InspectContainerServices(&lightsail.GetContainerServicesInput{})
+	if am.CheckError(err) {
+		return lightsailCounts
 	}
+	lightsailCounts.ContainerServices = len(containerServices.ContainerServices)
+
+	// Count the load balancers
+	err = lss.InspectLoadBalancers(&lightsail.GetLoadBalancersInput{}, func(page *lightsail.GetLoadBalancersOutput, lastPage bool) bool {
+		lightsailCounts.LoadBalancers += len(page.LoadBalancers)
+
+		return true
+	})
+	am.CheckError(err)
 
-	return instanceCount
+	return lightsailCounts
 }
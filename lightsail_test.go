@@ -36,48 +36,186 @@ var lightsailRegions *lightsail.GetRegionsOutput = &lightsail.GetRegionsOutput{
 	},
 }
 
-// This is our list of lightsail instances per region
-var lightsailInstancesPerRegion = map[string]*lightsail.GetInstancesOutput{
-	// US-EAST-1 simulates a region where there are three Lightsail instances:
-	// one is Wordpress, one is Magento (but it is stopped) and the other is Node.js.
-	"us-east-1": &lightsail.GetInstancesOutput{
-		Instances: []*lightsail.Instance{
-			&lightsail.Instance{
-				Name: aws.String("WordPress-1"),
-				State: &lightsail.InstanceState{
-					Name: aws.String("running"),
+// This is our list of accessible regions that includes a region whose resources
+// cannot all be retrieved.
+var lightsailRegionsWithError *lightsail.GetRegionsOutput = &lightsail.GetRegionsOutput{
+	Regions: []*lightsail.Region{
+		&lightsail.Region{
+			Name: aws.String("us-east-1"),
+		},
+		&lightsail.Region{
+			Name: aws.String("ca-central-1"),
+		},
+	},
+}
+
+// LightsailRegionInfo holds the pages of each kind of Lightsail resource in a region.
+// If a field is nil, then retrieving that kind of resource simulates an error.
+type LightsailRegionInfo struct {
+	InstancePages     []*lightsail.GetInstancesOutput
+	DatabasePages     []*lightsail.GetRelationalDatabasesOutput
+	ContainerServices *lightsail.GetContainerServicesOutput
+	LoadBalancerPages []*lightsail.GetLoadBalancersOutput
+}
+
+// This is our map of regions and the lightsail resources in each
+var lightsailPerRegion = map[string]*LightsailRegionInfo{
+	// US-EAST-1 simulates a region where there are three Lightsail instances
+	// (over two pages): one is Wordpress, one is Magento (but it is pending) and
+	// the other is Node.js. It also has 2 databases, 1 container service and 2
+	// load balancers (over two pages).
+	"us-east-1": &LightsailRegionInfo{
+		InstancePages: []*lightsail.GetInstancesOutput{
+			&lightsail.GetInstancesOutput{
+				Instances: []*lightsail.Instance{
+					&lightsail.Instance{
+						Name: aws.String("WordPress-1"),
+						State: &lightsail.InstanceState{
+							Name: aws.String("running"),
+						},
+					},
+					&lightsail.Instance{
+						Name: aws.String("Magento-1"),
+						State: &lightsail.InstanceState{
+							Name: aws.String("pending"),
+						},
+					},
 				},
 			},
-			&lightsail.Instance{
-				Name: aws.String("Magento-1"),
-				State: &lightsail.InstanceState{
-					Name: aws.String("pending"),
+			&lightsail.GetInstancesOutput{
+				Instances: []*lightsail.Instance{
+					&lightsail.Instance{
+						Name: aws.String("Node-js-1"),
+						State: &lightsail.InstanceState{
+							Name: aws.String("running"),
+						},
+					},
 				},
 			},
-			&lightsail.Instance{
-				Name: aws.String("Node-js-1"),
-				State: &lightsail.InstanceState{
-					Name: aws.String("running"),
+		},
+		DatabasePages: []*lightsail.GetRelationalDatabasesOutput{
+			&lightsail.GetRelationalDatabasesOutput{
+				RelationalDatabases: []*lightsail.RelationalDatabase{
+					&lightsail.RelationalDatabase{
+						Name: aws.String("MySQL-1"),
+					},
+					&lightsail.RelationalDatabase{
+						Name: aws.String("PostgreSQL-1"),
+					},
+				},
+			},
+		},
+		ContainerServices: &lightsail.GetContainerServicesOutput{
+			ContainerServices: []*lightsail.ContainerService{
+				&lightsail.ContainerService{
+					ContainerServiceName: aws.String("web-app"),
+				},
+			},
+		},
+		LoadBalancerPages: []*lightsail.GetLoadBalancersOutput{
+			&lightsail.GetLoadBalancersOutput{
+				LoadBalancers: []*lightsail.LoadBalancer{
+					&lightsail.LoadBalancer{
+						Name: aws.String("LoadBalancer-1"),
+					},
 				},
 			},
+			&lightsail.GetLoadBalancersOutput{
+				LoadBalancers: []*lightsail.LoadBalancer{
+					&lightsail.LoadBalancer{
+						Name: aws.String("LoadBalancer-2"),
+					},
+				},
+			},
+		},
+	},
+
+	// US-EAST-2 has no resources...
+	"us-east-2": &LightsailRegionInfo{
+		InstancePages: []*lightsail.GetInstancesOutput{
+			&lightsail.GetInstancesOutput{},
+		},
+		DatabasePages: []*lightsail.GetRelationalDatabasesOutput{
+			&lightsail.GetRelationalDatabasesOutput{},
+		},
+		ContainerServices: &lightsail.GetContainerServicesOutput{},
+		LoadBalancerPages: []*lightsail.GetLoadBalancersOutput{
+			&lightsail.GetLoadBalancersOutput{},
 		},
 	},
-	// US-EAST-2 has no instances...
-	"us-east-2": &lightsail.GetInstancesOutput{},
-
-	// EU-WEST-1 has 2 instances (only 1 running)
-	"eu-west-1": &lightsail.GetInstancesOutput{
-		Instances: []*lightsail.Instance{
-			&lightsail.Instance{
-				Name: aws.String("WordPress-1"),
-				State: &lightsail.InstanceState{
-					Name: aws.String("running"),
+
+	// EU-WEST-1 has 2 instances (only 1 running), 3 databases (over two pages),
+	// 2 container services and 1 load balancer
+	"eu-west-1": &LightsailRegionInfo{
+		InstancePages: []*lightsail.GetInstancesOutput{
+			&lightsail.GetInstancesOutput{
+				Instances: []*lightsail.Instance{
+					&lightsail.Instance{
+						Name: aws.String("WordPress-1"),
+						State: &lightsail.InstanceState{
+							Name: aws.String("running"),
+						},
+					},
+					&lightsail.Instance{
+						Name: aws.String("Magento-1"),
+						State: &lightsail.InstanceState{
+							Name: aws.String("stopped"),
+						},
+					},
 				},
 			},
-			&lightsail.Instance{
-				Name: aws.String("Magento-1"),
-				State: &lightsail.InstanceState{
-					Name: aws.String("stopped"),
+		},
+		DatabasePages: []*lightsail.GetRelationalDatabasesOutput{
+			&lightsail.GetRelationalDatabasesOutput{
+				RelationalDatabases: []*lightsail.RelationalDatabase{
+					&lightsail.RelationalDatabase{
+						Name: aws.String("MySQL-1"),
+					},
+				},
+			},
+			&lightsail.GetRelationalDatabasesOutput{
+				RelationalDatabases: []*lightsail.RelationalDatabase{
+					&lightsail.RelationalDatabase{
+						Name: aws.String("MySQL-2"),
+					},
+					&lightsail.RelationalDatabase{
+						Name: aws.String("PostgreSQL-1"),
+					},
+				},
+			},
+		},
+		ContainerServices: &lightsail.GetContainerServicesOutput{
+			ContainerServices: []*lightsail.ContainerService{
+				&lightsail.ContainerService{
+					ContainerServiceName: aws.String("api"),
+				},
+				&lightsail.ContainerService{
+					ContainerServiceName: aws.String("worker"),
+				},
+			},
+		},
+		LoadBalancerPages: []*lightsail.GetLoadBalancersOutput{
+			&lightsail.GetLoadBalancersOutput{
+				LoadBalancers: []*lightsail.LoadBalancer{
+					&lightsail.LoadBalancer{
+						Name: aws.String("LoadBalancer-1"),
+					},
+				},
+			},
+		},
+	},
+
+	// CA-CENTRAL-1 has an instance, but its databases cannot be retrieved
+	"ca-central-1": &LightsailRegionInfo{
+		InstancePages: []*lightsail.GetInstancesOutput{
+			&lightsail.GetInstancesOutput{
+				Instances: []*lightsail.Instance{
+					&lightsail.Instance{
+						Name: aws.String("WordPress-1"),
+						State: &lightsail.InstanceState{
+							Name: aws.String("running"),
+						},
+					},
 				},
 			},
 		},
@@ -91,8 +229,8 @@ var lightsailInstancesPerRegion = map[string]*lightsail.GetInstancesOutput{
 // This is our fake Lightsail Service that implements the AWS API for Lightsail
 type fakeLightsailService struct {
 	lightsailiface.LightsailAPI
-	GRResponse  *lightsail.GetRegionsOutput
-	GIOResponse *lightsail.GetInstancesOutput
+	GRResponse *lightsail.GetRegionsOutput
+	RegionInfo *LightsailRegionInfo
 }
 
 // GetRegions fakes the standard Lightsail API of the same name.
@@ -105,62 +243,90 @@ func (fake *fakeLightsailService) GetRegions(input *lightsail.GetRegionsInput) (
 	return fake.GRResponse, nil
 }
 
-// GetInstances fakes the standard Lightsail API of the same name.
+// GetInstances fakes the standard Lightsail API of the same name (a page at a time,
+// using the PageToken).
 func (fake *fakeLightsailService) GetInstances(input *lightsail.GetInstancesInput) (*lightsail.GetInstancesOutput, error) {
 	// If the supplied response is nil, then simulate an error
-	if fake.GIOResponse == nil {
+	if fake.RegionInfo == nil || fake.RegionInfo.InstancePages == nil {
 		return nil, errors.New("GetInstance encountered an unexpected error: 02468")
 	}
 
-	return fake.GIOResponse, nil
+	// Get the requested page
+	index, nextToken, err := fakePageIndex(input.PageToken, len(fake.RegionInfo.InstancePages))
+	if err != nil {
+		return nil, err
+	}
+
+	return &lightsail.GetInstancesOutput{
+		Instances:     fake.RegionInfo.InstancePages[index].Instances,
+		NextPageToken: nextToken,
+	}, nil
 }
 
-// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
-// Fake Lightsail Service Factory
-// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// GetRelationalDatabases fakes the standard Lightsail API of the same name (a page
+// at a time, using the PageToken).
+func (fake *fakeLightsailService) GetRelationalDatabases(input *lightsail.GetRelationalDatabasesInput) (*lightsail.GetRelationalDatabasesOutput, error) {
+	// If the supplied response is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.DatabasePages == nil {
+		return nil, errors.New("GetRelationalDatabases encountered an unexpected error: 13579")
+	}
 
-// This is our fake Service Factory that implements a way to get a LightsailService.
-type fakeLightsailServiceFactory struct {
-	RegionName string
-	GRResponse *lightsail.GetRegionsOutput
-}
+	// Get the requested page
+	index, nextToken, err := fakePageIndex(input.PageToken, len(fake.RegionInfo.DatabasePages))
+	if err != nil {
+		return nil, err
+	}
 
-// Return our current region
-func (fsf fakeLightsailServiceFactory) GetCurrentRegion() string {
-	return fsf.RegionName
+	return &lightsail.GetRelationalDatabasesOutput{
+		RelationalDatabases: fake.RegionInfo.DatabasePages[index].RelationalDatabases,
+		NextPageToken:       nextToken,
+	}, nil
 }
 
-// Don't need to implement
-func (fsf fakeLightsailServiceFactory) Init() {}
+// GetContainerServices fakes the standard Lightsail API of the same name.
+func (fake *fakeLightsailService) GetContainerServices(input *lightsail.GetContainerServicesInput) (*lightsail.GetContainerServicesOutput, error) {
+	// If the supplied response is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.ContainerServices == nil {
+		return nil, errors.New("GetContainerServices encountered an unexpected error: 97531")
+	}
 
-// Don't need to implement
-func (fsf fakeLightsailServiceFactory) GetAccountIDService() *AccountIDService {
-	return nil
+	return fake.RegionInfo.ContainerServices, nil
 }
 
-// Don't need to implement
-func (fsf fakeLightsailServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
-	return nil
-}
+// GetLoadBalancers fakes the standard Lightsail API of the same name (a page at a
+// time, using the PageToken).
+func (fake *fakeLightsailService) GetLoadBalancers(input *lightsail.GetLoadBalancersInput) (*lightsail.GetLoadBalancersOutput, error) {
+	// If the supplied response is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.LoadBalancerPages == nil {
+		return nil, errors.New("GetLoadBalancers encountered an unexpected error: 86420")
+	}
 
-// Don't need to implement
-func (fsf fakeLightsailServiceFactory) GetRDSInstanceService(regionName string) *RDSInstanceService {
-	return nil
-}
+	// Get the requested page
+	index, nextToken, err := fakePageIndex(input.PageToken, len(fake.RegionInfo.LoadBalancerPages))
+	if err != nil {
+		return nil, err
+	}
 
-// Don't need to implement
-func (fsf fakeLightsailServiceFactory) GetS3Service() *S3Service {
-	return nil
+	return &lightsail.GetLoadBalancersOutput{
+		LoadBalancers: fake.RegionInfo.LoadBalancerPages[index].LoadBalancers,
+		NextPageToken: nextToken,
+	}, nil
 }
 
-// Don't need to implement
-func (fsf fakeLightsailServiceFactory) GetLambdaService(string) *LambdaService {
-	return nil
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Lightsail Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This is our fake Service Factory that implements a way to get a LightsailService.
+type fakeLightsailServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	GRResponse *lightsail.GetRegionsOutput
 }
 
-// Don't need to implement
-func (fsf fakeLightsailServiceFactory) GetContainerService(string) *ContainerService {
-	return nil
+// Return our current region
+func (fsf fakeLightsailServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
 }
 
 // Implement a way to return Lightsail regions and instances found in each
@@ -175,47 +341,68 @@ func (fsf fakeLightsailServiceFactory) GetLightsailService(regionName string) *L
 
 	return &LightsailService{
 		Client: &fakeLightsailService{
-			GRResponse:  fsf.GRResponse,
-			GIOResponse: lightsailInstancesPerRegion[resolvedRegionName],
+			GRResponse: fsf.GRResponse,
+			RegionInfo: lightsailPerRegion[resolvedRegionName],
 		},
 	}
 }
 
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
-// Unit Test for LightsailInstances
+// Unit Test for LightsailResources
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 
-func TestLightsailInstances(t *testing.T) {
-	// Describe all of our test cases: 2 failures and 4 success cases
+func TestLightsailResources(t *testing.T) {
+	// Describe all of our test cases: 3 failures and 5 success cases
 	cases := []struct {
-		RegionName    string
-		AllRegions    bool
-		GRResponse    *lightsail.GetRegionsOutput
-		ExpectedCount int
-		ExpectError   bool
+		RegionName     string
+		AllRegions     bool
+		GRResponse     *lightsail.GetRegionsOutput
+		ExpectedCounts LightsailCounts
+		ExpectError    bool
 	}{
 		{
-			RegionName:    "us-east-1",
-			GRResponse:    lightsailRegions,
-			ExpectedCount: 2,
+			RegionName: "us-east-1",
+			GRResponse: lightsailRegions,
+			ExpectedCounts: LightsailCounts{
+				Instances:         2,
+				Databases:         2,
+				ContainerServices: 1,
+				LoadBalancers:     2,
+			},
+		}, {
+			RegionName: "us-east-2",
+			GRResponse: lightsailRegions,
+		}, {
+			RegionName: "eu-west-1",
+			GRResponse: lightsailRegions,
+			ExpectedCounts: LightsailCounts{
+				Instances:         1,
+				Databases:         3,
+				ContainerServices: 2,
+				LoadBalancers:     1,
+			},
 		}, {
-			RegionName:    "us-east-2",
-			GRResponse:    lightsailRegions,
-			ExpectedCount: 0,
+			RegionName: "undefined-region",
+			GRResponse: lightsailRegions,
 		}, {
-			RegionName:    "eu-west-1",
-			GRResponse:    lightsailRegions,
-			ExpectedCount: 1,
+			AllRegions: true,
+			GRResponse: lightsailRegions,
+			ExpectedCounts: LightsailCounts{
+				Instances:         3,
+				Databases:         5,
+				ContainerServices: 3,
+				LoadBalancers:     3,
+			},
 		}, {
-			RegionName:    "undefined-region",
-			GRResponse:    lightsailRegions,
-			ExpectedCount: 0,
+			AllRegions:  true,
+			ExpectError: true,
 		}, {
-			AllRegions:    true,
-			GRResponse:    lightsailRegions,
-			ExpectedCount: 3,
+			RegionName:  "ca-central-1",
+			GRResponse:  lightsailRegionsWithError,
+			ExpectError: true,
 		}, {
 			AllRegions:  true,
+			GRResponse:  lightsailRegionsWithError,
 			ExpectError: true,
 		},
 	}
@@ -231,8 +418,8 @@ func TestLightsailInstances(t *testing.T) {
 		// Create a mock activity monitor
 		mon := &mock.ActivityMonitorImpl{}
 
-		// Invoke our LightsailInstances function
-		actualCount := LightsailInstances(sf, mon, c.AllRegions)
+		// Invoke our LightsailResources function
+		actualCounts := LightsailResources(sf, mon, c.AllRegions)
 
 		// Did we expect an error?
 		if c.ExpectError {
@@ -242,8 +429,8 @@ func TestLightsailInstances(t *testing.T) {
 			}
 		} else if mon.ErrorOccured {
 			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
-		} else if actualCount != c.ExpectedCount {
-			t.Errorf("Error: LightsailInstances returned %d; expected %d", actualCount, c.ExpectedCount)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: LightsailResources returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
 		} else if mon.ProgramExited {
 			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
 		}
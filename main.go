@@ -8,6 +8,7 @@ Summary: Top-level entry point for the tool. Provides main() function.
 package main
 
 import (
+	"fmt"
 	"os"
 	"time"
 )
@@ -43,6 +44,12 @@ func main() {
 		ExitFn: os.Exit,
 	}
 
+	// Are we counting Terraform state files instead of an AWS account?
+	if len(os.Args) > 1 && os.Args[1] == CountTFStateCommand {
+		RunCountTFState(os.Args[2:], monitor)
+		return
+	}
+
 	// Process all command line arguments
 	settings := &CommandLineSettings{}
 	cleanupFn := settings.Process(os.Args[1:], monitor)
@@ -73,8 +80,9 @@ func main() {
 
 	// Construct a new results data structure
 	results := Results{
-		StoreHeaders: !settings.appendToOutput,
-		Writer:       settings.outputFile,
+		StoreHeaders:  !settings.appendToOutput,
+		AppendHeaders: settings.outputHeaders,
+		Writer:        settings.outputFile,
 	}
 	results.Init()
 
@@ -86,19 +94,200 @@ func main() {
 		displayRegion = settings.regionName
 	}
 
+	// Are we querying an AWS Config aggregator? If so, there is a row for each account.
+	if settings.backend == ConfigBackend {
+		timestamp := time.Now().Format(time.RFC3339)
+		columns := ConfigBackendColumns(settings.detail, settings.countReplicas, settings.resourceTypes)
+		for _, accountCounts := range ConfigAggregatorCounts(serviceFactory, monitor, settings.allRegions, settings.configAggregator, columns) {
+			results.NewRow()
+			results.Append("Account ID", accountCounts.AccountID)
+			results.Append("Timestamp", timestamp)
+			results.Append("Region", displayRegion)
+			for index, column := range columns {
+				results.Append(column.Name, accountCounts.Counts[index])
+			}
+		}
+
+		// Save our results (noting where they came from)
+		results.Save(monitor)
+		monitor.Message("\nThese counts were produced by the AWS Config aggregator %s. Only the resources recorded by AWS Config are counted.\n", settings.configAggregator)
+		monitor.Message("\nSuccess.\n")
+
+		return
+	}
+
+	// Are we searching a resource index? If so, the counts are only approximate.
+	if settings.backend == IndexBackend {
+		results.NewRow()
+		results.Append("Account ID", GetAccountID(serviceFactory.GetAccountIDService(), monitor))
+		results.Append("Timestamp", time.Now().Format(time.RFC3339))
+		results.Append("Region", displayRegion)
+		indexCounts := IndexResources(serviceFactory, monitor, settings.allRegions)
+		for index, column := range IndexColumns {
+			results.Append(column.Name, indexCounts.Counts[index])
+		}
+
+		// Save our results (noting where they came from)
+		results.Save(monitor)
+		monitor.Message("\nThese counts came from an index (%s), not from the APIs of each service. They are APPROXIMATE.\n", indexCounts.Source)
+		if indexCounts.FallbackReason != nil {
+			monitor.Message("AWS Resource Explorer could not be searched (%v), so only resources that have been tagged are counted.\n", indexCounts.FallbackReason)
+		}
+		for _, columnName := range indexCounts.Incomplete {
+			monitor.Message("*%s is a lower bound: Resource Explorer stops counting at 1,000 resources.\n", columnName)
+		}
+		monitor.Message("\nSuccess.\n")
+
+		return
+	}
+
 	// Create a new row of data
 	results.NewRow()
-	results.Append("Account ID", GetAccountID(serviceFactory.GetAccountIDService(), monitor))
+	accountID := GetAccountID(serviceFactory.GetAccountIDService(), monitor)
+	results.Append("Account ID", accountID)
 	results.Append("Timestamp", time.Now().Format(time.RFC3339))
 	results.Append("Region", displayRegion)
-	results.Append("# of EC2 Instances", EC2Counts(serviceFactory, monitor, settings.allRegions))
-	results.Append("# of Spot Instances", SpotInstances(serviceFactory, monitor, settings.allRegions))
-	results.Append("# of EBS Volumes", EBSVolumes(serviceFactory, monitor, settings.allRegions))
-	results.Append("# of Unique Containers", UniqueContainerImages(serviceFactory, monitor, settings.allRegions))
-	results.Append("# of Lambda Functions", LambdaFunctions(serviceFactory, monitor, settings.allRegions))
+	ec2Counts := EC2Instances(serviceFactory, monitor, settings.allRegions)
+	ec2Lifecycles, ec2Nodes := ec2Counts.Lifecycles, ec2Counts.Nodes
+	results.Append("# of EC2 Instances", ec2Lifecycles.OnDemand)
+	results.Append("# of Standalone EC2 Instances", ec2Nodes.Standalone)
+	results.Append("# of EKS Nodes", ec2Nodes.EKSNodes)
+	results.Append("# of ECS Nodes", ec2Nodes.ECSNodes)
+	results.Append("# of Auto Scaling Nodes", ec2Nodes.AutoScaling)
+	results.Append("# of Spot Instances", ec2Lifecycles.Spot)
+	results.Append("# of Scheduled Instances", ec2Lifecycles.Scheduled)
+	results.Append("# of Capacity Block Instances", ec2Lifecycles.CapacityBlock)
+	results.Append("# of Other Lifecycle Instances", ec2Lifecycles.Other)
+	ebsCounts := EBSVolumes(serviceFactory, monitor, settings.allRegions, settings.detail)
+	results.Append("# of EBS Volumes", ebsCounts.Volumes)
+	if settings.detail {
+		results.Append("EBS Attached Storage (GiB)", ebsCounts.AttachedGiB)
+		for _, volumeType := range EBSVolumeTypes {
+			results.Append(fmt.Sprintf("EBS Attached Storage (%s) (GiB)", volumeType), ebsCounts.VolumeTypeGiB[volumeType])
+		}
+		results.Append("# of Unattached EBS Volumes", ebsCounts.UnattachedVolumes)
+		results.Append("EBS Unattached Storage (GiB)", ebsCounts.UnattachedGiB)
+		results.Append("# of EBS Snapshots", ebsCounts.Snapshots)
+	}
+	containerOptions := ContainerImageOptions{
+		Source:      AllTaskDefinitions,
+		Dedupe:      settings.imageDedupe,
+		Concurrency: settings.concurrency,
+	}
+	if settings.cacheFileName != "" {
+		containerOptions.Cache = LoadTaskDefinitionCache(settings.cacheFileName, monitor)
+	}
+	containerCounts := UniqueContainerImages(serviceFactory, monitor, settings.allRegions, containerOptions)
+	results.Append("# of Unique Containers", containerCounts.Unique)
+	if settings.containerModes {
+		containerOptions.Source = ActiveTaskDefinitions
+		results.Append("# of Unique Containers (Active Task Definitions)", UniqueContainerImages(serviceFactory, monitor, settings.allRegions, containerOptions).Unique)
+		containerOptions.Source = RunningWorkloads
+		results.Append("# of Unique Containers (Running Workloads)", UniqueContainerImages(serviceFactory, monitor, settings.allRegions, containerOptions).Unique)
+	}
+	containerOptions.Cache.Save(monitor)
+	fargateCounts := FargateTasks(serviceFactory, monitor, settings.allRegions)
+	results.Append("# of Fargate Tasks", fargateCounts.Tasks)
+	results.Append("# of Fargate vCPUs", fargateCounts.VCPUs())
+	results.Append("# of Fargate Memory (MiB)", fargateCounts.MemoryMiB)
+	eksCounts := EKSClusters(serviceFactory, monitor, settings.allRegions)
+	results.Append("# of EKS Clusters", eksCounts.Clusters)
+	results.Append("# of EKS Node Groups", eksCounts.NodeGroups)
+	results.Append("# of EKS Desired Nodes", eksCounts.DesiredNodes)
+	results.Append("# of EKS Fargate Profiles", eksCounts.FargateProfiles)
+	ecrCounts := ECRRepositories(serviceFactory, monitor, settings.allRegions)
+	results.Append("# of ECR Repositories", ecrCounts.Repositories)
+	results.Append("# of ECR Images", ecrCounts.Images)
+	results.Append("# of ECR Image Tags", ecrCounts.Tags)
+	results.Append("# of Unique Containers (ECR)", containerCounts.ECR)
+	results.Append("# of Unique Containers (Public/Other Registries)", containerCounts.Other)
+	lambdaCounts := LambdaFunctions(serviceFactory, monitor, settings.allRegions, settings.lambdaVersions)
+	results.Append("# of Lambda Functions", lambdaCounts.Functions)
+	if settings.lambdaVersions {
+		results.Append("# of Lambda Published Versions", lambdaCounts.Versions)
+	}
+	results.Append("# of Lambda Functions (deprecated runtimes)", lambdaCounts.DeprecatedRuntimes)
+	for _, family := range LambdaRuntimeFamilies {
+		results.Append(fmt.Sprintf("# of Lambda Functions (%s)", family), lambdaCounts.Runtimes[family])
+	}
+	results.Append("# of Lambda Functions (by runtime)", lambdaCounts.RuntimeVersionSummary())
+	for _, arch := range LambdaArchitectures {
+		results.Append(fmt.Sprintf("# of Lambda Functions (%s)", arch), lambdaCounts.Architectures[arch])
+	}
+	serverlessCounts := ServerlessServices(serviceFactory, monitor, settings.allRegions)
+	results.Append("# of Step Functions State Machines", serverlessCounts.StateMachines)
+	results.Append("# of API Gateway REST APIs", serverlessCounts.RestAPIs)
+	results.Append("# of API Gateway HTTP APIs", serverlessCounts.HTTPAPIs)
+	results.Append("# of API Gateway WebSocket APIs", serverlessCounts.WebSocketAPIs)
+	results.Append("# of SQS Queues", serverlessCounts.Queues)
+	results.Append("# of SNS Topics", serverlessCounts.Topics)
+	results.Append("# of EventBridge Rules", serverlessCounts.EventBridgeRules)
+	results.Append("# of EventBridge Event Buses", serverlessCounts.EventBuses)
+	results.Append("# of DynamoDB Tables", DynamoDBTables(serviceFactory, monitor, settings.allRegions, settings.countReplicas))
 	results.Append("# of RDS Instances", RDSInstances(serviceFactory, monitor, settings.allRegions))
-	results.Append("# of Lightsail Instances", LightsailInstances(serviceFactory, monitor, settings.allRegions))
-	results.Append("# of S3 Buckets", S3Buckets(serviceFactory, monitor, settings.allRegions))
+	rdsClusterCounts := RDSClusters(serviceFactory, monitor, settings.allRegions)
+	results.Append("# of Aurora Clusters", rdsClusterCounts.AuroraClusters)
+	results.Append("# of Aurora Serverless Clusters", rdsClusterCounts.ServerlessClusters)
+	results.Append("# of Provisioned RDS Instances", rdsClusterCounts.ProvisionedInstances)
+	results.Append("# of RDS Read Replicas", rdsClusterCounts.ReadReplicas)
+	for _, family := range RDSEngineFamilies {
+		results.Append(fmt.Sprintf("# of RDS Databases (%s)", family), rdsClusterCounts.Engines[family])
+	}
+	dataCounts := DataServices(serviceFactory, monitor, settings.allRegions)
+	results.Append("# of ElastiCache Clusters", dataCounts.CacheClusters)
+	results.Append("# of ElastiCache Replication Groups", dataCounts.ReplicationGroups)
+	results.Append("# of Redshift Clusters", dataCounts.RedshiftClusters)
+	results.Append("# of Redshift Serverless Workgroups", dataCounts.RedshiftWorkgroups)
+	results.Append("# of OpenSearch Domains", dataCounts.OpenSearchDomains)
+	results.Append("# of DocumentDB Clusters", dataCounts.DocumentDBClusters)
+	results.Append("# of Neptune Clusters", dataCounts.NeptuneClusters)
+	edgeCounts := NetworkEdge(serviceFactory, monitor, settings.allRegions)
+	results.Append("# of Classic Load Balancers", edgeCounts.ClassicLoadBalancers)
+	results.Append("# of Application Load Balancers", edgeCounts.ApplicationLoadBalancers)
+	results.Append("# of Network Load Balancers", edgeCounts.NetworkLoadBalancers)
+	results.Append("# of Gateway Load Balancers", edgeCounts.GatewayLoadBalancers)
+	results.Append("# of NAT Gateways", edgeCounts.NATGateways)
+	results.Append("# of Elastic IPs", edgeCounts.ElasticIPs)
+	vpcCounts := VPCFootprint(serviceFactory, monitor, settings.allRegions)
+	results.Append("# of VPCs", vpcCounts.VPCs)
+	results.Append("# of VPCs with Flow Logs", vpcCounts.VPCsWithFlowLogs)
+	results.Append("# of VPCs without Flow Logs", vpcCounts.VPCsWithoutFlowLogs)
+	results.Append("# of Subnets", vpcCounts.Subnets)
+	results.Append("# of Transit Gateway Attachments", vpcCounts.TransitGatewayAttachments)
+	results.Append("# of VPC Endpoints", vpcCounts.VPCEndpoints)
+	lightsailCounts := LightsailResources(serviceFactory, monitor, settings.allRegions)
+	results.Append("# of Lightsail Instances", lightsailCounts.Instances)
+	results.Append("# of Lightsail Databases", lightsailCounts.Databases)
+	results.Append("# of Lightsail Container Services", lightsailCounts.ContainerServices)
+	results.Append("# of Lightsail Load Balancers", lightsailCounts.LoadBalancers)
+	s3BucketCount, s3BucketRegions := S3Buckets(serviceFactory, monitor, settings.allRegions, settings.s3Metrics, settings.concurrency)
+	results.Append("# of S3 Buckets", s3BucketCount)
+	if settings.s3Metrics {
+		s3Storage := S3StorageMetrics(serviceFactory, monitor, settings.allRegions, s3BucketRegions)
+		results.Append("# of S3 Objects", s3Storage.Objects)
+		results.Append("S3 Storage (Bytes)", s3Storage.Bytes)
+	}
+	results.Append("# of CloudFront Distributions", CloudFrontDistributions(serviceFactory, monitor, settings.allRegions))
+	for _, typeName := range settings.resourceTypes {
+		results.Append(fmt.Sprintf("# of %s", typeName), CloudControlResources(serviceFactory, monitor, settings.allRegions, typeName))
+	}
+	for _, counter := range settings.customCounters {
+		results.Append(counter.Column, CustomCount(serviceFactory, monitor, settings.allRegions, counter))
+	}
+	if len(settings.plugins) > 0 {
+		pluginRequest := &PluginRequest{
+			AccountID:  accountID,
+			Profile:    settings.profileName,
+			AllRegions: settings.allRegions,
+			Settings:   settings.PluginSettings(),
+		}
+		pluginExecutor := &ProcessPluginExecutor{
+			Timeout: pluginTimeout,
+		}
+		for _, result := range RunPlugins(serviceFactory, monitor, pluginExecutor, settings.plugins, pluginRequest) {
+			results.Append(result.Column, result.Value)
+		}
+	}
 
 	/* =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 	 * Construct CSV Output
@@ -107,9 +296,19 @@ func main() {
 	// Save our results to a CSV file
 	results.Save(monitor)
 
-	// Do we need to "explain" our S3 count?
+	// Do we need to "explain" our CloudFront count?
 	if !settings.allRegions {
-		monitor.Message("\n*S3 counts cannot be computed on a per-region basis. This count is for ALL REGIONS.\n")
+		monitor.Message("\n*CloudFront counts cannot be computed on a per-region basis. This count is for ALL REGIONS.\n")
+		for _, typeName := range settings.resourceTypes {
+			if IsGlobalResourceType(typeName) {
+				monitor.Message("*%s counts cannot be computed on a per-region basis. This count is for ALL REGIONS.\n", typeName)
+			}
+		}
+		for _, counter := range settings.customCounters {
+			if counter.Global {
+				monitor.Message("*%s cannot be computed on a per-region basis. This count is for ALL REGIONS.\n", counter.Column)
+			}
+		}
 	}
 
 	// Indicate success
@@ -0,0 +1,137 @@
+/******************************************************************************
+Cloud Resource Counter
+File: networkEdge.go
+
+Summary: Provides a count of load balancers (Classic, Application, Network and
+         Gateway), NAT gateways and Elastic IP addresses.
+******************************************************************************/
+
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	color "github.com/logrusorgru/aurora"
+)
+
+// NetworkEdgeCounts holds the number of load balancers (by type), NAT gateways and
+// Elastic IP addresses.
+type NetworkEdgeCounts struct {
+	ClassicLoadBalancers     int
+	ApplicationLoadBalancers int
+	NetworkLoadBalancers     int
+	GatewayLoadBalancers     int
+	NATGateways              int
+	ElasticIPs               int
+}
+
+// Add the supplied counts into our struct.
+func (nec *NetworkEdgeCounts) Add(other NetworkEdgeCounts) {
+	nec.ClassicLoadBalancers += other.ClassicLoadBalancers
+	nec.ApplicationLoadBalancers += other.ApplicationLoadBalancers
+	nec.NetworkLoadBalancers += other.NetworkLoadBalancers
+	nec.GatewayLoadBalancers += other.GatewayLoadBalancers
+	nec.NATGateways += other.NATGateways
+	nec.ElasticIPs += other.ElasticIPs
+}
+
+// LoadBalancers returns the number of load balancers of all types.
+func (nec NetworkEdgeCounts) LoadBalancers() int {
+	return nec.ClassicLoadBalancers + nec.ApplicationLoadBalancers + nec.NetworkLoadBalancers + nec.GatewayLoadBalancers
+}
+
+// NetworkEdge retrieves the counts of load balancers, NAT gateways and Elastic IP
+// addresses either for all regions (allRegions is true) or the region associated
+// with the session. This method gives status back to the user via the supplied
+// ActivityMonitor instance.
+func NetworkEdge(sf ServiceFactory, am ActivityMonitor, allRegions bool) NetworkEdgeCounts {
+	// Indicate activity
+	am.StartAction("Retrieving Load Balancer, NAT Gateway and Elastic IP counts")
+
+	// Should we get the counts for all regions?
+	var edgeCounts NetworkEdgeCounts
+	if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the network edge counts for a specific region
+			edgeCounts.Add(networkEdgeForSingleRegion(sf, regionName, am))
+		}
+	} else {
+		// Get the network edge counts for the region selected by this session
+		edgeCounts = networkEdgeForSingleRegion(sf, "", am)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d load balancers, %d NAT gateways, %d Elastic IPs)",
+		color.Bold(edgeCounts.LoadBalancers()), color.Bold(edgeCounts.NATGateways), color.Bold(edgeCounts.ElasticIPs))
+
+	return edgeCounts
+}
+
+// Get the network edge counts for a single region. We stop at the first error.
+func networkEdgeForSingleRegion(sf ServiceFactory, regionName string, am ActivityMonitor) NetworkEdgeCounts {
+	// Indicate activity
+	am.Message(".")
+
+	var edgeCounts NetworkEdgeCounts
+
+	// Count the Classic Load Balancers
+	err := sf.GetELBService(regionName).DescribeLoadBalancers(&elb.DescribeLoadBalancersInput{}, func(page *elb.DescribeLoadBalancersOutput, lastPage bool) bool {
+		edgeCounts.ClassicLoadBalancers += len(page.LoadBalancerDescriptions)
+
+		return true
+	})
+	if am.CheckError(err) {
+		return edgeCounts
+	}
+
+	// Count the (v2) load balancers by type
+	err = sf.GetELBv2Service(regionName).DescribeLoadBalancers(&elbv2.DescribeLoadBalancersInput{}, func(page *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+		for _, lb := range page.LoadBalancers {
+			switch aws.StringValue(lb.Type) {
+			case elbv2.LoadBalancerTypeEnumApplication:
+				edgeCounts.ApplicationLoadBalancers++
+			case elbv2.LoadBalancerTypeEnumNetwork:
+				edgeCounts.NetworkLoadBalancers++
+			case elbv2.LoadBalancerTypeEnumGateway:
+				edgeCounts.GatewayLoadBalancers++
+			}
+		}
+
+		return true
+	})
+	if am.CheckError(err) {
+		return edgeCounts
+	}
+
+	// Count the NAT gateways (ignoring those that have failed or are deleted)
+	ec2is := sf.GetEC2InstanceService(regionName)
+	err = ec2is.InspectNatGateways(&ec2.DescribeNatGatewaysInput{}, func(page *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+		for _, natGateway := range page.NatGateways {
+			switch aws.StringValue(natGateway.State) {
+			case ec2.NatGatewayStateFailed, ec2.NatGatewayStateDeleting, ec2.NatGatewayStateDeleted:
+				continue
+			}
+			edgeCounts.NATGateways++
+		}
+
+		return true
+	})
+	if am.CheckError(err) {
+		return edgeCounts
+	}
+
+	// Count the Elastic IP addresses
+	addresses, err := ec2is.GetAddresses(&ec2.DescribeAddressesInput{})
+	if am.CheckError(err) {
+		return edgeCounts
+	}
+	edgeCounts.ElasticIPs = len(addresses.Addresses)
+
+	return edgeCounts
+}
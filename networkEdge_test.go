@@ -0,0 +1,384 @@
+/******************************************************************************
+Cloud Resource Counter
+File: networkEdge_test.go
+
+Summary: The Unit Test for networkEdge.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Network Edge Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// NetworkEdgeRegionInfo describes the fake load balancers, NAT gateways and Elastic
+// IPs in a region. Each slice holds the pages returned by the corresponding API.
+type NetworkEdgeRegionInfo struct {
+	ClassicPages    []*elb.DescribeLoadBalancersOutput
+	V2Pages         []*elbv2.DescribeLoadBalancersOutput
+	NatGatewayPages []*ec2.DescribeNatGatewaysOutput
+	Addresses       *ec2.DescribeAddressesOutput
+}
+
+// Helper function to construct a (v2) load balancer of the supplied type
+func loadBalancerOfType(lbType string) *elbv2.LoadBalancer {
+	return &elbv2.LoadBalancer{
+		Type: aws.String(lbType),
+	}
+}
+
+// Helper function to construct a NAT gateway in the supplied state
+func natGatewayInState(state string) *ec2.NatGateway {
+	return &ec2.NatGateway{
+		State: aws.String(state),
+	}
+}
+
+// This is our map of regions and the network edge in each
+var networkEdgePerRegion = map[string]*NetworkEdgeRegionInfo{
+	// US-EAST-1 illustrates a case where most APIs return two pages of results:
+	// 3 Classic Load Balancers, 2 ALBs, 1 NLB, 1 GWLB, 2 NAT gateways (of 4) and
+	// 3 Elastic IPs.
+	"us-east-1": &NetworkEdgeRegionInfo{
+		ClassicPages: []*elb.DescribeLoadBalancersOutput{
+			&elb.DescribeLoadBalancersOutput{
+				LoadBalancerDescriptions: []*elb.LoadBalancerDescription{
+					&elb.LoadBalancerDescription{LoadBalancerName: aws.String("legacy-web")},
+					&elb.LoadBalancerDescription{LoadBalancerName: aws.String("legacy-api")},
+				},
+			},
+			&elb.DescribeLoadBalancersOutput{
+				LoadBalancerDescriptions: []*elb.LoadBalancerDescription{
+					&elb.LoadBalancerDescription{LoadBalancerName: aws.String("legacy-admin")},
+				},
+			},
+		},
+		V2Pages: []*elbv2.DescribeLoadBalancersOutput{
+			&elbv2.DescribeLoadBalancersOutput{
+				LoadBalancers: []*elbv2.LoadBalancer{
+					loadBalancerOfType("application"),
+					loadBalancerOfType("network"),
+				},
+			},
+			&elbv2.DescribeLoadBalancersOutput{
+				LoadBalancers: []*elbv2.LoadBalancer{
+					loadBalancerOfType("application"),
+					loadBalancerOfType("gateway"),
+				},
+			},
+		},
+		NatGatewayPages: []*ec2.DescribeNatGatewaysOutput{
+			&ec2.DescribeNatGatewaysOutput{
+				NatGateways: []*ec2.NatGateway{
+					natGatewayInState("available"),
+					natGatewayInState("deleted"),
+				},
+			},
+			&ec2.DescribeNatGatewaysOutput{
+				NatGateways: []*ec2.NatGateway{
+					natGatewayInState("pending"),
+					natGatewayInState("failed"),
+				},
+			},
+		},
+		Addresses: &ec2.DescribeAddressesOutput{
+			Addresses: []*ec2.Address{
+				&ec2.Address{PublicIp: aws.String("203.0.113.10")},
+				&ec2.Address{PublicIp: aws.String("203.0.113.11")},
+				&ec2.Address{PublicIp: aws.String("203.0.113.12")},
+			},
+		},
+	},
+	// US-EAST-2 has 1 NLB and 1 Elastic IP (and nothing else)
+	"us-east-2": &NetworkEdgeRegionInfo{
+		ClassicPages: []*elb.DescribeLoadBalancersOutput{
+			&elb.DescribeLoadBalancersOutput{},
+		},
+		V2Pages: []*elbv2.DescribeLoadBalancersOutput{
+			&elbv2.DescribeLoadBalancersOutput{
+				LoadBalancers: []*elbv2.LoadBalancer{
+					loadBalancerOfType("network"),
+				},
+			},
+		},
+		NatGatewayPages: []*ec2.DescribeNatGatewaysOutput{
+			&ec2.DescribeNatGatewaysOutput{},
+		},
+		Addresses: &ec2.DescribeAddressesOutput{
+			Addresses: []*ec2.Address{
+				&ec2.Address{PublicIp: aws.String("198.51.100.7")},
+			},
+		},
+	},
+	// AF-SOUTH-1 has nothing
+	"af-south-1": &NetworkEdgeRegionInfo{
+		ClassicPages: []*elb.DescribeLoadBalancersOutput{
+			&elb.DescribeLoadBalancersOutput{},
+		},
+		V2Pages: []*elbv2.DescribeLoadBalancersOutput{
+			&elbv2.DescribeLoadBalancersOutput{},
+		},
+		NatGatewayPages: []*ec2.DescribeNatGatewaysOutput{
+			&ec2.DescribeNatGatewaysOutput{},
+		},
+		Addresses: &ec2.DescribeAddressesOutput{},
+	},
+	// AF-SOUTH-2 has load balancers, but the Elastic IPs cannot be described (an error)
+	"af-south-2": &NetworkEdgeRegionInfo{
+		ClassicPages: []*elb.DescribeLoadBalancersOutput{
+			&elb.DescribeLoadBalancersOutput{},
+		},
+		V2Pages: []*elbv2.DescribeLoadBalancersOutput{
+			&elbv2.DescribeLoadBalancersOutput{
+				LoadBalancers: []*elbv2.LoadBalancer{
+					loadBalancerOfType("application"),
+				},
+			},
+		},
+		NatGatewayPages: []*ec2.DescribeNatGatewaysOutput{
+			&ec2.DescribeNatGatewaysOutput{},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Network Edge Services
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// To use these structs, the caller must supply a NetworkEdgeRegionInfo. If it (or
+// the pages for a particular API) is missing, it will trigger the mock functions to
+// simulate an error.
+type fakeELBService struct {
+	elbiface.ELBAPI
+	RegionInfo *NetworkEdgeRegionInfo
+}
+
+// Simulate the DescribeLoadBalancersPages function
+func (fake *fakeELBService) DescribeLoadBalancersPages(input *elb.DescribeLoadBalancersInput, fn func(*elb.DescribeLoadBalancersOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.ClassicPages == nil {
+		return errors.New("DescribeLoadBalancersPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.ClassicPages {
+		if !fn(output, index == len(fake.RegionInfo.ClassicPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+type fakeELBv2Service struct {
+	elbv2iface.ELBV2API
+	RegionInfo *NetworkEdgeRegionInfo
+}
+
+// Simulate the DescribeLoadBalancersPages function
+func (fake *fakeELBv2Service) DescribeLoadBalancersPages(input *elbv2.DescribeLoadBalancersInput, fn func(*elbv2.DescribeLoadBalancersOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.V2Pages == nil {
+		return errors.New("DescribeLoadBalancersPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.V2Pages {
+		if !fn(output, index == len(fake.RegionInfo.V2Pages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+type fakeNetworkEdgeEC2Service struct {
+	ec2iface.EC2API
+	RegionInfo *NetworkEdgeRegionInfo
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Simulate the DescribeRegions function
+func (fake *fakeNetworkEdgeEC2Service) DescribeRegions(input *ec2.DescribeRegionsInput) (*ec2.DescribeRegionsOutput, error) {
+	// If the supplied response is nil, then simulate an error
+	if fake.DRResponse == nil {
+		return nil, errors.New("DescribeRegions encountered an unexpected error: 6789")
+	}
+
+	return fake.DRResponse, nil
+}
+
+// Simulate the DescribeNatGatewaysPages function
+func (fake *fakeNetworkEdgeEC2Service) DescribeNatGatewaysPages(input *ec2.DescribeNatGatewaysInput, fn func(*ec2.DescribeNatGatewaysOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.NatGatewayPages == nil {
+		return errors.New("DescribeNatGatewaysPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.NatGatewayPages {
+		if !fn(output, index == len(fake.RegionInfo.NatGatewayPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Simulate the DescribeAddresses function
+func (fake *fakeNetworkEdgeEC2Service) DescribeAddresses(input *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.Addresses == nil {
+		return nil, errors.New("DescribeAddresses encountered an unexpected error: 1234")
+	}
+
+	return fake.RegionInfo.Addresses, nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeNetworkEdgeServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Get the network edge of the supplied region (or the region associated with our factory)
+func (fsf fakeNetworkEdgeServiceFactory) regionInfo(regionName string) *NetworkEdgeRegionInfo {
+	if regionName == "" {
+		return networkEdgePerRegion[fsf.RegionName]
+	}
+
+	return networkEdgePerRegion[regionName]
+}
+
+// Return our current region
+func (fsf fakeNetworkEdgeServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// Return a specialized EC2InstanceService that supports the DescribeRegions,
+// DescribeNatGatewaysPages and DescribeAddresses APIs
+func (fsf fakeNetworkEdgeServiceFactory) GetEC2InstanceService(regionName string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeNetworkEdgeEC2Service{
+			RegionInfo: fsf.regionInfo(regionName),
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return a specialized ELBService that returns pre-canned responses
+func (fsf fakeNetworkEdgeServiceFactory) GetELBService(regionName string) *ELBService {
+	return &ELBService{
+		Client: &fakeELBService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized ELBv2Service that returns pre-canned responses
+func (fsf fakeNetworkEdgeServiceFactory) GetELBv2Service(regionName string) *ELBv2Service {
+	return &ELBv2Service{
+		Client: &fakeELBv2Service{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for NetworkEdge
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestNetworkEdge(t *testing.T) {
+	// Describe all of our test cases: 2 failures and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts NetworkEdgeCounts
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: NetworkEdgeCounts{
+				ClassicLoadBalancers:     3,
+				ApplicationLoadBalancers: 2,
+				NetworkLoadBalancers:     1,
+				GatewayLoadBalancers:     1,
+				NATGateways:              2,
+				ElasticIPs:               3,
+			},
+		}, {
+			RegionName: "us-east-2",
+			ExpectedCounts: NetworkEdgeCounts{
+				NetworkLoadBalancers: 1,
+				ElasticIPs:           1,
+			},
+		}, {
+			RegionName: "af-south-1",
+		}, {
+			RegionName:  "af-south-2",
+			ExpectError: true,
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions: true,
+			ExpectedCounts: NetworkEdgeCounts{
+				ClassicLoadBalancers:     3,
+				ApplicationLoadBalancers: 2,
+				NetworkLoadBalancers:     2,
+				GatewayLoadBalancers:     1,
+				NATGateways:              2,
+				ElasticIPs:               4,
+			},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeNetworkEdgeServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our Network Edge function
+		actualCounts := NetworkEdge(sf, mon, c.AllRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: NetworkEdge returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
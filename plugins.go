@@ -0,0 +1,307 @@
+/******************************************************************************
+Cloud Resource Counter
+File: plugins.go
+
+Summary: Runs external counter plugins. A plugin is an executable that reads a
+         JSON request (credentials, regions and settings) from its standard
+         input and writes a JSON response (counts and errors) to its standard
+         output.
+******************************************************************************/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	color "github.com/logrusorgru/aurora"
+)
+
+// PluginProtocolVersion is the version of the request and response documents
+// exchanged with plugins.
+const PluginProtocolVersion = 1
+
+// The longest that we wait for a plugin to finish
+const pluginTimeout = 30 * time.Minute
+
+// PluginRequest is the JSON document written to the standard input of each plugin.
+// Every plugin receives the same request.
+type PluginRequest struct {
+	// The version of the protocol
+	ProtocolVersion int `json:"protocolVersion"`
+
+	// The account being counted, the profile that selected it and the (resolved)
+	// credentials of the session
+	AccountID   string            `json:"accountId"`
+	Profile     string            `json:"profile"`
+	Credentials PluginCredentials `json:"credentials"`
+
+	// The region of the session and the regions to count (either all enabled
+	// regions or just the region of the session)
+	Region     string   `json:"region"`
+	AllRegions bool     `json:"allRegions"`
+	Regions    []string `json:"regions"`
+
+	// The command line settings that affect counting
+	Settings PluginSettings `json:"settings"`
+}
+
+// PluginCredentials holds the AWS credentials that a plugin should use.
+type PluginCredentials struct {
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken,omitempty"`
+}
+
+// PluginSettings holds the command line settings that affect counting.
+type PluginSettings struct {
+	ContainerModes        bool   `json:"containerModes"`
+	ImageDedupe           string `json:"imageDedupe"`
+	CountTableReplicas    bool   `json:"countTableReplicas"`
+	IncludeLambdaVersions bool   `json:"includeLambdaVersions"`
+	S3Metrics             bool   `json:"s3Metrics"`
+	Detail                bool   `json:"detail"`
+	Concurrency           int    `json:"concurrency"`
+}
+
+// PluginResponse is the JSON document that each plugin writes to its standard
+// output. For example:
+//
+//   {
+//     "protocolVersion": 1,
+//     "counts": [
+//       {"column": "# of Widgets", "regions": {"us-east-1": 3, "us-west-2": 1}},
+//       {"column": "# of Global Widgets", "count": 7}
+//     ],
+//     "errors": []
+//   }
+//
+// Any errors are reported just like the errors of the built-in counters.
+type PluginResponse struct {
+	ProtocolVersion int           `json:"protocolVersion"`
+	Counts          []PluginCount `json:"counts"`
+	Errors          []string      `json:"errors"`
+}
+
+// PluginCount is a single count (CSV column) returned by a plugin. The plugin
+// either supplies the count itself or the count of each region.
+type PluginCount struct {
+	Column  string         `json:"column"`
+	Count   *int           `json:"count"`
+	Regions map[string]int `json:"regions"`
+}
+
+// Value returns the count. If the plugin did not supply the count, then it is the
+// sum of the counts of the supplied regions. (The counts of any other regions are
+// ignored.)
+func (pc *PluginCount) Value(regions []string) int {
+	// Did the plugin supply the count?
+	if pc.Count != nil {
+		return *pc.Count
+	}
+
+	// Add up the counts of our regions
+	count := 0
+	for _, regionName := range regions {
+		count += pc.Regions[regionName]
+	}
+
+	return count
+}
+
+// PluginResult holds the name of a column and its value, as returned by a plugin.
+type PluginResult struct {
+	Column string
+	Value  int
+}
+
+// PluginExecutor is an interface for running a plugin: it supplies the request to
+// the plugin and returns its response.
+type PluginExecutor interface {
+	Execute(path string, request []byte) ([]byte, error)
+}
+
+// ProcessPluginExecutor runs each plugin in its own process, killing it if it runs
+// for longer than the supplied timeout.
+type ProcessPluginExecutor struct {
+	Timeout time.Duration
+}
+
+// Execute runs the plugin at the supplied path, writing the request to its
+// standard input and returning its standard output. If it fails, then whatever it
+// wrote to its standard error is included in the error.
+func (ppe *ProcessPluginExecutor) Execute(path string, request []byte) ([]byte, error) {
+	// Run the plugin for no longer than our timeout
+	ctx, cancel := context.WithTimeout(context.Background(), ppe.Timeout)
+	defer cancel()
+
+	// Connect the plugin's input and output
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// Did the plugin fail?
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%v: %s", err, message)
+		}
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// The extensions of the files that Windows can run. (Windows has no executable
+// permission bits.)
+var windowsExecutableExtensions = map[string]bool{
+	".exe": true,
+	".bat": true,
+	".cmd": true,
+}
+
+// FindPlugins returns the paths of the plugins in the supplied directory: every
+// executable file, in order of name.
+func FindPlugins(dirName string) ([]string, error) {
+	return findPlugins(dirName, runtime.GOOS)
+}
+
+// Find the plugins in the supplied directory, deciding which files are executable
+// the way that the supplied operating system (GOOS) does.
+func findPlugins(dirName string, goos string) ([]string, error) {
+	// Get the files in the directory (sorted by name)
+	files, err := ioutil.ReadDir(dirName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the executable files
+	var plugins []string
+	for _, file := range files {
+		if isExecutableFile(file, goos) {
+			plugins = append(plugins, filepath.Join(dirName, file.Name()))
+		}
+	}
+
+	return plugins, nil
+}
+
+// Check whether the supplied file is executable: on Windows, by its extension;
+// elsewhere, by its permissions.
+func isExecutableFile(file os.FileInfo, goos string) bool {
+	if !file.Mode().IsRegular() {
+		return false
+	}
+	if goos == "windows" {
+		return windowsExecutableExtensions[strings.ToLower(filepath.Ext(file.Name()))]
+	}
+
+	return file.Mode().Perm()&0111 != 0
+}
+
+// RunPlugins runs each of the supplied plugins, returning the counts of all of them
+// (in order). The caller supplies the account, profile, region mode and settings
+// of the request; the rest of it is filled in from the supplied ServiceFactory.
+//
+// This method gives status back to the user via the supplied ActivityMonitor
+// instance.
+func RunPlugins(sf ServiceFactory, am ActivityMonitor, pe PluginExecutor, plugins []string, request *PluginRequest) []PluginResult {
+	// Indicate activity
+	am.StartAction("Preparing plugin request")
+
+	// Get the credentials of our session
+	value, err := sf.GetCredentials().Get()
+	if am.CheckError(err) {
+		return nil
+	}
+	request.ProtocolVersion = PluginProtocolVersion
+	request.Credentials = PluginCredentials{
+		AccessKeyID:     value.AccessKeyID,
+		SecretAccessKey: value.SecretAccessKey,
+		SessionToken:    value.SessionToken,
+	}
+
+	// Which regions are being counted?
+	request.Region = sf.GetCurrentRegion()
+	if request.AllRegions {
+		request.Regions = GetEC2Regions(sf.GetEC2InstanceService(""), am)
+	} else {
+		request.Regions = []string{request.Region}
+	}
+
+	// Construct the request document
+	requestBytes, err := json.Marshal(request)
+	if am.CheckError(err) {
+		return nil
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d regions)", color.Bold(len(request.Regions)))
+
+	// Loop through the plugins...
+	var results []PluginResult
+	for _, path := range plugins {
+		results = append(results, runPlugin(pe, am, path, requestBytes, request.Regions)...)
+	}
+
+	return results
+}
+
+// Run a single plugin, returning its counts. We stop at the first error.
+func runPlugin(pe PluginExecutor, am ActivityMonitor, path string, requestBytes []byte, regions []string) []PluginResult {
+	pluginName := filepath.Base(path)
+
+	// Indicate activity
+	am.StartAction("Running plugin %s", pluginName)
+
+	// Run the plugin
+	responseBytes, err := pe.Execute(path, requestBytes)
+	if am.CheckError(err) {
+		return nil
+	}
+
+	// Parse its response
+	var response PluginResponse
+	if err = json.Unmarshal(responseBytes, &response); err != nil {
+		am.CheckError(fmt.Errorf("plugin %s returned an invalid response: %v", pluginName, err))
+		return nil
+	}
+
+	// Check the response
+	if response.ProtocolVersion != PluginProtocolVersion {
+		am.CheckError(fmt.Errorf("plugin %s uses protocol version %d; expected %d", pluginName, response.ProtocolVersion, PluginProtocolVersion))
+		return nil
+	}
+	if len(response.Errors) > 0 {
+		am.CheckError(fmt.Errorf("plugin %s: %s", pluginName, strings.Join(response.Errors, "; ")))
+		return nil
+	}
+
+	// Collect the counts
+	var results []PluginResult
+	for _, count := range response.Counts {
+		if count.Column == "" {
+			am.CheckError(fmt.Errorf("plugin %s returned a count without a column", pluginName))
+			return nil
+		}
+		results = append(results, PluginResult{
+			Column: count.Column,
+			Value:  count.Value(regions),
+		})
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d counts)", color.Bold(len(results)))
+
+	return results
+}
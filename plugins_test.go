@@ -0,0 +1,388 @@
+/******************************************************************************
+Cloud Resource Counter
+File: plugins_test.go
+
+Summary: The Unit Test for plugins.
+******************************************************************************/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Plugin Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This is our map of plugin paths and their responses. A missing path simulates a
+// plugin that fails to run.
+var pluginResponses = map[string]string{
+	// Counts by region (including a region that is not enabled in our account) and
+	// a count for all regions
+	"plugins/widgets": `{
+		"protocolVersion": 1,
+		"counts": [
+			{"column": "# of Widgets", "regions": {"us-east-1": 3, "us-east-2": 0, "af-south-1": 2, "eu-west-1": 9}},
+			{"column": "# of Global Widgets", "count": 7}
+		]
+	}`,
+
+	// A single count by region
+	"plugins/gadgets": `{
+		"protocolVersion": 1,
+		"counts": [
+			{"column": "# of Gadgets", "regions": {"us-east-2": 4}}
+		],
+		"errors": []
+	}`,
+
+	// A response that is not JSON
+	"plugins/not-json": `# of Widgets: 3`,
+
+	// A response of a later protocol version
+	"plugins/version-2": `{"protocolVersion": 2, "counts": []}`,
+
+	// A response with errors
+	"plugins/with-errors": `{
+		"protocolVersion": 1,
+		"counts": [],
+		"errors": ["AccessDenied: not authorized to list widgets"]
+	}`,
+
+	// A count without a column
+	"plugins/no-column": `{"protocolVersion": 1, "counts": [{"count": 3}]}`,
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Plugin Executor
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This struct returns the response of each plugin from our map of responses. It
+// records the last request that it receives.
+type fakePluginExecutor struct {
+	Request *PluginRequest
+}
+
+// Simulate running the plugin at the supplied path
+func (fake *fakePluginExecutor) Execute(path string, request []byte) ([]byte, error) {
+	// Parse the request
+	fake.Request = &PluginRequest{}
+	if err := json.Unmarshal(request, fake.Request); err != nil {
+		return nil, err
+	}
+
+	// Find the plugin's response
+	response, ok := pluginResponses[path]
+	if !ok {
+		return nil, fmt.Errorf("fork/exec %s: no such file or directory", path)
+	}
+
+	return []byte(response), nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakePluginsServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName    string
+	DRResponse    *ec2.DescribeRegionsOutput
+	NoCredentials bool
+}
+
+// Return our current region
+func (fsf fakePluginsServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakePluginsServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return static credentials (which are empty, simulating an error, if requested)
+func (fsf fakePluginsServiceFactory) GetCredentials() *credentials.Credentials {
+	if fsf.NoCredentials {
+		return credentials.NewStaticCredentials("", "", "")
+	}
+
+	return credentials.NewStaticCredentials("AKIDEXAMPLE", "SECRETEXAMPLE", "TOKENEXAMPLE")
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for RunPlugins
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestRunPlugins(t *testing.T) {
+	// Describe all of our test cases: 6 failures and 3 successes
+	cases := []struct {
+		RegionName      string
+		AllRegions      bool
+		NoCredentials   bool
+		Plugins         []string
+		ExpectedResults []PluginResult
+		ExpectedRegions []string
+		ExpectError     bool
+	}{
+		{
+			RegionName: "us-east-2",
+			Plugins:    []string{"plugins/gadgets", "plugins/widgets"},
+			ExpectedResults: []PluginResult{
+				{Column: "# of Gadgets", Value: 4},
+				{Column: "# of Widgets", Value: 0},
+				{Column: "# of Global Widgets", Value: 7},
+			},
+			ExpectedRegions: []string{"us-east-2"},
+		}, {
+			RegionName: "af-south-1",
+			Plugins:    []string{"plugins/widgets"},
+			ExpectedResults: []PluginResult{
+				{Column: "# of Widgets", Value: 2},
+				{Column: "# of Global Widgets", Value: 7},
+			},
+			ExpectedRegions: []string{"af-south-1"},
+		}, {
+			RegionName: "us-east-1",
+			AllRegions: true,
+			Plugins:    []string{"plugins/widgets", "plugins/gadgets"},
+			ExpectedResults: []PluginResult{
+				{Column: "# of Widgets", Value: 5},
+				{Column: "# of Global Widgets", Value: 7},
+				{Column: "# of Gadgets", Value: 4},
+			},
+			ExpectedRegions: []string{"us-east-1", "us-east-2", "af-south-1"},
+		}, {
+			RegionName:    "us-east-1",
+			NoCredentials: true,
+			Plugins:       []string{"plugins/widgets"},
+			ExpectError:   true,
+		}, {
+			RegionName:  "us-east-1",
+			Plugins:     []string{"plugins/missing"},
+			ExpectError: true,
+		}, {
+			RegionName:  "us-east-1",
+			Plugins:     []string{"plugins/not-json"},
+			ExpectError: true,
+		}, {
+			RegionName:  "us-east-1",
+			Plugins:     []string{"plugins/version-2"},
+			ExpectError: true,
+		}, {
+			RegionName:  "us-east-1",
+			Plugins:     []string{"plugins/with-errors"},
+			ExpectError: true,
+		}, {
+			RegionName:  "us-east-1",
+			Plugins:     []string{"plugins/no-column"},
+			ExpectError: true,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakePluginsServiceFactory{
+			RegionName:    c.RegionName,
+			DRResponse:    ec2Regions,
+			NoCredentials: c.NoCredentials,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Create our fake plugin executor
+		pe := &fakePluginExecutor{}
+
+		// Invoke our RunPlugins function
+		actualResults := RunPlugins(sf, mon, pe, c.Plugins, &PluginRequest{
+			AccountID:  "123456789012",
+			AllRegions: c.AllRegions,
+			Settings: PluginSettings{
+				Concurrency: 8,
+			},
+		})
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Errorf("Expected an error to occur for %v, but it did not... :^(", c.Plugins)
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if !reflect.DeepEqual(actualResults, c.ExpectedResults) {
+			t.Errorf("Error: RunPlugins returned %v; expected %v", actualResults, c.ExpectedResults)
+		} else if !reflect.DeepEqual(pe.Request.Regions, c.ExpectedRegions) {
+			t.Errorf("Error: the plugins received regions %v; expected %v", pe.Request.Regions, c.ExpectedRegions)
+		} else if pe.Request.ProtocolVersion != PluginProtocolVersion || pe.Request.Region != c.RegionName ||
+			pe.Request.AccountID != "123456789012" || pe.Request.Settings.Concurrency != 8 ||
+			pe.Request.Credentials.AccessKeyID != "AKIDEXAMPLE" || pe.Request.Credentials.SessionToken != "TOKENEXAMPLE" {
+			t.Errorf("Error: the plugins received an unexpected request: %+v", pe.Request)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for FindPlugins
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestFindPlugins(t *testing.T) {
+	// We cannot create files with executable permissions on Windows
+	if runtime.GOOS == "windows" {
+		t.Skip("Executable permissions cannot be set on Windows")
+	}
+
+	// Create a directory of plugins (and other files)
+	dirName, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dirName)
+	files := map[string]os.FileMode{
+		"widgets":    0755,
+		"gadgets":    0700,
+		"README.md":  0644,
+		"report.EXE": 0644,
+		"sync.cmd":   0644,
+	}
+	for fileName, mode := range files {
+		if err = ioutil.WriteFile(filepath.Join(dirName, fileName), []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatalf("Unexpected error creating %s: %v", fileName, err)
+		}
+	}
+	if err = os.Mkdir(filepath.Join(dirName, "subdirectory"), 0755); err != nil {
+		t.Fatalf("Unexpected error creating subdirectory: %v", err)
+	}
+
+	// Find the plugins
+	plugins, err := FindPlugins(dirName)
+	expected := []string{filepath.Join(dirName, "gadgets"), filepath.Join(dirName, "widgets")}
+	if err != nil {
+		t.Errorf("Unexpected error occurred: %v", err)
+	} else if !reflect.DeepEqual(plugins, expected) {
+		t.Errorf("Error: FindPlugins returned %v; expected %v", plugins, expected)
+	}
+
+	// On Windows, the plugins are found by their extensions instead
+	plugins, err = findPlugins(dirName, "windows")
+	expected = []string{filepath.Join(dirName, "report.EXE"), filepath.Join(dirName, "sync.cmd")}
+	if err != nil {
+		t.Errorf("Unexpected error occurred: %v", err)
+	} else if !reflect.DeepEqual(plugins, expected) {
+		t.Errorf("Error: findPlugins (windows) returned %v; expected %v", plugins, expected)
+	}
+
+	// A missing directory is an error
+	if _, err = FindPlugins(filepath.Join(dirName, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory, but it did not occur")
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for ProcessPluginExecutor
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestProcessPluginExecutor(t *testing.T) {
+	// We need a shell to run our plugins
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script plugins cannot be run on Windows")
+	}
+
+	// Create a directory for our plugins
+	dirName, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dirName)
+
+	// Describe all of our test cases: 2 failures and 1 success
+	cases := []struct {
+		Script         string
+		ExpectedOutput string
+		ExpectedError  string
+	}{
+		{
+			Script:         "#!/bin/sh\ncat\n",
+			ExpectedOutput: `{"protocolVersion":1}`,
+		}, {
+			Script:        "#!/bin/sh\necho 'no credentials' >&2\nexit 3\n",
+			ExpectedError: "exit status 3: no credentials",
+		}, {
+			Script:        "#!/bin/sh\nexec sleep 5\n",
+			ExpectedError: "signal: killed",
+		},
+	}
+
+	// Loop through each test case
+	for index, c := range cases {
+		// Write the plugin
+		path := filepath.Join(dirName, fmt.Sprintf("plugin-%d", index))
+		if err = ioutil.WriteFile(path, []byte(c.Script), 0755); err != nil {
+			t.Fatalf("Unexpected error creating %s: %v", path, err)
+		}
+
+		// Run the plugin
+		pe := &ProcessPluginExecutor{
+			Timeout: 500 * time.Millisecond,
+		}
+		output, err := pe.Execute(path, []byte(`{"protocolVersion":1}`))
+
+		// Did we expect an error?
+		if c.ExpectedError != "" {
+			if err == nil {
+				t.Errorf("Case %d: Expected an error to occur, but it did not... :^(", index)
+			} else if !strings.Contains(err.Error(), c.ExpectedError) {
+				t.Errorf("Case %d: Unexpected error: expected %s, actual %v", index, c.ExpectedError, err)
+			}
+		} else if err != nil {
+			t.Errorf("Case %d: Unexpected error occurred: %v", index, err)
+		} else if string(output) != c.ExpectedOutput {
+			t.Errorf("Case %d: Unexpected output: expected %s, actual %s", index, c.ExpectedOutput, output)
+		}
+	}
+}
+
+// The total of each plugin count is either supplied or computed from its regions
+func TestPluginCountValue(t *testing.T) {
+	count := 7
+	regions := []string{"us-east-1", "us-west-2"}
+	cases := []struct {
+		Count    PluginCount
+		Expected int
+	}{
+		{PluginCount{Count: &count, Regions: map[string]int{"us-east-1": 1}}, 7},
+		{PluginCount{Regions: map[string]int{"us-east-1": 1, "us-west-2": 2, "eu-west-1": 4}}, 3},
+		{PluginCount{}, 0},
+	}
+
+	for index, c := range cases {
+		if actual := c.Count.Value(regions); actual != c.Expected {
+			t.Errorf("Case %d: Value returned %d; expected %d", index, actual, c.Expected)
+		}
+	}
+}
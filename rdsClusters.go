@@ -0,0 +1,189 @@
+/******************************************************************************
+Cloud Resource Counter
+File: rdsClusters.go
+
+Summary: Provides a count of Aurora clusters (provisioned and serverless) and
+         of RDS instances (writers and read replicas), broken down by engine.
+******************************************************************************/
+
+package main
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	color "github.com/logrusorgru/aurora"
+)
+
+// RDSEngineFamilies lists the engine families that we report on, in the order in
+// which they are reported. Any engine not in this list is counted as "other".
+var RDSEngineFamilies = []string{
+	"aurora-mysql",
+	"aurora-postgresql",
+	"mariadb",
+	"mysql",
+	"oracle",
+	"postgres",
+	"sqlserver",
+	"other",
+}
+
+// RDSClusterCounts holds the number of Aurora clusters and RDS instances. Every
+// available DB instance is either a provisioned (writer or standalone) instance or
+// a read replica. Aurora Serverless (v1) clusters have no instances, so they are
+// counted separately. The Engines map counts the instances and serverless clusters
+// by engine family (see RDSEngineFamilies).
+type RDSClusterCounts struct {
+	AuroraClusters       int
+	ServerlessClusters   int
+	ProvisionedInstances int
+	ReadReplicas         int
+	Engines              map[string]int
+}
+
+// Add the supplied counts into our struct.
+func (rcc *RDSClusterCounts) Add(other RDSClusterCounts) {
+	rcc.AuroraClusters += other.AuroraClusters
+	rcc.ServerlessClusters += other.ServerlessClusters
+	rcc.ProvisionedInstances += other.ProvisionedInstances
+	rcc.ReadReplicas += other.ReadReplicas
+
+	// Merge the engine counts
+	for family, count := range other.Engines {
+		if rcc.Engines == nil {
+			rcc.Engines = make(map[string]int)
+		}
+		rcc.Engines[family] += count
+	}
+}
+
+// RDSClusters retrieves the counts of Aurora clusters and RDS instances either for
+// all regions (allRegions is true) or the region associated with the session. Like
+// RDSInstances, only clusters and instances whose status is "available" are counted.
+// This method gives status back to the user via the supplied ActivityMonitor instance.
+func RDSClusters(sf ServiceFactory, am ActivityMonitor, allRegions bool) RDSClusterCounts {
+	// Indicate activity
+	am.StartAction("Retrieving RDS cluster counts")
+
+	// Should we get the counts for all regions?
+	var clusterCounts RDSClusterCounts
+	if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the RDS cluster counts for a specific region
+			clusterCounts.Add(rdsClustersForSingleRegion(sf.GetRDSInstanceService(regionName), am))
+		}
+	} else {
+		// Get the RDS cluster counts for the region selected by this session
+		clusterCounts = rdsClustersForSingleRegion(sf.GetRDSInstanceService(""), am)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d clusters, %d serverless, %d instances, %d read replicas)",
+		color.Bold(clusterCounts.AuroraClusters), color.Bold(clusterCounts.ServerlessClusters),
+		color.Bold(clusterCounts.ProvisionedInstances), color.Bold(clusterCounts.ReadReplicas))
+
+	return clusterCounts
+}
+
+// Get the RDS cluster counts for a single region
+func rdsClustersForSingleRegion(rdsis *RDSInstanceService, am ActivityMonitor) RDSClusterCounts {
+	// Indicate activity
+	am.Message(".")
+
+	// Inspect the clusters, remembering which instances are (cluster) readers
+	clusterCounts := RDSClusterCounts{
+		Engines: make(map[string]int),
+	}
+	readers := make(map[string]bool)
+	err := rdsis.InspectClusters(&rds.DescribeDBClustersInput{}, func(page *rds.DescribeDBClustersOutput, lastPage bool) bool {
+		// Loop through the DB Clusters...
+		for _, dbc := range page.DBClusters {
+			// Record the readers of the cluster
+			for _, member := range dbc.DBClusterMembers {
+				if !aws.BoolValue(member.IsClusterWriter) {
+					readers[aws.StringValue(member.DBInstanceIdentifier)] = true
+				}
+			}
+
+			// We only count available Aurora clusters
+			engine := aws.StringValue(dbc.Engine)
+			if aws.StringValue(dbc.Status) != "available" || !strings.HasPrefix(engine, "aurora") {
+				continue
+			}
+
+			// Serverless (v1) clusters have no instances, so we count them by engine here
+			if aws.StringValue(dbc.EngineMode) == "serverless" {
+				clusterCounts.ServerlessClusters++
+				clusterCounts.Engines[rdsEngineFamily(engine)]++
+			} else {
+				clusterCounts.AuroraClusters++
+			}
+		}
+
+		return true
+	})
+
+	// Check for error
+	if am.CheckError(err) {
+		return clusterCounts
+	}
+
+	// Inspect the instances
+	err = rdsis.InspectInstances(&rds.DescribeDBInstancesInput{}, func(page *rds.DescribeDBInstancesOutput, lastPage bool) bool {
+		// Loop through the DB Instances...
+		for _, dbi := range page.DBInstances {
+			if aws.StringValue(dbi.DBInstanceStatus) != "available" {
+				continue
+			}
+
+			// DocumentDB and Neptune instances are also described by RDS, but they
+			// are counted with the other data services (see DataServices)
+			if engine := aws.StringValue(dbi.Engine); engine == "docdb" || engine == "neptune" {
+				continue
+			}
+
+			// Is this a reader (either of a cluster or of another instance)?
+			if readers[aws.StringValue(dbi.DBInstanceIdentifier)] || dbi.ReadReplicaSourceDBInstanceIdentifier != nil {
+				clusterCounts.ReadReplicas++
+			} else {
+				clusterCounts.ProvisionedInstances++
+			}
+			clusterCounts.Engines[rdsEngineFamily(aws.StringValue(dbi.Engine))]++
+		}
+
+		return true
+	})
+
+	// Check for error
+	am.CheckError(err)
+
+	return clusterCounts
+}
+
+// Convert an RDS engine name (e.g., "oracle-ee" or "aurora") into one of our
+// engine families.
+func rdsEngineFamily(engine string) string {
+	switch {
+	case engine == "aurora" || engine == "aurora-mysql":
+		return "aurora-mysql"
+	case engine == "aurora-postgresql":
+		return "aurora-postgresql"
+	case engine == "mariadb":
+		return "mariadb"
+	case engine == "mysql":
+		return "mysql"
+	case engine == "postgres":
+		return "postgres"
+	case strings.HasPrefix(engine, "oracle") || strings.HasPrefix(engine, "custom-oracle"):
+		return "oracle"
+	case strings.HasPrefix(engine, "sqlserver") || strings.HasPrefix(engine, "custom-sqlserver"):
+		return "sqlserver"
+	default:
+		return "other"
+	}
+}
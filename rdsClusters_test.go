@@ -0,0 +1,309 @@
+/******************************************************************************
+Cloud Resource Counter
+File: rdsClusters_test.go
+
+Summary: The Unit Test for rdsClusters.
+******************************************************************************/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake RDS Cluster Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// For our tests, we are combining the clusters and instances of each region
+type RDSClusterInfo struct {
+	ClusterOutputs  []*rds.DescribeDBClustersOutput
+	InstanceOutputs []*rds.DescribeDBInstancesOutput
+}
+
+// Helper function to construct a DB cluster
+func dbCluster(engine string, engineMode string, status string, members ...*rds.DBClusterMember) *rds.DBCluster {
+	return &rds.DBCluster{
+		Engine:           aws.String(engine),
+		EngineMode:       aws.String(engineMode),
+		Status:           aws.String(status),
+		DBClusterMembers: members,
+	}
+}
+
+// Helper function to construct a DB cluster member
+func dbClusterMember(instanceID string, isWriter bool) *rds.DBClusterMember {
+	return &rds.DBClusterMember{
+		DBInstanceIdentifier: aws.String(instanceID),
+		IsClusterWriter:      aws.Bool(isWriter),
+	}
+}
+
+// Helper function to construct a DB instance
+func dbInstance(instanceID string, engine string, status string) *rds.DBInstance {
+	return &rds.DBInstance{
+		DBInstanceIdentifier: aws.String(instanceID),
+		Engine:               aws.String(engine),
+		DBInstanceStatus:     aws.String(status),
+	}
+}
+
+// This is our map of regions and the clusters and instances in each
+var rdsClustersPerRegion = map[string]*RDSClusterInfo{
+	// US-EAST-1 illustrates a case where DescribeDBClustersPages returns two pages.
+	// First page: an Aurora PostgreSQL cluster (1 writer, 1 reader) and an Aurora
+	// Serverless (v1) MySQL cluster.
+	// Second page: a DocumentDB cluster and a stopped Aurora cluster (neither counted).
+	// There are 9 instances: the 2 Aurora instances, a MySQL instance with a read
+	// replica, an Oracle instance, a Db2 instance, a stopped SQL Server instance (not
+	// counted) and a DocumentDB and a Neptune instance (not counted, as they are
+	// counted as data services).
+	"us-east-1": &RDSClusterInfo{
+		ClusterOutputs: []*rds.DescribeDBClustersOutput{
+			&rds.DescribeDBClustersOutput{
+				DBClusters: []*rds.DBCluster{
+					dbCluster("aurora-postgresql", "provisioned", "available",
+						dbClusterMember("pg-1", true), dbClusterMember("pg-2", false)),
+					dbCluster("aurora-mysql", "serverless", "available"),
+				},
+			},
+			&rds.DescribeDBClustersOutput{
+				DBClusters: []*rds.DBCluster{
+					dbCluster("docdb", "provisioned", "available", dbClusterMember("docs-1", true)),
+					dbCluster("aurora", "provisioned", "stopped"),
+				},
+			},
+		},
+		InstanceOutputs: []*rds.DescribeDBInstancesOutput{
+			&rds.DescribeDBInstancesOutput{
+				DBInstances: []*rds.DBInstance{
+					dbInstance("pg-1", "aurora-postgresql", "available"),
+					dbInstance("pg-2", "aurora-postgresql", "available"),
+					dbInstance("my-1", "mysql", "available"),
+					&rds.DBInstance{
+						DBInstanceIdentifier:                  aws.String("my-1-replica"),
+						Engine:                                aws.String("mysql"),
+						DBInstanceStatus:                      aws.String("available"),
+						ReadReplicaSourceDBInstanceIdentifier: aws.String("my-1"),
+					},
+					dbInstance("ora-1", "oracle-ee", "available"),
+					dbInstance("db2-1", "db2-se", "available"),
+					dbInstance("docs-1", "docdb", "available"),
+					dbInstance("graph-1", "neptune", "available"),
+					dbInstance("sql-1", "sqlserver-se", "stopped"),
+				},
+			},
+		},
+	},
+	// US-EAST-2 has no clusters and 2 standalone instances (SQL Server and PostgreSQL)
+	"us-east-2": &RDSClusterInfo{
+		ClusterOutputs: []*rds.DescribeDBClustersOutput{
+			&rds.DescribeDBClustersOutput{},
+		},
+		InstanceOutputs: []*rds.DescribeDBInstancesOutput{
+			&rds.DescribeDBInstancesOutput{
+				DBInstances: []*rds.DBInstance{
+					dbInstance("sql-2", "sqlserver-ee", "available"),
+					dbInstance("pg-3", "postgres", "available"),
+				},
+			},
+		},
+	},
+	// AF-SOUTH-1 has no clusters and no instances
+	"af-south-1": &RDSClusterInfo{
+		ClusterOutputs: []*rds.DescribeDBClustersOutput{
+			&rds.DescribeDBClustersOutput{},
+		},
+		InstanceOutputs: []*rds.DescribeDBInstancesOutput{
+			&rds.DescribeDBInstancesOutput{},
+		},
+	},
+	// AF-SOUTH-2 simulates a failure to describe the instances
+	"af-south-2": &RDSClusterInfo{
+		ClusterOutputs: []*rds.DescribeDBClustersOutput{
+			&rds.DescribeDBClustersOutput{},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeRDSClusterServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Return our current region
+func (fsf fakeRDSClusterServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeRDSClusterServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Implement a way to return a RDSInstanceService which is associated with the supplied
+// region.
+func (fsf fakeRDSClusterServiceFactory) GetRDSInstanceService(regionName string) *RDSInstanceService {
+	// If the caller failed to specify a region, then use what is associated with our factory
+	var resolvedRegionName string
+	if regionName == "" {
+		resolvedRegionName = fsf.RegionName
+	} else {
+		resolvedRegionName = regionName
+	}
+
+	// Construct a fake service from our region's clusters and instances (if any)
+	fake := &fakeRDSService{}
+	if clusterInfo, ok := rdsClustersPerRegion[resolvedRegionName]; ok {
+		fake.DDBCResponse = clusterInfo.ClusterOutputs
+		fake.DDBIResponse = clusterInfo.InstanceOutputs
+	}
+
+	return &RDSInstanceService{
+		Client: fake,
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for RDSClusters
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestRDSClusters(t *testing.T) {
+	// Describe all of our test cases: 2 failures and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts RDSClusterCounts
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: RDSClusterCounts{
+				AuroraClusters:       1,
+				ServerlessClusters:   1,
+				ProvisionedInstances: 4,
+				ReadReplicas:         2,
+				Engines: map[string]int{
+					"aurora-mysql":      1,
+					"aurora-postgresql": 2,
+					"mysql":             2,
+					"oracle":            1,
+					"other":             1,
+				},
+			},
+		}, {
+			RegionName: "us-east-2",
+			ExpectedCounts: RDSClusterCounts{
+				ProvisionedInstances: 2,
+				Engines: map[string]int{
+					"postgres":  1,
+					"sqlserver": 1,
+				},
+			},
+		}, {
+			RegionName: "af-south-1",
+			ExpectedCounts: RDSClusterCounts{
+				Engines: map[string]int{},
+			},
+		}, {
+			RegionName:  "af-south-2",
+			ExpectError: true,
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions: true,
+			ExpectedCounts: RDSClusterCounts{
+				AuroraClusters:       1,
+				ServerlessClusters:   1,
+				ProvisionedInstances: 6,
+				ReadReplicas:         2,
+				Engines: map[string]int{
+					"aurora-mysql":      1,
+					"aurora-postgresql": 2,
+					"mysql":             2,
+					"oracle":            1,
+					"other":             1,
+					"postgres":          1,
+					"sqlserver":         1,
+				},
+			},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeRDSClusterServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our RDS Clusters function
+		actualCounts := RDSClusters(sf, mon, c.AllRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if !reflect.DeepEqual(actualCounts, c.ExpectedCounts) {
+			t.Errorf("Error: RDSClusters returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for rdsEngineFamily
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestRDSEngineFamily(t *testing.T) {
+	// Describe all of our test cases
+	cases := map[string]string{
+		"aurora":              "aurora-mysql",
+		"aurora-mysql":        "aurora-mysql",
+		"aurora-postgresql":   "aurora-postgresql",
+		"mariadb":             "mariadb",
+		"mysql":               "mysql",
+		"postgres":            "postgres",
+		"oracle-ee":           "oracle",
+		"oracle-se2-cdb":      "oracle",
+		"custom-oracle-ee":    "oracle",
+		"sqlserver-web":       "sqlserver",
+		"custom-sqlserver-ee": "sqlserver",
+		"neptune":             "other",
+	}
+
+	// Loop through each test case
+	for engine, expectedFamily := range cases {
+		if actualFamily := rdsEngineFamily(engine); actualFamily != expectedFamily {
+			t.Errorf("Error: rdsEngineFamily(%s) returned %s; expected %s", engine, actualFamily, expectedFamily)
+		}
+	}
+}
@@ -93,12 +93,13 @@ var rdsInstancesPerRegion = map[string][]*rds.DescribeDBInstancesOutput{
 // Fake RDS Service
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 
-// To use this struct, the caller must supply a DescribeDBInstances slice. If
-// it is missing, it will trigger the mock function to simulate an error from
-// the corresponding function.
+// To use this struct, the caller must supply a DescribeDBInstances slice (and a
+// DescribeDBClusters slice to describe clusters). If either is missing, it will
+// trigger the corresponding mock function to simulate an error.
 type fakeRDSService struct {
 	rdsiface.RDSAPI
 	DDBIResponse []*rds.DescribeDBInstancesOutput
+	DDBCResponse []*rds.DescribeDBClustersOutput
 }
 
 // Simulate the DescribeDBInstancesPages function
@@ -133,6 +134,36 @@ func (fake *fakeRDSService) DescribeDBInstancesPages(input *rds.DescribeDBInstan
 	return nil
 }
 
+// Simulate the DescribeDBClustersPages function
+func (fake *fakeRDSService) DescribeDBClustersPages(input *rds.DescribeDBClustersInput, fn func(*rds.DescribeDBClustersOutput, bool) bool) error {
+	// If the supplied response is nil, then simulate an error
+	if fake.DDBCResponse == nil {
+		return errors.New("DescribeDBClustersPages encountered an unexpected error: 5678")
+	}
+
+	// Apply filtering to the supplied response
+	// NOTE: I have not implemented this feature as our code does not require it.
+	if input.DBClusterIdentifier != nil || input.Filters != nil {
+		return errors.New("The unit test does not support a DescribeDBClustersInput other than 'zero' (no parameters)")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.DDBCResponse {
+		// Are we looking at the last "page" of our output?
+		lastPage := index == len(fake.DDBCResponse)-1
+
+		// Invoke our fn
+		cont := fn(output, lastPage)
+
+		// Shall we exit our loop?
+		if !cont {
+			break
+		}
+	}
+
+	return nil
+}
+
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 // Fake Service Factory
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -140,23 +171,16 @@ func (fake *fakeRDSService) DescribeDBInstancesPages(input *rds.DescribeDBInstan
 // This structure simulates the AWS Service Factory by storing some pregenerated
 // responses (that would come from AWS).
 type fakeRDSServiceFactory struct {
+	baseFakeServiceFactory
 	RegionName string
 	DRResponse *ec2.DescribeRegionsOutput
 }
 
-// Don't need to implement
-func (fsf fakeRDSServiceFactory) Init() {}
-
 // Return our current region
 func (fsf fakeRDSServiceFactory) GetCurrentRegion() string {
 	return fsf.RegionName
 }
 
-// Don't need to implement
-func (fsf fakeRDSServiceFactory) GetAccountIDService() *AccountIDService {
-	return nil
-}
-
 // This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
 // only.
 func (fsf fakeRDSServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
@@ -185,26 +209,6 @@ func (fsf fakeRDSServiceFactory) GetRDSInstanceService(regionName string) *RDSIn
 	}
 }
 
-// Don't need to implement
-func (fsf fakeRDSServiceFactory) GetS3Service() *S3Service {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeRDSServiceFactory) GetLambdaService(string) *LambdaService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeRDSServiceFactory) GetContainerService(string) *ContainerService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeRDSServiceFactory) GetLightsailService(string) *LightsailService {
-	return nil
-}
-
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 // Unit Test for RDSInstances
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -12,14 +12,23 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"os"
 )
 
 // Results is a struct that collects rows of data and writes them to the supplied
 // file in CSV format.
+//
+// If the rows are being appended to an existing file, then AppendHeaders holds the
+// column names of that file. The rows are only saved if they have the same columns
+// (otherwise, their values would not line up with the file's column names).
 type Results struct {
-	Rows         [][]string
-	StoreHeaders bool
-	Writer       io.Writer
+	Rows          [][]string
+	StoreHeaders  bool
+	AppendHeaders []string
+	Writer        io.Writer
+
+	// The column names of the first row of values
+	columnNames []string
 }
 
 // Init performs one-time initialization on the results struct.
@@ -38,11 +47,17 @@ func (r *Results) NewRow() {
 
 // Append the supplied column name and row value into our struct.
 func (r *Results) Append(columnName string, rowValue interface{}) {
-	// Are we storing column names?
-	if r.StoreHeaders {
+	// Are we storing column names? (They are stored along with the first row of values.)
+	if r.StoreHeaders && len(r.Rows) == 2 {
 		r.Rows[0] = append(r.Rows[0], columnName)
 	}
 
+	// Remember the column names of the first row of values (even if we are not
+	// storing them)
+	if (r.StoreHeaders && len(r.Rows) == 2) || (!r.StoreHeaders && len(r.Rows) == 1) {
+		r.columnNames = append(r.columnNames, columnName)
+	}
+
 	// Append our value to the last row
 	r.Rows[len(r.Rows)-1] = append(r.Rows[len(r.Rows)-1], fmt.Sprintf("%v", rowValue))
 }
@@ -57,6 +72,14 @@ func (r *Results) Save(am ActivityMonitor) {
 	// Indicate activity
 	am.StartAction("Writing to file")
 
+	// Are we appending to a file with different columns?
+	if len(r.AppendHeaders) > 0 && len(r.columnNames) > 0 {
+		if mismatch := describeColumnMismatch(r.AppendHeaders, r.columnNames); mismatch != "" {
+			am.ActionError("Error: The columns of these results do not match those of the file being appended to (%s). Specify a different output file.", mismatch)
+			return
+		}
+	}
+
 	// Get the CSV Writer
 	writer := csv.NewWriter(r.Writer)
 
@@ -69,3 +92,38 @@ func (r *Results) Save(am ActivityMonitor) {
 	// Indicate success
 	am.EndAction("OK")
 }
+
+// ReadResultsHeaders returns the column names (the first row) of an existing CSV
+// file, so that rows that are appended to it can be checked against them. If the
+// file is empty, then nil is returned.
+func ReadResultsHeaders(fileName string) ([]string, error) {
+	// Open the file
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Read its first row
+	headers, err := csv.NewReader(file).Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+
+	return headers, err
+}
+
+// Describe the first difference between the expected and actual column names, or
+// return the empty string if they are the same.
+func describeColumnMismatch(expected []string, actual []string) string {
+	for index := 0; index < len(expected) && index < len(actual); index++ {
+		if expected[index] != actual[index] {
+			return fmt.Sprintf("column %d is %q, but the file has %q", index+1, actual[index], expected[index])
+		}
+	}
+	if len(expected) != len(actual) {
+		return fmt.Sprintf("there are %d columns, but the file has %d", len(actual), len(expected))
+	}
+
+	return ""
+}
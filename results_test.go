@@ -10,10 +10,16 @@ package main
 
 import (
 	"encoding/csv"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/expel-io/cloud-resource-counter/mock"
 )
 
@@ -80,6 +86,13 @@ func TestResultsAppend(t *testing.T) {
 	results.Append("col3", 456)
 	results.Append("col4", 789)
 
+	// ...and a second row of values (which does not repeat the column names)
+	results.NewRow()
+	results.Append("col1", "row2_col1")
+	results.Append("col2", 321)
+	results.Append("col3", 654)
+	results.Append("col4", 987)
+
 	// Create our mock activity monitor
 	mon := mock.ActivityMonitorImpl{}
 
@@ -100,6 +113,8 @@ func TestResultsAppend(t *testing.T) {
 			"col1", "col2", "col3", "col4",
 		}, {
 			"row1_col1", "123", "456", "789",
+		}, {
+			"row2_col1", "321", "654", "987",
 		},
 	}
 
@@ -169,3 +184,110 @@ func TestResultsNoSave(t *testing.T) {
 		t.Errorf("Encountered an error during Results.Save: %s", mon.ErrorMessage)
 	}
 }
+
+func TestResultsAppendHeaders(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		AppendHeaders []string
+		ExpectError   bool
+	}{
+		{
+			AppendHeaders: []string{"col1", "col2", "col3"},
+		},
+		{
+			// A file without any rows (and so without any columns)
+		},
+		{
+			AppendHeaders: []string{"col1", "col3", "col2"},
+			ExpectError:   true,
+		},
+		{
+			AppendHeaders: []string{"col1", "col2"},
+			ExpectError:   true,
+		},
+		{
+			AppendHeaders: []string{"col1", "col2", "col3", "col4"},
+			ExpectError:   true,
+		},
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create an instance of Results that appends to a file
+		builder := strings.Builder{}
+		results := Results{
+			AppendHeaders: c.AppendHeaders,
+			Writer:        &builder,
+		}
+		results.Init()
+		results.NewRow()
+		results.Append("col1", "row1_col1")
+		results.Append("col2", 123)
+		results.Append("col3", 456)
+
+		// Save to our mock Writer
+		mon := mock.ActivityMonitorImpl{}
+		results.Save(&mon)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			if !mon.ErrorOccured {
+				t.Errorf("Expected an error to occur appending to %v, but it did not", c.AppendHeaders)
+			} else if builder.Len() != 0 {
+				t.Errorf("Error: Results.Save wrote %q despite mismatched columns %v", builder.String(), c.AppendHeaders)
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if builder.String() != "row1_col1,123,456\n" {
+			t.Errorf("Error: Results.Save wrote %q; expected %q", builder.String(), "row1_col1,123,456\n")
+		}
+	}
+}
+
+func TestReadResultsHeaders(t *testing.T) {
+	// Create a directory for our files
+	dirName, err := ioutil.TempDir("", "results")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dirName)
+
+	// Create our test cases
+	cases := []struct {
+		Contents        *string
+		ExpectedHeaders []string
+		ExpectError     bool
+	}{
+		{
+			Contents:        aws.String("Account ID,Timestamp,# of EC2 Instances\n123456789012,2024-01-01T00:00:00Z,3\n"),
+			ExpectedHeaders: []string{"Account ID", "Timestamp", "# of EC2 Instances"},
+		},
+		{
+			Contents: aws.String(""),
+		},
+		{
+			ExpectError: true,
+		},
+	}
+
+	// Loop through the test cases
+	for index, c := range cases {
+		// Write the file (unless it is missing)
+		fileName := filepath.Join(dirName, fmt.Sprintf("results%d.csv", index))
+		if c.Contents != nil {
+			if err := ioutil.WriteFile(fileName, []byte(*c.Contents), 0644); err != nil {
+				t.Fatalf("Unexpected error creating %s: %v", fileName, err)
+			}
+		}
+
+		// Read its headers
+		actualHeaders, err := ReadResultsHeaders(fileName)
+
+		// Did we get what we expected?
+		if c.ExpectError != (err != nil) {
+			t.Errorf("Error: ReadResultsHeaders(%s) returned error %v; expected error: %v", fileName, err, c.ExpectError)
+		} else if !reflect.DeepEqual(actualHeaders, c.ExpectedHeaders) {
+			t.Errorf("Error: ReadResultsHeaders(%s) returned %v; expected %v", fileName, actualHeaders, c.ExpectedHeaders)
+		}
+	}
+}
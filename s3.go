@@ -8,23 +8,38 @@ Summary: Provides a count of all S3 buckets.
 package main
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 
 	color "github.com/logrusorgru/aurora"
 )
 
-// S3Buckets retrieves the count of all S3 buckets in ALL REGIONS.
-// This behavior is unlike other AWS Services (e.g., EC2, Spot, RDS,
-// etc).
+// S3BucketRegions groups the names of S3 buckets by the region in which they are
+// located. The buckets whose location could not be looked up are kept in Unknown.
+type S3BucketRegions struct {
+	Regions map[string][]*string
+	Unknown []*string
+}
+
+// S3Buckets retrieves the count of all S3 buckets either for all regions
+// (allRegions is true) or the region associated with the session.
 //
-// AS SUCH, THIS COUNT WILL BE INCORRECT WHEN A SINGLE REGION IS SPECIFIED.
+// S3 lists the buckets of ALL REGIONS at once. To count the buckets of a
+// single region, we look up the location of each bucket, making at most
+// concurrency lookups at the same time. Buckets whose location cannot be
+// looked up (such as when GetBucketLocation is denied by a bucket policy) are
+// not counted, and the number of them is reported.
 //
-// It is unclear if displaying counts of distinct regions will be part
-// of the final CLI.
+// The bucket locations are also returned (so that they can be reused by
+// S3StorageMetrics). They are looked up when a single region is selected or
+// lookupRegions is true.
 //
 // This method gives status back to the user via the supplied
 // ActivityMonitor instance.
-func S3Buckets(sf ServiceFactory, am ActivityMonitor, allRegions bool) int {
+func S3Buckets(sf ServiceFactory, am ActivityMonitor, allRegions bool, lookupRegions bool, concurrency int) (int, S3BucketRegions) {
 	// Create a new instance of the S3 (abstract) service
 	svc := sf.GetS3Service()
 
@@ -39,20 +54,79 @@ func S3Buckets(sf ServiceFactory, am ActivityMonitor, allRegions bool) int {
 
 	// Check for error
 	if am.CheckError(err) {
-		return 0
+		return 0, S3BucketRegions{}
 	}
 
-	// Get our count of buckets
-	count := len(result.Buckets)
+	// Do we need the location of each bucket?
+	var bucketRegions S3BucketRegions
+	if !allRegions || lookupRegions {
+		bucketRegions = s3BucketsByRegion(svc, result.Buckets, concurrency)
+	}
 
-	// Should we "qualify" our count?
-	var qualify string
-	if !allRegions && count > 0 {
-		qualify = "*"
+	// Get our count of buckets
+	var count int
+	if allRegions {
+		count = len(result.Buckets)
+	} else {
+		count = len(bucketRegions.Regions[sf.GetCurrentRegion()])
 	}
 
 	// Indicate end of activity
-	am.EndAction("OK (%d%s)", color.Bold(count), qualify)
+	am.EndAction("OK (%d%s)", color.Bold(count), s3UnknownBucketsQualifier(bucketRegions.Unknown))
+
+	return count, bucketRegions
+}
+
+// Group the names of the supplied buckets by the region in which they are located.
+func s3BucketsByRegion(s3s *S3Service, buckets []*s3.Bucket, concurrency int) S3BucketRegions {
+	// Look up the bucket locations concurrently. Each goroutine only writes to
+	// its own slot in these slices.
+	bucketRegions := make([]string, len(buckets))
+	errs := make([]error, len(buckets))
+	ForEachConcurrently(len(buckets), concurrency, func(index int) {
+		bucketRegions[index], errs[index] = s3BucketRegion(s3s, buckets[index].Name)
+	})
+
+	// Loop through the buckets...
+	regionBuckets := S3BucketRegions{
+		Regions: make(map[string][]*string),
+	}
+	for index, bucket := range buckets {
+		// Could we look up its location?
+		if errs[index] != nil {
+			regionBuckets.Unknown = append(regionBuckets.Unknown, bucket.Name)
+			continue
+		}
+
+		// Add it to its region
+		regionBuckets.Regions[bucketRegions[index]] = append(regionBuckets.Regions[bucketRegions[index]], bucket.Name)
+	}
+
+	return regionBuckets
+}
+
+// Describe the buckets whose location could not be looked up (for the end of an
+// action), or return the empty string if there are none.
+func s3UnknownBucketsQualifier(unknownBuckets []*string) string {
+	if len(unknownBuckets) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("; skipped %d buckets of unknown region: %s", len(unknownBuckets),
+		strings.Join(aws.StringValueSlice(unknownBuckets), ", "))
+}
+
+// Get the region in which the named bucket is located. This function is called
+// from multiple goroutines, so it must not use the ActivityMonitor.
+func s3BucketRegion(s3s *S3Service, bucketName *string) (string, error) {
+	// Get the location constraint of the bucket
+	output, err := s3s.GetBucketLocation(&s3.GetBucketLocationInput{
+		Bucket: bucketName,
+	})
+	if err != nil {
+		return "", err
+	}
 
-	return count
+	// Convert the location constraint (e.g., "" or "EU") into a region name
+	return s3.NormalizeBucketLocation(aws.StringValue(output.LocationConstraint)), nil
 }
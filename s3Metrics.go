@@ -0,0 +1,210 @@
+/******************************************************************************
+Cloud Resource Counter
+File: s3Metrics.go
+
+Summary: Provides the total number of objects and bytes stored in S3 buckets,
+         using the daily storage metrics that S3 sends to CloudWatch.
+******************************************************************************/
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+
+	color "github.com/logrusorgru/aurora"
+)
+
+// S3StorageTypes lists the storage types (one per storage class and its overhead)
+// for which S3 reports the daily BucketSizeBytes metric. This list needs to be
+// updated as AWS adds storage classes.
+var S3StorageTypes = []string{
+	"StandardStorage",
+	"IntelligentTieringFAStorage",
+	"IntelligentTieringIAStorage",
+	"IntelligentTieringAAStorage",
+	"IntelligentTieringAIAStorage",
+	"IntelligentTieringDAAStorage",
+	"StandardIAStorage",
+	"StandardIASizeOverhead",
+	"OneZoneIAStorage",
+	"OneZoneIASizeOverhead",
+	"ReducedRedundancyStorage",
+	"GlacierInstantRetrievalStorage",
+	"GlacierInstantRetrievalSizeOverhead",
+	"GlacierStorage",
+	"GlacierStagingStorage",
+	"GlacierObjectOverhead",
+	"GlacierS3ObjectOverhead",
+	"DeepArchiveStorage",
+	"DeepArchiveObjectOverhead",
+	"DeepArchiveS3ObjectOverhead",
+	"DeepArchiveStagingStorage",
+	"ExpressOneZone",
+}
+
+// The storage type under which S3 reports the NumberOfObjects metric
+const s3AllStorageTypes = "AllStorageTypes"
+
+// The maximum number of metric queries that can be made in one GetMetricData call
+const maxMetricDataQueries = 500
+
+// The period (in seconds) of the daily S3 storage metrics
+const s3MetricsPeriod = 24 * 60 * 60
+
+// S3 reports its storage metrics once a day (and they can be up to a day late).
+// We look back this far for the most recent data point of each metric.
+const s3MetricsLookback = 3 * 24 * time.Hour
+
+// S3StorageCounts holds the total number of objects and bytes stored in S3 buckets.
+type S3StorageCounts struct {
+	Objects int64
+	Bytes   int64
+}
+
+// Add the supplied counts into our struct.
+func (ssc *S3StorageCounts) Add(other S3StorageCounts) {
+	ssc.Objects += other.Objects
+	ssc.Bytes += other.Bytes
+}
+
+// S3StorageMetrics retrieves the total number of objects and bytes stored in the S3
+// buckets either for all regions (allRegions is true) or the region associated
+// with the session.
+//
+// S3 sends the storage metrics of a bucket to CloudWatch in the bucket's region, so
+// we use the bucket locations looked up by S3Buckets. Buckets whose location could
+// not be looked up are skipped (S3Buckets reports them).
+//
+// This method gives status back to the user via the supplied ActivityMonitor
+// instance.
+func S3StorageMetrics(sf ServiceFactory, am ActivityMonitor, allRegions bool, bucketRegions S3BucketRegions) S3StorageCounts {
+	// Indicate activity
+	am.StartAction("Retrieving S3 storage metrics")
+
+	// Get the buckets of each region
+	regionBuckets := bucketRegions.Regions
+
+	// Should we get the metrics for all regions?
+	var storageCounts S3StorageCounts
+	if allRegions {
+		// Sort the regions so that they are always visited in the same order
+		regionNames := make([]string, 0, len(regionBuckets))
+		for regionName := range regionBuckets {
+			regionNames = append(regionNames, regionName)
+		}
+		sort.Strings(regionNames)
+
+		// Loop through all of the regions with buckets
+		for _, regionName := range regionNames {
+			// Get the storage metrics for a specific region
+			storageCounts.Add(s3StorageMetricsForSingleRegion(sf.GetCloudWatchService(regionName), am, regionBuckets[regionName]))
+		}
+	} else if bucketNames := regionBuckets[sf.GetCurrentRegion()]; len(bucketNames) > 0 {
+		// Get the storage metrics for the region selected by this session
+		storageCounts = s3StorageMetricsForSingleRegion(sf.GetCloudWatchService(""), am, bucketNames)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d objects, %d bytes)", color.Bold(storageCounts.Objects), color.Bold(storageCounts.Bytes))
+
+	return storageCounts
+}
+
+// Get the storage metrics of the supplied buckets (all of which are located in the
+// region of the supplied CloudWatch service). We stop at the first error.
+func s3StorageMetricsForSingleRegion(cws *CloudWatchService, am ActivityMonitor, bucketNames []*string) S3StorageCounts {
+	// Construct a query for the number of objects in each bucket and for the
+	// bytes stored in each bucket by storage type. We remember which queries
+	// are for objects.
+	var queries []*cloudwatch.MetricDataQuery
+	objectQueries := make(map[string]bool)
+	for _, bucketName := range bucketNames {
+		id := fmt.Sprintf("q%d", len(queries))
+		objectQueries[id] = true
+		queries = append(queries, s3MetricDataQuery(id, "NumberOfObjects", bucketName, s3AllStorageTypes))
+
+		for _, storageType := range S3StorageTypes {
+			queries = append(queries, s3MetricDataQuery(fmt.Sprintf("q%d", len(queries)), "BucketSizeBytes", bucketName, storageType))
+		}
+	}
+
+	// Loop through the queries, as many at a time as allowed...
+	var storageCounts S3StorageCounts
+	endTime := time.Now()
+	for start := 0; start < len(queries); start += maxMetricDataQueries {
+		end := start + maxMetricDataQueries
+		if end > len(queries) {
+			end = len(queries)
+		}
+
+		// Indicate activity
+		am.Message(".")
+
+		// Get the most recent data point of each metric. (A metric's data points
+		// can be spread across pages; the first one we see is the most recent.)
+		seen := make(map[string]bool)
+		err := cws.GetMetricData(&cloudwatch.GetMetricDataInput{
+			MetricDataQueries: queries[start:end],
+			StartTime:         aws.Time(endTime.Add(-s3MetricsLookback)),
+			EndTime:           aws.Time(endTime),
+			ScanBy:            aws.String(cloudwatch.ScanByTimestampDescending),
+		}, func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+			for _, metricResult := range page.MetricDataResults {
+				id := aws.StringValue(metricResult.Id)
+				if seen[id] || len(metricResult.Values) == 0 {
+					continue
+				}
+				seen[id] = true
+
+				// Add the value to the appropriate total
+				value := int64(math.Round(aws.Float64Value(metricResult.Values[0])))
+				if objectQueries[id] {
+					storageCounts.Objects += value
+				} else {
+					storageCounts.Bytes += value
+				}
+			}
+
+			return true
+		})
+
+		// Check for error
+		if am.CheckError(err) {
+			break
+		}
+	}
+
+	return storageCounts
+}
+
+// Construct a query for the daily average of the named S3 metric of a bucket and
+// storage type.
+func s3MetricDataQuery(id string, metricName string, bucketName *string, storageType string) *cloudwatch.MetricDataQuery {
+	return &cloudwatch.MetricDataQuery{
+		Id: aws.String(id),
+		MetricStat: &cloudwatch.MetricStat{
+			Metric: &cloudwatch.Metric{
+				Namespace:  aws.String("AWS/S3"),
+				MetricName: aws.String(metricName),
+				Dimensions: []*cloudwatch.Dimension{
+					{
+						Name:  aws.String("BucketName"),
+						Value: bucketName,
+					},
+					{
+						Name:  aws.String("StorageType"),
+						Value: aws.String(storageType),
+					},
+				},
+			},
+			Period: aws.Int64(s3MetricsPeriod),
+			Stat:   aws.String(cloudwatch.StatisticAverage),
+		},
+	}
+}
@@ -0,0 +1,341 @@
+/******************************************************************************
+Cloud Resource Counter
+File: s3Metrics_test.go
+
+Summary: The Unit Test for s3Metrics.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake S3 Storage Metrics
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This is the most recent value of the daily metrics of each bucket (see
+// fakeS3BucketsSlice), keyed by metric name and storage type. Buckets without
+// metrics (such as empty buckets) are missing.
+var fakeS3BucketMetrics = map[string]map[string]float64{
+	"bucket1": {
+		"NumberOfObjects/AllStorageTypes":   10,
+		"BucketSizeBytes/StandardStorage":   1000,
+		"BucketSizeBytes/StandardIAStorage": 500,
+	},
+	"bucket2": {
+		"NumberOfObjects/AllStorageTypes": 5,
+		"BucketSizeBytes/StandardStorage": 200,
+	},
+	"bucket3": {
+		"NumberOfObjects/AllStorageTypes":         100,
+		"BucketSizeBytes/StandardStorage":         4000,
+		"BucketSizeBytes/GlacierStorage":          6000,
+		"BucketSizeBytes/GlacierObjectOverhead":   32,
+		"BucketSizeBytes/GlacierS3ObjectOverhead": 8,
+	},
+	"bucket4": {
+		"NumberOfObjects/AllStorageTypes": 1,
+		"BucketSizeBytes/StandardStorage": 3,
+	},
+	"bucket5": {
+		"NumberOfObjects/AllStorageTypes":             2,
+		"BucketSizeBytes/IntelligentTieringFAStorage": 20,
+		"BucketSizeBytes/IntelligentTieringIAStorage": 30,
+	},
+	"bucket7": {
+		"NumberOfObjects/AllStorageTypes": 7,
+		"BucketSizeBytes/StandardStorage": 70,
+	},
+	"bucket8": {
+		"NumberOfObjects/AllStorageTypes":    8,
+		"BucketSizeBytes/DeepArchiveStorage": 80,
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake CloudWatch Service
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This fake answers metric queries from the supplied metrics, but only for buckets
+// located in its region (see fakeS3BucketLocations). If a region is not supplied,
+// then the bucket locations are not checked. If Fail is set, it simulates an error.
+type fakeCloudWatchService struct {
+	cloudwatchiface.CloudWatchAPI
+	RegionName string
+	Fail       bool
+	Metrics    map[string]map[string]float64
+	Calls      int
+}
+
+// Simulate the GetMetricDataPages function. Each data point is returned on its own
+// page, with an older data point following the most recent one.
+func (fcw *fakeCloudWatchService) GetMetricDataPages(input *cloudwatch.GetMetricDataInput,
+	fn func(*cloudwatch.GetMetricDataOutput, bool) bool) error {
+	fcw.Calls++
+
+	// Simulate an error?
+	if fcw.Fail {
+		return errors.New("GetMetricData returns an unexpected error: 6543")
+	}
+
+	// Like AWS, we limit the number of queries
+	if len(input.MetricDataQueries) > maxMetricDataQueries {
+		return fmt.Errorf("GetMetricData received too many queries: %d", len(input.MetricDataQueries))
+	}
+
+	// Find the data point of each query
+	var pages []*cloudwatch.GetMetricDataOutput
+	for _, query := range input.MetricDataQueries {
+		// Get the bucket name and storage type of the query
+		var bucketName, storageType string
+		for _, dimension := range query.MetricStat.Metric.Dimensions {
+			switch aws.StringValue(dimension.Name) {
+			case "BucketName":
+				bucketName = aws.StringValue(dimension.Value)
+			case "StorageType":
+				storageType = aws.StringValue(dimension.Value)
+			}
+		}
+
+		// Is the bucket in a different region?
+		if fcw.RegionName != "" && s3.NormalizeBucketLocation(fakeS3BucketLocations[bucketName]) != fcw.RegionName {
+			continue
+		}
+
+		// Do we have a data point?
+		value, ok := fcw.Metrics[bucketName][aws.StringValue(query.MetricStat.Metric.MetricName)+"/"+storageType]
+		if !ok {
+			continue
+		}
+
+		// Return the most recent data point, followed by an older one
+		pages = append(pages, &cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []*cloudwatch.MetricDataResult{
+				{
+					Id:     query.Id,
+					Values: []*float64{aws.Float64(value)},
+				},
+			},
+		}, &cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []*cloudwatch.MetricDataResult{
+				{
+					Id:     query.Id,
+					Values: []*float64{aws.Float64(value + 1000000)},
+				},
+			},
+		})
+	}
+
+	// Invoke the function for each page
+	if len(pages) == 0 {
+		fn(&cloudwatch.GetMetricDataOutput{}, true)
+	}
+	for index, page := range pages {
+		if !fn(page, index == len(pages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeS3MetricsServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	LBResponse *s3.ListBucketsOutput
+	FailRegion string
+}
+
+// Return our current region
+func (fsf fakeS3MetricsServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// Simply return our fake S3 Service
+func (fsf fakeS3MetricsServiceFactory) GetS3Service() *S3Service {
+	return &S3Service{
+		Client: &fakeS3Service{
+			LBResponse: fsf.LBResponse,
+		},
+	}
+}
+
+// Return our fake CloudWatch service for the supplied region (or our current region)
+func (fsf fakeS3MetricsServiceFactory) GetCloudWatchService(regionName string) *CloudWatchService {
+	if regionName == "" {
+		regionName = fsf.RegionName
+	}
+
+	return &CloudWatchService{
+		Client: &fakeCloudWatchService{
+			RegionName: regionName,
+			Fail:       regionName == fsf.FailRegion,
+			Metrics:    fakeS3BucketMetrics,
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for S3StorageMetrics
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestS3StorageMetrics(t *testing.T) {
+	// Describe all of our test cases: 1 failure and 6 successes
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		Buckets        *s3.ListBucketsOutput
+		FailRegion     string
+		ExpectedCounts S3StorageCounts
+		ExpectError    bool
+	}{
+		{
+			AllRegions: true,
+			Buckets:    fakeS3BucketsSlice,
+			ExpectedCounts: S3StorageCounts{
+				Objects: 133,
+				Bytes:   11943,
+			},
+		}, {
+			RegionName: "us-east-1",
+			Buckets:    fakeS3BucketsSlice,
+			ExpectedCounts: S3StorageCounts{
+				Objects: 15,
+				Bytes:   1700,
+			},
+		}, {
+			RegionName: "us-east-2",
+			Buckets:    fakeS3BucketsSlice,
+			ExpectedCounts: S3StorageCounts{
+				Objects: 108,
+				Bytes:   10120,
+			},
+		}, {
+			RegionName: "eu-west-1",
+			Buckets:    fakeS3BucketsSlice,
+			ExpectedCounts: S3StorageCounts{
+				Objects: 3,
+				Bytes:   53,
+			},
+		}, {
+			RegionName: "ap-south-1",
+			Buckets:    fakeS3BucketsSlice,
+		}, {
+			RegionName: "us-east-1",
+			Buckets: &s3.ListBucketsOutput{
+				Buckets: []*s3.Bucket{
+					{
+						Name: aws.String("bucket1"),
+					},
+					{
+						Name: aws.String("unknown-bucket"),
+					},
+				},
+			},
+			ExpectedCounts: S3StorageCounts{
+				Objects: 10,
+				Bytes:   1500,
+			},
+		}, {
+			AllRegions:  true,
+			Buckets:     fakeS3BucketsSlice,
+			FailRegion:  "eu-west-1",
+			ExpectError: true,
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeS3MetricsServiceFactory{
+			RegionName: c.RegionName,
+			LBResponse: c.Buckets,
+			FailRegion: c.FailRegion,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Look up the bucket locations (as S3Buckets does for us)
+		_, bucketRegions := S3Buckets(sf, mon, c.AllRegions, true, 3)
+		if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred in S3Buckets: %s", mon.ErrorMessage)
+			continue
+		}
+
+		// Invoke our S3 Storage Metrics function
+		actualCounts := S3StorageMetrics(sf, mon, c.AllRegions, bucketRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: S3StorageMetrics returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for s3StorageMetricsForSingleRegion
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestS3StorageMetricsManyBuckets(t *testing.T) {
+	// Create enough buckets that their queries do not fit in a single call
+	metrics := make(map[string]map[string]float64)
+	var bucketNames []*string
+	for i := 0; i < 30; i++ {
+		bucketName := fmt.Sprintf("big-bucket%d", i)
+		bucketNames = append(bucketNames, aws.String(bucketName))
+		metrics[bucketName] = map[string]float64{
+			"NumberOfObjects/AllStorageTypes": 1,
+			"BucketSizeBytes/StandardStorage": 10,
+		}
+	}
+
+	// Create our fake CloudWatch service (which does not check bucket locations)
+	fcw := &fakeCloudWatchService{
+		Metrics: metrics,
+	}
+
+	// Create a mock activity monitor
+	mon := &mock.ActivityMonitorImpl{}
+
+	// Invoke our function
+	actualCounts := s3StorageMetricsForSingleRegion(&CloudWatchService{Client: fcw}, mon, bucketNames)
+
+	// Check our results
+	expectedCounts := S3StorageCounts{
+		Objects: 30,
+		Bytes:   300,
+	}
+	if mon.ErrorOccured {
+		t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+	} else if actualCounts != expectedCounts {
+		t.Errorf("Error: s3StorageMetricsForSingleRegion returned %+v; expected %+v", actualCounts, expectedCounts)
+	} else if fcw.Calls != 2 {
+		t.Errorf("Error: GetMetricData was called %d times; expected 2", fcw.Calls)
+	}
+}
@@ -9,6 +9,8 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -51,6 +53,19 @@ var fakeS3BucketsSlice = &s3.ListBucketsOutput{
 	},
 }
 
+// This is the location constraint of each bucket. (US-EAST-1 has no location
+// constraint; "EU" is the legacy name of EU-WEST-1.)
+var fakeS3BucketLocations = map[string]string{
+	"bucket1": "",
+	"bucket2": "",
+	"bucket3": "us-east-2",
+	"bucket4": "EU",
+	"bucket5": "eu-west-1",
+	"bucket6": "",
+	"bucket7": "af-south-1",
+	"bucket8": "us-east-2",
+}
+
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 // Fake S3 Service
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -72,6 +87,23 @@ func (fs3 *fakeS3Service) ListBuckets(input *s3.ListBucketsInput) (*s3.ListBucke
 	return fs3.LBResponse, nil
 }
 
+// Simulate the GetBucketLocation function
+func (fs3 *fakeS3Service) GetBucketLocation(input *s3.GetBucketLocationInput) (*s3.GetBucketLocationOutput, error) {
+	// Find the location of the bucket
+	location, ok := fakeS3BucketLocations[aws.StringValue(input.Bucket)]
+	if !ok {
+		return nil, fmt.Errorf("GetBucketLocation could not find bucket: %s", aws.StringValue(input.Bucket))
+	}
+
+	// Like AWS, we return a nil location constraint for US-EAST-1
+	output := &s3.GetBucketLocationOutput{}
+	if location != "" {
+		output.LocationConstraint = aws.String(location)
+	}
+
+	return output, nil
+}
+
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 // Fake Service Factory
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
@@ -79,30 +111,14 @@ func (fs3 *fakeS3Service) ListBuckets(input *s3.ListBucketsInput) (*s3.ListBucke
 // This structure simulates the AWS Service Factory by storing some pregenerated
 // responses (that would come from AWS).
 type fakeS3ServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
 	LBResponse *s3.ListBucketsOutput
 }
 
-// Don't need to implement
-func (fsf fakeS3ServiceFactory) Init() {}
-
-// Don't implement
+// Return our current region
 func (fsf fakeS3ServiceFactory) GetCurrentRegion() string {
-	return ""
-}
-
-// Don't need to implement
-func (fsf fakeS3ServiceFactory) GetAccountIDService() *AccountIDService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeS3ServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeS3ServiceFactory) GetRDSInstanceService(regionName string) *RDSInstanceService {
-	return nil
+	return fsf.RegionName
 }
 
 // Simply return our fake S3 Service
@@ -114,57 +130,73 @@ func (fsf fakeS3ServiceFactory) GetS3Service() *S3Service {
 	}
 }
 
-// Don't need to implement
-func (fsf fakeS3ServiceFactory) GetLambdaService(string) *LambdaService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeS3ServiceFactory) GetContainerService(string) *ContainerService {
-	return nil
-}
-
-// Don't need to implement
-func (fsf fakeS3ServiceFactory) GetLightsailService(string) *LightsailService {
-	return nil
-}
-
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 // Unit Test for S3Buckets
 // =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
 
 func TestS3Buckets(t *testing.T) {
-	// Describe all of our test cases: 1 failure and 1 success
+	// Describe all of our test cases: 1 failure and 6 successes
 	cases := []struct {
+		RegionName    string
+		AllRegions    bool
+		Buckets       *s3.ListBucketsOutput
 		ExpectedCount int
 		ExpectError   bool
+		ExpectSkipped string
 	}{
 		{
+			AllRegions:    true,
+			Buckets:       fakeS3BucketsSlice,
 			ExpectedCount: 8,
 		}, {
+			RegionName:    "us-east-1",
+			Buckets:       fakeS3BucketsSlice,
+			ExpectedCount: 3,
+		}, {
+			RegionName:    "us-east-2",
+			Buckets:       fakeS3BucketsSlice,
+			ExpectedCount: 2,
+		}, {
+			RegionName:    "eu-west-1",
+			Buckets:       fakeS3BucketsSlice,
+			ExpectedCount: 2,
+		}, {
+			RegionName:    "ap-south-1",
+			Buckets:       fakeS3BucketsSlice,
+			ExpectedCount: 0,
+		}, {
+			AllRegions:  true,
 			ExpectError: true,
+		}, {
+			RegionName: "us-east-1",
+			Buckets: &s3.ListBucketsOutput{
+				Buckets: []*s3.Bucket{
+					{
+						Name: aws.String("bucket1"),
+					},
+					{
+						Name: aws.String("unknown-bucket"),
+					},
+				},
+			},
+			ExpectedCount: 1,
+			ExpectSkipped: "unknown-bucket",
 		},
 	}
 
 	// Loop through each test case
 	for _, c := range cases {
-		// Construct a ListBucketsOutput object based on whether
-		// we expect an error or not
-		lbResponse := fakeS3BucketsSlice
-		if c.ExpectError {
-			lbResponse = nil
-		}
-
 		// Create our fake service factory
 		sf := fakeS3ServiceFactory{
-			LBResponse: lbResponse,
+			RegionName: c.RegionName,
+			LBResponse: c.Buckets,
 		}
 
 		// Create a mock activity monitor
 		mon := &mock.ActivityMonitorImpl{}
 
 		// Invoke our S3 Buckets function
-		actualCount := S3Buckets(sf, mon, false)
+		actualCount, _ := S3Buckets(sf, mon, c.AllRegions, false, 3)
 
 		// Did we expect an error?
 		if c.ExpectError {
@@ -176,8 +208,57 @@ func TestS3Buckets(t *testing.T) {
 			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
 		} else if actualCount != c.ExpectedCount {
 			t.Errorf("Error: S3Buckets returned %d; expected %d", actualCount, c.ExpectedCount)
+		} else if c.ExpectSkipped != "" && !strings.Contains(mon.Messages[len(mon.Messages)-1], c.ExpectSkipped) {
+			t.Errorf("Error: S3Buckets did not report skipping %s: %s", c.ExpectSkipped, mon.Messages[len(mon.Messages)-1])
 		} else if mon.ProgramExited {
 			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
 		}
 	}
 }
+
+func TestS3BucketsLookupRegions(t *testing.T) {
+	// Create our fake service factory
+	sf := fakeS3ServiceFactory{
+		RegionName: "us-east-1",
+		LBResponse: &s3.ListBucketsOutput{
+			Buckets: append(fakeS3BucketsSlice.Buckets, &s3.Bucket{
+				Name: aws.String("unknown-bucket"),
+			}),
+		},
+	}
+
+	// Expected number of buckets in each region
+	expectedRegionCounts := map[string]int{
+		"us-east-1":  3,
+		"us-east-2":  2,
+		"eu-west-1":  2,
+		"af-south-1": 1,
+	}
+
+	// Get the bucket locations for all regions
+	mon := &mock.ActivityMonitorImpl{}
+	actualCount, bucketRegions := S3Buckets(sf, mon, true, true, 3)
+
+	// Check our results
+	if mon.ErrorOccured {
+		t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+	} else if actualCount != 9 {
+		t.Errorf("Error: S3Buckets returned %d; expected 9", actualCount)
+	} else if len(bucketRegions.Unknown) != 1 || aws.StringValue(bucketRegions.Unknown[0]) != "unknown-bucket" {
+		t.Errorf("Error: S3Buckets returned unknown buckets %v; expected [unknown-bucket]", aws.StringValueSlice(bucketRegions.Unknown))
+	} else if len(bucketRegions.Regions) != len(expectedRegionCounts) {
+		t.Errorf("Error: S3Buckets returned %d regions; expected %d", len(bucketRegions.Regions), len(expectedRegionCounts))
+	} else {
+		for regionName, expectedCount := range expectedRegionCounts {
+			if actualCount := len(bucketRegions.Regions[regionName]); actualCount != expectedCount {
+				t.Errorf("Error: S3Buckets returned %d buckets in %s; expected %d", actualCount, regionName, expectedCount)
+			}
+		}
+	}
+
+	// Without lookupRegions, the locations of the buckets of all regions are not looked up
+	mon = &mock.ActivityMonitorImpl{}
+	if _, bucketRegions = S3Buckets(sf, mon, true, false, 3); bucketRegions.Regions != nil {
+		t.Errorf("Error: S3Buckets looked up bucket regions that were not needed: %v", bucketRegions.Regions)
+	}
+}
@@ -0,0 +1,189 @@
+/******************************************************************************
+Cloud Resource Counter
+File: serverless.go
+
+Summary: Provides a count of serverless and integration resources: Step
+         Functions state machines, API Gateway APIs, SQS queues, SNS topics and
+         EventBridge rules and buses.
+******************************************************************************/
+
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	color "github.com/logrusorgru/aurora"
+)
+
+// The name of the event bus that exists in every region
+const defaultEventBusName = "default"
+
+// ServerlessCounts holds the number of serverless and integration resources of
+// each kind. (Lambda functions are counted by LambdaFunctions.)
+type ServerlessCounts struct {
+	StateMachines    int
+	RestAPIs         int
+	HTTPAPIs         int
+	WebSocketAPIs    int
+	Queues           int
+	Topics           int
+	EventBridgeRules int
+	EventBuses       int
+}
+
+// Add the supplied counts into our struct.
+func (sc *ServerlessCounts) Add(other ServerlessCounts) {
+	sc.StateMachines += other.StateMachines
+	sc.RestAPIs += other.RestAPIs
+	sc.HTTPAPIs += other.HTTPAPIs
+	sc.WebSocketAPIs += other.WebSocketAPIs
+	sc.Queues += other.Queues
+	sc.Topics += other.Topics
+	sc.EventBridgeRules += other.EventBridgeRules
+	sc.EventBuses += other.EventBuses
+}
+
+// Total returns the number of all serverless and integration resources.
+func (sc ServerlessCounts) Total() int {
+	return sc.StateMachines + sc.RestAPIs + sc.HTTPAPIs + sc.WebSocketAPIs +
+		sc.Queues + sc.Topics + sc.EventBridgeRules + sc.EventBuses
+}
+
+// ServerlessServices retrieves the counts of Step Functions state machines, API
+// Gateway (REST, HTTP and WebSocket) APIs, SQS queues, SNS topics and EventBridge
+// rules and (custom) event buses either for all regions (allRegions is true) or the
+// region associated with the session. This method gives status back to the user via
+// the supplied ActivityMonitor instance.
+func ServerlessServices(sf ServiceFactory, am ActivityMonitor, allRegions bool) ServerlessCounts {
+	// Indicate activity
+	am.StartAction("Retrieving Serverless and Integration counts")
+
+	// Should we get the counts for all regions?
+	var serverlessCounts ServerlessCounts
+	if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the serverless counts for a specific region
+			serverlessCounts.Add(serverlessServicesForSingleRegion(sf, regionName, am))
+		}
+	} else {
+		// Get the serverless counts for the region selected by this session
+		serverlessCounts = serverlessServicesForSingleRegion(sf, "", am)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d)", color.Bold(serverlessCounts.Total()))
+
+	return serverlessCounts
+}
+
+// Get the serverless counts for a single region. We stop at the first error.
+func serverlessServicesForSingleRegion(sf ServiceFactory, regionName string, am ActivityMonitor) ServerlessCounts {
+	// Indicate activity
+	am.Message(".")
+
+	var serverlessCounts ServerlessCounts
+
+	// Count the Step Functions state machines
+	err := sf.GetStepFunctionsService(regionName).ListStateMachines(&sfn.ListStateMachinesInput{}, func(page *sfn.ListStateMachinesOutput, lastPage bool) bool {
+		serverlessCounts.StateMachines += len(page.StateMachines)
+
+		return true
+	})
+	if am.CheckError(err) {
+		return serverlessCounts
+	}
+
+	// Count the REST APIs
+	err = sf.GetAPIGatewayService(regionName).GetRestApis(&apigateway.GetRestApisInput{}, func(page *apigateway.GetRestApisOutput, lastPage bool) bool {
+		serverlessCounts.RestAPIs += len(page.Items)
+
+		return true
+	})
+	if am.CheckError(err) {
+		return serverlessCounts
+	}
+
+	// Count the HTTP and WebSocket APIs
+	err = sf.GetAPIGatewayV2Service(regionName).GetApis(&apigatewayv2.GetApisInput{}, func(page *apigatewayv2.GetApisOutput, lastPage bool) bool {
+		for _, api := range page.Items {
+			switch aws.StringValue(api.ProtocolType) {
+			case apigatewayv2.ProtocolTypeHttp:
+				serverlessCounts.HTTPAPIs++
+			case apigatewayv2.ProtocolTypeWebsocket:
+				serverlessCounts.WebSocketAPIs++
+			}
+		}
+
+		return true
+	})
+	if am.CheckError(err) {
+		return serverlessCounts
+	}
+
+	// Count the SQS queues. (Unless MaxResults is set, SQS returns no more than 1,000
+	// queues and does not paginate.)
+	sqsInput := &sqs.ListQueuesInput{
+		MaxResults: aws.Int64(1000),
+	}
+	err = sf.GetSQSService(regionName).ListQueues(sqsInput, func(page *sqs.ListQueuesOutput, lastPage bool) bool {
+		serverlessCounts.Queues += len(page.QueueUrls)
+
+		return true
+	})
+	if am.CheckError(err) {
+		return serverlessCounts
+	}
+
+	// Count the SNS topics
+	err = sf.GetSNSService(regionName).ListTopics(&sns.ListTopicsInput{}, func(page *sns.ListTopicsOutput, lastPage bool) bool {
+		serverlessCounts.Topics += len(page.Topics)
+
+		return true
+	})
+	if am.CheckError(err) {
+		return serverlessCounts
+	}
+
+	// Get the names of the event buses. (Each region has a "default" bus, which we
+	// do not count as a bus; we do count its rules.)
+	ebs := sf.GetEventBridgeService(regionName)
+	var busNames []*string
+	err = ebs.ListEventBuses(&eventbridge.ListEventBusesInput{}, func(page *eventbridge.ListEventBusesOutput, lastPage bool) bool {
+		for _, bus := range page.EventBuses {
+			busNames = append(busNames, bus.Name)
+			if aws.StringValue(bus.Name) != defaultEventBusName {
+				serverlessCounts.EventBuses++
+			}
+		}
+
+		return true
+	})
+	if am.CheckError(err) {
+		return serverlessCounts
+	}
+
+	// Count the rules of each event bus
+	for _, busName := range busNames {
+		err = ebs.ListRules(&eventbridge.ListRulesInput{
+			EventBusName: busName,
+		}, func(page *eventbridge.ListRulesOutput, lastPage bool) bool {
+			serverlessCounts.EventBridgeRules += len(page.Rules)
+
+			return true
+		})
+		if am.CheckError(err) {
+			break
+		}
+	}
+
+	return serverlessCounts
+}
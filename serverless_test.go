@@ -0,0 +1,604 @@
+/******************************************************************************
+Cloud Resource Counter
+File: serverless_test.go
+
+Summary: The Unit Test for serverless.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/apigateway/apigatewayiface"
+	"github.com/aws/aws-sdk-go/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go/service/apigatewayv2/apigatewayv2iface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/eventbridge/eventbridgeiface"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Serverless Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// ServerlessRegionInfo describes the fake serverless resources in a region. Each
+// slice holds the pages returned by the corresponding API. The rule pages are keyed
+// by event bus name.
+type ServerlessRegionInfo struct {
+	StateMachinePages []*sfn.ListStateMachinesOutput
+	RestAPIPages      []*apigateway.GetRestApisOutput
+	APIPages          []*apigatewayv2.GetApisOutput
+	QueuePages        []*sqs.ListQueuesOutput
+	TopicPages        []*sns.ListTopicsOutput
+	EventBusPages     []*eventbridge.ListEventBusesOutput
+	RulePages         map[string][]*eventbridge.ListRulesOutput
+}
+
+// Helper function to construct an HTTP or WebSocket API
+func apiWithProtocol(protocolType string) *apigatewayv2.Api {
+	return &apigatewayv2.Api{
+		ProtocolType: aws.String(protocolType),
+	}
+}
+
+// Helper function to construct a page of event rules with the supplied names
+func rulesNamed(names ...string) *eventbridge.ListRulesOutput {
+	output := &eventbridge.ListRulesOutput{}
+	for _, name := range names {
+		output.Rules = append(output.Rules, &eventbridge.Rule{
+			Name: aws.String(name),
+		})
+	}
+
+	return output
+}
+
+// Helper function that returns the page indicated by a (fake) NextToken along with
+// the NextToken of the following page (nil on the last page)
+func fakePageIndex(token *string, pageCount int) (int, *string, error) {
+	index := 0
+	if token != nil {
+		var err error
+		if index, err = strconv.Atoi(*token); err != nil || index >= pageCount {
+			return 0, nil, fmt.Errorf("Invalid NextToken: %s", *token)
+		}
+	}
+
+	// Is there another page?
+	if index == pageCount-1 {
+		return index, nil, nil
+	}
+
+	return index, aws.String(strconv.Itoa(index + 1)), nil
+}
+
+// This is our map of regions and the serverless resources in each
+var serverlessPerRegion = map[string]*ServerlessRegionInfo{
+	// US-EAST-1 illustrates a case where most APIs return two pages of results:
+	// 3 state machines, 2 REST APIs, 2 HTTP APIs, 1 WebSocket API, 3 queues,
+	// 2 topics, 2 custom event buses and 5 rules (across all 3 buses).
+	"us-east-1": &ServerlessRegionInfo{
+		StateMachinePages: []*sfn.ListStateMachinesOutput{
+			&sfn.ListStateMachinesOutput{
+				StateMachines: []*sfn.StateMachineListItem{
+					&sfn.StateMachineListItem{Name: aws.String("checkout")},
+					&sfn.StateMachineListItem{Name: aws.String("refund")},
+				},
+			},
+			&sfn.ListStateMachinesOutput{
+				StateMachines: []*sfn.StateMachineListItem{
+					&sfn.StateMachineListItem{Name: aws.String("onboarding")},
+				},
+			},
+		},
+		RestAPIPages: []*apigateway.GetRestApisOutput{
+			&apigateway.GetRestApisOutput{
+				Items: []*apigateway.RestApi{
+					&apigateway.RestApi{Name: aws.String("orders")},
+				},
+			},
+			&apigateway.GetRestApisOutput{
+				Items: []*apigateway.RestApi{
+					&apigateway.RestApi{Name: aws.String("payments")},
+				},
+			},
+		},
+		APIPages: []*apigatewayv2.GetApisOutput{
+			&apigatewayv2.GetApisOutput{
+				Items: []*apigatewayv2.Api{
+					apiWithProtocol("HTTP"),
+					apiWithProtocol("WEBSOCKET"),
+				},
+			},
+			&apigatewayv2.GetApisOutput{
+				Items: []*apigatewayv2.Api{
+					apiWithProtocol("HTTP"),
+				},
+			},
+		},
+		QueuePages: []*sqs.ListQueuesOutput{
+			&sqs.ListQueuesOutput{
+				QueueUrls: aws.StringSlice([]string{"https://sqs/1/jobs", "https://sqs/1/jobs-dlq"}),
+			},
+			&sqs.ListQueuesOutput{
+				QueueUrls: aws.StringSlice([]string{"https://sqs/1/emails"}),
+			},
+		},
+		TopicPages: []*sns.ListTopicsOutput{
+			&sns.ListTopicsOutput{
+				Topics: []*sns.Topic{
+					&sns.Topic{TopicArn: aws.String("arn:aws:sns:us-east-1:1:alerts")},
+					&sns.Topic{TopicArn: aws.String("arn:aws:sns:us-east-1:1:orders")},
+				},
+			},
+		},
+		EventBusPages: []*eventbridge.ListEventBusesOutput{
+			&eventbridge.ListEventBusesOutput{
+				EventBuses: []*eventbridge.EventBus{
+					&eventbridge.EventBus{Name: aws.String("default")},
+					&eventbridge.EventBus{Name: aws.String("orders")},
+				},
+			},
+			&eventbridge.ListEventBusesOutput{
+				EventBuses: []*eventbridge.EventBus{
+					&eventbridge.EventBus{Name: aws.String("partner")},
+				},
+			},
+		},
+		RulePages: map[string][]*eventbridge.ListRulesOutput{
+			"default": []*eventbridge.ListRulesOutput{
+				rulesNamed("nightly", "hourly"),
+				rulesNamed("weekly"),
+			},
+			"orders": []*eventbridge.ListRulesOutput{
+				rulesNamed("order-placed", "order-shipped"),
+			},
+			"partner": []*eventbridge.ListRulesOutput{
+				rulesNamed(),
+			},
+		},
+	},
+	// US-EAST-2 has 1 queue and 1 rule on the default bus (and nothing else)
+	"us-east-2": &ServerlessRegionInfo{
+		StateMachinePages: []*sfn.ListStateMachinesOutput{
+			&sfn.ListStateMachinesOutput{},
+		},
+		RestAPIPages: []*apigateway.GetRestApisOutput{
+			&apigateway.GetRestApisOutput{},
+		},
+		APIPages: []*apigatewayv2.GetApisOutput{
+			&apigatewayv2.GetApisOutput{},
+		},
+		QueuePages: []*sqs.ListQueuesOutput{
+			&sqs.ListQueuesOutput{
+				QueueUrls: aws.StringSlice([]string{"https://sqs/2/jobs"}),
+			},
+		},
+		TopicPages: []*sns.ListTopicsOutput{
+			&sns.ListTopicsOutput{},
+		},
+		EventBusPages: []*eventbridge.ListEventBusesOutput{
+			&eventbridge.ListEventBusesOutput{
+				EventBuses: []*eventbridge.EventBus{
+					&eventbridge.EventBus{Name: aws.String("default")},
+				},
+			},
+		},
+		RulePages: map[string][]*eventbridge.ListRulesOutput{
+			"default": []*eventbridge.ListRulesOutput{
+				rulesNamed("nightly"),
+			},
+		},
+	},
+	// AF-SOUTH-1 has nothing (except the default bus)
+	"af-south-1": &ServerlessRegionInfo{
+		StateMachinePages: []*sfn.ListStateMachinesOutput{
+			&sfn.ListStateMachinesOutput{},
+		},
+		RestAPIPages: []*apigateway.GetRestApisOutput{
+			&apigateway.GetRestApisOutput{},
+		},
+		APIPages: []*apigatewayv2.GetApisOutput{
+			&apigatewayv2.GetApisOutput{},
+		},
+		QueuePages: []*sqs.ListQueuesOutput{
+			&sqs.ListQueuesOutput{},
+		},
+		TopicPages: []*sns.ListTopicsOutput{
+			&sns.ListTopicsOutput{},
+		},
+		EventBusPages: []*eventbridge.ListEventBusesOutput{
+			&eventbridge.ListEventBusesOutput{
+				EventBuses: []*eventbridge.EventBus{
+					&eventbridge.EventBus{Name: aws.String("default")},
+				},
+			},
+		},
+		RulePages: map[string][]*eventbridge.ListRulesOutput{
+			"default": []*eventbridge.ListRulesOutput{
+				rulesNamed(),
+			},
+		},
+	},
+	// AF-SOUTH-2 has a custom bus whose rules cannot be listed (an error)
+	"af-south-2": &ServerlessRegionInfo{
+		StateMachinePages: []*sfn.ListStateMachinesOutput{
+			&sfn.ListStateMachinesOutput{},
+		},
+		RestAPIPages: []*apigateway.GetRestApisOutput{
+			&apigateway.GetRestApisOutput{},
+		},
+		APIPages: []*apigatewayv2.GetApisOutput{
+			&apigatewayv2.GetApisOutput{},
+		},
+		QueuePages: []*sqs.ListQueuesOutput{
+			&sqs.ListQueuesOutput{},
+		},
+		TopicPages: []*sns.ListTopicsOutput{
+			&sns.ListTopicsOutput{},
+		},
+		EventBusPages: []*eventbridge.ListEventBusesOutput{
+			&eventbridge.ListEventBusesOutput{
+				EventBuses: []*eventbridge.EventBus{
+					&eventbridge.EventBus{Name: aws.String("forbidden")},
+				},
+			},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Serverless Services
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// To use these structs, the caller must supply a ServerlessRegionInfo. If it (or
+// the pages for a particular API) is missing, it will trigger the mock functions to
+// simulate an error.
+type fakeStepFunctionsService struct {
+	sfniface.SFNAPI
+	RegionInfo *ServerlessRegionInfo
+}
+
+// Simulate the ListStateMachinesPages function
+func (fake *fakeStepFunctionsService) ListStateMachinesPages(input *sfn.ListStateMachinesInput, fn func(*sfn.ListStateMachinesOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.StateMachinePages == nil {
+		return errors.New("ListStateMachinesPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.StateMachinePages {
+		if !fn(output, index == len(fake.RegionInfo.StateMachinePages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+type fakeAPIGatewayService struct {
+	apigatewayiface.APIGatewayAPI
+	RegionInfo *ServerlessRegionInfo
+}
+
+// Simulate the GetRestApisPages function
+func (fake *fakeAPIGatewayService) GetRestApisPages(input *apigateway.GetRestApisInput, fn func(*apigateway.GetRestApisOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.RestAPIPages == nil {
+		return errors.New("GetRestApisPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.RestAPIPages {
+		if !fn(output, index == len(fake.RegionInfo.RestAPIPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+type fakeAPIGatewayV2Service struct {
+	apigatewayv2iface.ApiGatewayV2API
+	RegionInfo *ServerlessRegionInfo
+}
+
+// Simulate the GetApis function (a page at a time, using the NextToken)
+func (fake *fakeAPIGatewayV2Service) GetApis(input *apigatewayv2.GetApisInput) (*apigatewayv2.GetApisOutput, error) {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.APIPages == nil {
+		return nil, errors.New("GetApis encountered an unexpected error: 1234")
+	}
+
+	// Find the requested page
+	index, nextToken, err := fakePageIndex(input.NextToken, len(fake.RegionInfo.APIPages))
+	if err != nil {
+		return nil, err
+	}
+
+	return &apigatewayv2.GetApisOutput{
+		Items:     fake.RegionInfo.APIPages[index].Items,
+		NextToken: nextToken,
+	}, nil
+}
+
+type fakeSQSService struct {
+	sqsiface.SQSAPI
+	RegionInfo *ServerlessRegionInfo
+}
+
+// Simulate the ListQueuesPages function
+func (fake *fakeSQSService) ListQueuesPages(input *sqs.ListQueuesInput, fn func(*sqs.ListQueuesOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.QueuePages == nil {
+		return errors.New("ListQueuesPages encountered an unexpected error: 1234")
+	}
+
+	// Like SQS, we only paginate if MaxResults is set. Otherwise, just the first
+	// page (if any) is returned.
+	pages := fake.RegionInfo.QueuePages
+	if input.MaxResults == nil && len(pages) > 1 {
+		pages = pages[:1]
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range pages {
+		if !fn(output, index == len(pages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+type fakeSNSService struct {
+	snsiface.SNSAPI
+	RegionInfo *ServerlessRegionInfo
+}
+
+// Simulate the ListTopicsPages function
+func (fake *fakeSNSService) ListTopicsPages(input *sns.ListTopicsInput, fn func(*sns.ListTopicsOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.TopicPages == nil {
+		return errors.New("ListTopicsPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.TopicPages {
+		if !fn(output, index == len(fake.RegionInfo.TopicPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+type fakeEventBridgeService struct {
+	eventbridgeiface.EventBridgeAPI
+	RegionInfo *ServerlessRegionInfo
+}
+
+// Simulate the ListEventBuses function (a page at a time, using the NextToken)
+func (fake *fakeEventBridgeService) ListEventBuses(input *eventbridge.ListEventBusesInput) (*eventbridge.ListEventBusesOutput, error) {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.EventBusPages == nil {
+		return nil, errors.New("ListEventBuses encountered an unexpected error: 1234")
+	}
+
+	// Find the requested page
+	index, nextToken, err := fakePageIndex(input.NextToken, len(fake.RegionInfo.EventBusPages))
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventbridge.ListEventBusesOutput{
+		EventBuses: fake.RegionInfo.EventBusPages[index].EventBuses,
+		NextToken:  nextToken,
+	}, nil
+}
+
+// Simulate the ListRules function (a page at a time, using the NextToken)
+func (fake *fakeEventBridgeService) ListRules(input *eventbridge.ListRulesInput) (*eventbridge.ListRulesOutput, error) {
+	// Find the rules of the event bus
+	pages, ok := fake.RegionInfo.RulePages[aws.StringValue(input.EventBusName)]
+	if !ok {
+		return nil, fmt.Errorf("ListRules could not find event bus: %s", aws.StringValue(input.EventBusName))
+	}
+
+	// Find the requested page
+	index, nextToken, err := fakePageIndex(input.NextToken, len(pages))
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventbridge.ListRulesOutput{
+		Rules:     pages[index].Rules,
+		NextToken: nextToken,
+	}, nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeServerlessServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Get the serverless resources of the supplied region (or the region associated with our factory)
+func (fsf fakeServerlessServiceFactory) regionInfo(regionName string) *ServerlessRegionInfo {
+	if regionName == "" {
+		return serverlessPerRegion[fsf.RegionName]
+	}
+
+	return serverlessPerRegion[regionName]
+}
+
+// Return our current region
+func (fsf fakeServerlessServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// This implementation of GetEC2InstanceService is limited to supporting DescribeRegions API
+// only.
+func (fsf fakeServerlessServiceFactory) GetEC2InstanceService(string) *EC2InstanceService {
+	return &EC2InstanceService{
+		Client: &fakeEC2Service{
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// Return a specialized StepFunctionsService that returns pre-canned responses
+func (fsf fakeServerlessServiceFactory) GetStepFunctionsService(regionName string) *StepFunctionsService {
+	return &StepFunctionsService{
+		Client: &fakeStepFunctionsService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized APIGatewayService that returns pre-canned responses
+func (fsf fakeServerlessServiceFactory) GetAPIGatewayService(regionName string) *APIGatewayService {
+	return &APIGatewayService{
+		Client: &fakeAPIGatewayService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized APIGatewayV2Service that returns pre-canned responses
+func (fsf fakeServerlessServiceFactory) GetAPIGatewayV2Service(regionName string) *APIGatewayV2Service {
+	return &APIGatewayV2Service{
+		Client: &fakeAPIGatewayV2Service{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized SQSService that returns pre-canned responses
+func (fsf fakeServerlessServiceFactory) GetSQSService(regionName string) *SQSService {
+	return &SQSService{
+		Client: &fakeSQSService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized SNSService that returns pre-canned responses
+func (fsf fakeServerlessServiceFactory) GetSNSService(regionName string) *SNSService {
+	return &SNSService{
+		Client: &fakeSNSService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// Return a specialized EventBridgeService that returns pre-canned responses
+func (fsf fakeServerlessServiceFactory) GetEventBridgeService(regionName string) *EventBridgeService {
+	return &EventBridgeService{
+		Client: &fakeEventBridgeService{
+			RegionInfo: fsf.regionInfo(regionName),
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for ServerlessServices
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestServerlessServices(t *testing.T) {
+	// Describe all of our test cases: 2 failures and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts ServerlessCounts
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: ServerlessCounts{
+				StateMachines:    3,
+				RestAPIs:         2,
+				HTTPAPIs:         2,
+				WebSocketAPIs:    1,
+				Queues:           3,
+				Topics:           2,
+				EventBridgeRules: 5,
+				EventBuses:       2,
+			},
+		}, {
+			RegionName: "us-east-2",
+			ExpectedCounts: ServerlessCounts{
+				Queues:           1,
+				EventBridgeRules: 1,
+			},
+		}, {
+			RegionName: "af-south-1",
+		}, {
+			RegionName:  "af-south-2",
+			ExpectError: true,
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions: true,
+			ExpectedCounts: ServerlessCounts{
+				StateMachines:    3,
+				RestAPIs:         2,
+				HTTPAPIs:         2,
+				WebSocketAPIs:    1,
+				Queues:           4,
+				Topics:           2,
+				EventBridgeRules: 6,
+				EventBuses:       2,
+			},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeServerlessServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our Serverless Services function
+		actualCounts := ServerlessServices(sf, mon, c.AllRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: ServerlessServices returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}
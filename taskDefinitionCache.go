@@ -0,0 +1,153 @@
+/******************************************************************************
+Cloud Resource Counter
+File: taskDefinitionCache.go
+
+Summary: An on-disk cache of the container images used by each ECS task
+         definition revision.
+******************************************************************************/
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	color "github.com/logrusorgru/aurora"
+)
+
+// The version of the cache file format. A cache file with a different version
+// is ignored (and replaced when saved).
+const taskDefinitionCacheVersion = 1
+
+// TaskDefinitionCache is a cache of the container images used by each task
+// definition, keyed by task definition ARN. Task definition revisions are
+// immutable, so a cached entry never needs to be refreshed. It is safe to use
+// from multiple goroutines. A nil cache caches nothing.
+type TaskDefinitionCache struct {
+	FileName string
+
+	mutex  sync.Mutex
+	images map[string][]string
+	dirty  bool
+}
+
+// The layout of the cache file
+type taskDefinitionCacheFile struct {
+	Version         int                 `json:"version"`
+	TaskDefinitions map[string][]string `json:"taskDefinitions"`
+}
+
+// LoadTaskDefinitionCache reads the cache stored in the supplied file. If the
+// file does not (yet) exist, an empty cache is returned.
+func LoadTaskDefinitionCache(fileName string, am ActivityMonitor) *TaskDefinitionCache {
+	cache := &TaskDefinitionCache{
+		FileName: fileName,
+		images:   make(map[string][]string),
+	}
+
+	// Read the file
+	contents, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return cache
+	}
+
+	// Check for error
+	if am.CheckError(err) {
+		return cache
+	}
+
+	// Parse its contents
+	var cacheFile taskDefinitionCacheFile
+	if am.CheckError(json.Unmarshal(contents, &cacheFile)) {
+		return cache
+	}
+
+	// Only use entries written in our format
+	if cacheFile.Version == taskDefinitionCacheVersion && cacheFile.TaskDefinitions != nil {
+		cache.images = cacheFile.TaskDefinitions
+	}
+
+	return cache
+}
+
+// Lookup returns the container images of the supplied task definition ARN, if
+// they have been cached.
+func (tdc *TaskDefinitionCache) Lookup(taskDefnArn string) ([]string, bool) {
+	if tdc == nil {
+		return nil, false
+	}
+
+	tdc.mutex.Lock()
+	defer tdc.mutex.Unlock()
+
+	images, ok := tdc.images[taskDefnArn]
+
+	return images, ok
+}
+
+// Store records the container images of the supplied task definition ARN.
+func (tdc *TaskDefinitionCache) Store(taskDefnArn string, images []string) {
+	if tdc == nil {
+		return
+	}
+
+	tdc.mutex.Lock()
+	defer tdc.mutex.Unlock()
+
+	// Always store a non-nil slice so that the entry survives a round trip
+	if images == nil {
+		images = []string{}
+	}
+	tdc.images[taskDefnArn] = images
+	tdc.dirty = true
+}
+
+// Len returns the number of cached task definitions.
+func (tdc *TaskDefinitionCache) Len() int {
+	if tdc == nil {
+		return 0
+	}
+
+	tdc.mutex.Lock()
+	defer tdc.mutex.Unlock()
+
+	return len(tdc.images)
+}
+
+// Save writes the cache back to its file (if anything was added to it).
+func (tdc *TaskDefinitionCache) Save(am ActivityMonitor) {
+	if tdc == nil {
+		return
+	}
+
+	tdc.mutex.Lock()
+	defer tdc.mutex.Unlock()
+
+	// If nothing has changed, then get out now...
+	if !tdc.dirty {
+		return
+	}
+
+	// Indicate activity
+	am.StartAction("Writing task definition cache")
+
+	// Convert the cache to JSON
+	contents, err := json.Marshal(&taskDefinitionCacheFile{
+		Version:         taskDefinitionCacheVersion,
+		TaskDefinitions: tdc.images,
+	})
+	if am.CheckError(err) {
+		return
+	}
+
+	// Write the file
+	if am.CheckError(ioutil.WriteFile(tdc.FileName, contents, 0666)) {
+		return
+	}
+	tdc.dirty = false
+
+	// Indicate success
+	am.EndAction("OK (%d)", color.Bold(len(tdc.images)))
+}
@@ -0,0 +1,91 @@
+/******************************************************************************
+Cloud Resource Counter
+File: taskDefinitionCache_test.go
+
+Summary: The Unit Test for taskDefinitionCache.
+******************************************************************************/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+func TestTaskDefinitionCacheRoundTrip(t *testing.T) {
+	// Create a temporary folder to hold our cache file
+	tempDir, err := ioutil.TempDir("", "task-definition-cache")
+	if err != nil {
+		t.Fatalf("Unexpected error while creating a temporary folder: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	fileName := filepath.Join(tempDir, "cache.json")
+
+	// Create a mock activity monitor
+	mon := &mock.ActivityMonitorImpl{}
+
+	// Loading a non-existent file gives us an empty cache
+	cache := LoadTaskDefinitionCache(fileName, mon)
+	if mon.ErrorOccured {
+		t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+	} else if cache.Len() != 0 {
+		t.Errorf("Error: new TaskDefinitionCache holds %d entries; expected 0", cache.Len())
+	}
+
+	// Store some entries and save them
+	cache.Store("arn:1", []string{"image1", "image2"})
+	cache.Store("arn:2", nil)
+	cache.Save(mon)
+	if mon.ErrorOccured {
+		t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+	} else if !FileExists(fileName) {
+		t.Errorf("Error: TaskDefinitionCache was not saved to %s", fileName)
+	}
+
+	// Load them again
+	reloaded := LoadTaskDefinitionCache(fileName, mon)
+	if mon.ErrorOccured {
+		t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+	} else if images, ok := reloaded.Lookup("arn:1"); !ok || !reflect.DeepEqual(images, []string{"image1", "image2"}) {
+		t.Errorf("Error: reloaded TaskDefinitionCache returned %v (%v) for arn:1", images, ok)
+	} else if images, ok := reloaded.Lookup("arn:2"); !ok || len(images) != 0 {
+		t.Errorf("Error: reloaded TaskDefinitionCache returned %v (%v) for arn:2", images, ok)
+	} else if _, ok := reloaded.Lookup("arn:3"); ok {
+		t.Error("Error: reloaded TaskDefinitionCache returned an entry for arn:3")
+	}
+}
+
+func TestTaskDefinitionCacheInvalidFile(t *testing.T) {
+	// Create a temporary file with invalid contents
+	tempFile, err := ioutil.TempFile("", "task-definition-cache")
+	if err != nil {
+		t.Fatalf("Unexpected error while creating a temporary file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+	tempFile.WriteString("this is not JSON")
+	tempFile.Close()
+
+	// Create a mock activity monitor
+	mon := &mock.ActivityMonitorImpl{}
+
+	// Try to load it
+	LoadTaskDefinitionCache(tempFile.Name(), mon)
+	if !mon.ErrorOccured {
+		t.Error("Expected an error to occur, but it did not... :^(")
+	}
+}
+
+func TestTaskDefinitionCacheNil(t *testing.T) {
+	// A nil cache caches nothing (and does not panic)
+	var cache *TaskDefinitionCache
+	cache.Store("arn:1", []string{"image1"})
+	if _, ok := cache.Lookup("arn:1"); ok {
+		t.Error("Error: nil TaskDefinitionCache returned an entry")
+	}
+	cache.Save(&mock.ActivityMonitorImpl{})
+}
@@ -0,0 +1,313 @@
+/******************************************************************************
+Cloud Resource Counter
+File: tfstate.go
+
+Summary: Counts resources from Terraform state (version 4) files, without
+         calling any AWS APIs.
+******************************************************************************/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// TFStateVersion is the only version of the Terraform state format that we read.
+const TFStateVersion = 4
+
+// TFState is a Terraform state file. We only decode the parts that we count.
+type TFState struct {
+	Version   int               `json:"version"`
+	Resources []TFStateResource `json:"resources"`
+}
+
+// TFStateResource is a single resource block of a Terraform state file. A block
+// has an instance for each resource created by "count" or "for_each".
+type TFStateResource struct {
+	Module    string            `json:"module"`
+	Mode      string            `json:"mode"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Instances []TFStateInstance `json:"instances"`
+}
+
+// TFStateInstance is a single instance of a resource, holding its attributes.
+type TFStateInstance struct {
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// TFStateCounts holds the number of resources found in a Terraform state file.
+// Each count is stored in the column of the same name produced by the API backend.
+type TFStateCounts struct {
+	AccountID          string
+	Lifecycles         EC2LifecycleCounts
+	EBSVolumes         int
+	UniqueContainers   int
+	LambdaFunctions    int
+	RDSInstances       int
+	LightsailInstances int
+	S3Buckets          int
+}
+
+// Total returns the number of resources that were counted.
+func (counts TFStateCounts) Total() int {
+	return counts.Lifecycles.Total() + counts.EBSVolumes + counts.UniqueContainers +
+		counts.LambdaFunctions + counts.RDSInstances + counts.LightsailInstances + counts.S3Buckets
+}
+
+// LoadTFState reads the Terraform state file with the supplied name.
+func LoadTFState(fileName string) (*TFState, error) {
+	// Read the file
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it
+	var state TFState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s is not a valid Terraform state file: %v", fileName, err)
+	}
+
+	// Can we read this version?
+	if state.Version != TFStateVersion {
+		return nil, fmt.Errorf("%s has Terraform state version %d; only version %d is supported", fileName, state.Version, TFStateVersion)
+	}
+
+	return &state, nil
+}
+
+// FindTFStateFiles returns the Terraform state files at the supplied path. If it is
+// a directory, then every "*.tfstate" file beneath it (such as the state of each
+// workspace in "terraform.tfstate.d") is returned, in order of name. The ".terraform"
+// directory (which holds the settings of the Terraform backend) is skipped.
+func FindTFStateFiles(path string) ([]string, error) {
+	// Is the path a single file?
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	// Walk the directory, looking for state files
+	var fileNames []string
+	err = filepath.Walk(path, func(fileName string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".terraform" {
+			return filepath.SkipDir
+		}
+		if info.Mode().IsRegular() && strings.HasSuffix(info.Name(), ".tfstate") {
+			fileNames = append(fileNames, fileName)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Did we find any?
+	if len(fileNames) == 0 {
+		return nil, fmt.Errorf("no Terraform state files (*.tfstate) were found in %s", path)
+	}
+	sort.Strings(fileNames)
+
+	return fileNames, nil
+}
+
+// Count returns the number of resources in the state, either in all regions
+// (regionName is empty) or the supplied region. Resources whose region cannot be
+// determined are only counted for all regions. Container images are considered
+// the same according to the supplied ImageDedupeMode.
+//
+// Like the API backend, only running EC2 instances (of every lifecycle), attached
+// EBS volumes and available RDS instances are counted (when the state records
+// their status). A volume is attached if it is a block device of an instance or is
+// attached by an "aws_volume_attachment".
+func (state *TFState) Count(regionName string, dedupe ImageDedupeMode) TFStateCounts {
+	var counts TFStateCounts
+
+	// The volumes and container images that we have seen
+	volumeIDs := make(map[string]bool)
+	containerImageMap := make(map[string]bool)
+
+	// Find the volumes that are attached by volume attachments. (An attachment does
+	// not record its region, so we rely on the region of the volume itself.)
+	attachedVolumeIDs := make(map[string]bool)
+	for _, resource := range state.Resources {
+		if resource.Mode == "managed" && resource.Type == "aws_volume_attachment" {
+			for _, instance := range resource.Instances {
+				if volumeID := tfStateString(instance.Attributes, "volume_id"); volumeID != "" {
+					attachedVolumeIDs[volumeID] = true
+				}
+			}
+		}
+	}
+
+	// Count a volume once (even if it appears in more than one resource)
+	addVolume := func(attributes map[string]interface{}) {
+		if volumeID := tfStateString(attributes, "volume_id"); volumeID != "" {
+			volumeIDs[volumeID] = true
+		} else {
+			counts.EBSVolumes++
+		}
+	}
+
+	// Loop through the managed resources (not data sources)...
+	for _, resource := range state.Resources {
+		if resource.Mode != "managed" {
+			continue
+		}
+
+		// Loop through the instances of the resource...
+		for _, instance := range resource.Instances {
+			attributes := instance.Attributes
+
+			// Remember the account of the first ARN that we see
+			if counts.AccountID == "" {
+				counts.AccountID = tfStateAccountID(attributes)
+			}
+
+			// Is the resource in the region that we are counting?
+			if regionName != "" && tfStateRegion(attributes) != regionName {
+				continue
+			}
+
+			// What type of resource is it?
+			switch resource.Type {
+			case "aws_instance", "aws_spot_instance_request":
+				// Is it a spot request that was never fulfilled?
+				if resource.Type == "aws_spot_instance_request" && tfStateString(attributes, "spot_instance_id") == "" {
+					continue
+				}
+
+				// Count the volumes of the instance (whatever its state)
+				for _, device := range tfStateBlocks(attributes, "root_block_device") {
+					addVolume(device)
+				}
+				for _, device := range tfStateBlocks(attributes, "ebs_block_device") {
+					addVolume(device)
+				}
+
+				// Is the instance running?
+				if instanceState := tfStateString(attributes, "instance_state"); instanceState != "" && instanceState != "running" {
+					continue
+				}
+
+				// Count it under its lifecycle (a spot request launches a spot instance)
+				lifecycle := tfStateString(attributes, "instance_lifecycle")
+				if resource.Type == "aws_spot_instance_request" {
+					lifecycle = ec2.InstanceLifecycleTypeSpot
+				}
+				counts.Lifecycles.AddInstance(&ec2.Instance{
+					InstanceLifecycle: aws.String(lifecycle),
+				})
+			case "aws_ebs_volume":
+				// Is the volume attached by a volume attachment?
+				if volumeID := tfStateString(attributes, "id"); volumeID != "" && attachedVolumeIDs[volumeID] {
+					volumeIDs[volumeID] = true
+				}
+			case "aws_ecs_task_definition":
+				for _, image := range tfStateContainerImages(attributes) {
+					containerImageMap[dedupe.Key(image)] = true
+				}
+			case "aws_lambda_function":
+				counts.LambdaFunctions++
+			case "aws_db_instance":
+				if status := tfStateString(attributes, "status"); status == "" || status == "available" {
+					counts.RDSInstances++
+				}
+			case "aws_lightsail_instance":
+				counts.LightsailInstances++
+			case "aws_s3_bucket":
+				counts.S3Buckets++
+			}
+		}
+	}
+
+	// Add the unique volumes and images
+	counts.EBSVolumes += len(volumeIDs)
+	counts.UniqueContainers = len(containerImageMap)
+
+	return counts
+}
+
+// Get a string attribute (or the empty string if it is missing).
+func tfStateString(attributes map[string]interface{}, name string) string {
+	value, _ := attributes[name].(string)
+
+	return value
+}
+
+// Get the nested blocks of an attribute (such as the "root_block_device" of an
+// instance).
+func tfStateBlocks(attributes map[string]interface{}, name string) []map[string]interface{} {
+	var blocks []map[string]interface{}
+	list, _ := attributes[name].([]interface{})
+	for _, item := range list {
+		if block, ok := item.(map[string]interface{}); ok {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return blocks
+}
+
+// Get the region of a resource: from its "region" attribute (recorded by newer
+// versions of the AWS provider), its ARN or its availability zone.
+func tfStateRegion(attributes map[string]interface{}) string {
+	if regionName := tfStateString(attributes, "region"); regionName != "" {
+		return regionName
+	}
+	if parts := strings.Split(tfStateString(attributes, "arn"), ":"); len(parts) >= 6 && parts[3] != "" {
+		return parts[3]
+	}
+	if zone := tfStateString(attributes, "availability_zone"); len(zone) > 1 {
+		return zone[:len(zone)-1]
+	}
+
+	return ""
+}
+
+// Get the account of a resource from its ARN (or the empty string if it has no
+// ARN, or its ARN does not include an account).
+func tfStateAccountID(attributes map[string]interface{}) string {
+	if parts := strings.Split(tfStateString(attributes, "arn"), ":"); len(parts) >= 6 {
+		return parts[4]
+	}
+
+	return ""
+}
+
+// Get the images of the containers of a task definition. Its container definitions
+// are stored as a JSON string.
+func tfStateContainerImages(attributes map[string]interface{}) []string {
+	var containerDefinitions []struct {
+		Image string `json:"image"`
+	}
+	if err := json.Unmarshal([]byte(tfStateString(attributes, "container_definitions")), &containerDefinitions); err != nil {
+		return nil
+	}
+
+	var images []string
+	for _, containerDefinition := range containerDefinitions {
+		if containerDefinition.Image != "" {
+			images = append(images, containerDefinition.Image)
+		}
+	}
+
+	return images
+}
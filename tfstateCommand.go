@@ -0,0 +1,235 @@
+/******************************************************************************
+Cloud Resource Counter
+File: tfstateCommand.go
+
+Summary: The "count-tfstate" subcommand: counts the resources in Terraform state
+         files (rather than in an AWS account) and saves them to a CSV file.
+******************************************************************************/
+
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	color "github.com/logrusorgru/aurora"
+)
+
+// CountTFStateCommand is the name of the subcommand that counts Terraform state
+// files.
+const CountTFStateCommand = "count-tfstate"
+
+// TFStateSettings is a struct that holds the settings of the count-tfstate
+// subcommand.
+type TFStateSettings struct {
+	outputFileName  string
+	outputFile      *os.File
+	appendToOutput  bool
+	outputHeaders   []string
+	noOutputFile    bool
+	regionName      string
+	imageDedupeName string
+	imageDedupe     ImageDedupeMode
+	stateFileNames  []string
+}
+
+// Process the arguments of the count-tfstate subcommand (the arguments after the
+// name of the subcommand). The arguments are:
+//
+//   --output-file OF: Write the results in Comma Separated Values (CSV) format to file OF.
+//   --no-output:      Do not save the results to any file.
+//   --region RN:      Count only the resources in region RN.
+//   --image-dedupe M: How unique container images are determined.
+//   PATH...:          The Terraform state files (or directories of them) to count.
+//
+func (tfs *TFStateSettings) Process(args []string, am ActivityMonitor) func() {
+	emptyFn := func() {}
+
+	// Define a new FlagSet
+	flagSet := flag.NewFlagSet(CountTFStateCommand, flag.ExitOnError)
+
+	// Define and parse the command line arguments...
+	flagSet.StringVar(&tfs.outputFileName, "output-file", "", "CSV Output File. Specify a path to a `file` to save the generated CSV file. (default resources.csv)")
+	flagSet.BoolVar(&tfs.noOutputFile, "no-output", false, "Do not save the results of this run into any file. (default false--save results to a file)")
+	flagSet.StringVar(&tfs.regionName, "region", "", "Count only the resources in this AWS Region. If omitted, then the resources of all regions are counted.")
+	flagSet.StringVar(&tfs.imageDedupeName, "image-dedupe", DedupeByReference.String(), "How unique container images are determined: by canonical `mode` \"reference\", by unaltered \"raw\" image string, by \"repository\" (ignoring tags) or by the digest that the image reference is pinned to (\"pinned-digest\"; tags are not resolved to digests).")
+	flagSet.Parse(args)
+
+	// Check for a valid AWS Region
+	if tfs.regionName != "" && !IsValidRegionName(tfs.regionName) {
+		am.ActionError("Error: '%s' is not a valid AWS Region name.", tfs.regionName)
+		return emptyFn
+	}
+
+	// Check for a valid image dedupe mode
+	var ok bool
+	if tfs.imageDedupe, ok = ParseImageDedupeMode(tfs.imageDedupeName); !ok {
+		am.ActionError("Error: '%s' is not a valid image dedupe mode.", tfs.imageDedupeName)
+		return emptyFn
+	} else if tfs.imageDedupe == DedupeByDigest {
+		// Resolving tags to digests needs access to ECR
+		am.ActionError("Error: the '%s' image dedupe mode is not available when counting Terraform state files; use '%s' instead.", DedupeByDigest, DedupeByPinnedDigest)
+		return emptyFn
+	}
+
+	// Were we given any state files?
+	if flagSet.NArg() == 0 {
+		am.ActionError("Error: %s requires at least one Terraform state file or directory.", CountTFStateCommand)
+		return emptyFn
+	}
+
+	// Find the state files
+	tfs.stateFileNames = nil
+	for _, path := range flagSet.Args() {
+		fileNames, err := FindTFStateFiles(path)
+		if err != nil {
+			am.ActionError("Error: %v", err)
+			return emptyFn
+		}
+		tfs.stateFileNames = append(tfs.stateFileNames, fileNames...)
+	}
+
+	// If both --output-file and --no-output specified, then complain
+	if tfs.outputFileName != "" && tfs.noOutputFile {
+		am.ActionError("Error: Cannot specify both --output-file and -no-output!")
+		return emptyFn
+	}
+
+	// If no output file specified, then use a default name (assuming that we are not barring output)
+	if tfs.outputFileName == "" && !tfs.noOutputFile {
+		tfs.outputFileName = "resources.csv"
+	}
+
+	// Check whether a response file is being specified
+	if tfs.outputFileName != "" && !tfs.noOutputFile {
+		// Determine whether to append the output file or not
+		tfs.appendToOutput = FileExists(tfs.outputFileName)
+
+		// If appending, get the columns of the file (to check against our own)
+		if tfs.appendToOutput {
+			var err error
+			tfs.outputHeaders, err = ReadResultsHeaders(tfs.outputFileName)
+			if am.CheckError(err) {
+				return emptyFn
+			}
+		}
+
+		// Try to open the file for writing
+		tfs.outputFile = OpenFileForWriting(tfs.outputFileName, "CSV", am, tfs.appendToOutput)
+	}
+
+	// Return a function that we can use for cleaning up open resources
+	return func() {
+		if !NilInterface(tfs.outputFile) {
+			tfs.outputFile.Close()
+		}
+	}
+}
+
+// Display constructs a listing of the settings of the count-tfstate subcommand to
+// the Activity Monitor
+func (tfs *TFStateSettings) Display(am ActivityMonitor) {
+	// What is the region being selected?
+	var displayRegionName string
+	if tfs.regionName == "" {
+		displayRegionName = "(All regions)"
+	} else {
+		displayRegionName = tfs.regionName
+	}
+
+	// What is the file name for the output file
+	var displayOutputFile string
+	if tfs.outputFileName == "" {
+		displayOutputFile = "(none)"
+	} else {
+		displayOutputFile = tfs.outputFileName
+	}
+
+	// Output information about utility running
+	am.Message("%s (v%s) counting Terraform state with:\n", color.Bold("Cloud Resource Counter"), version)
+	am.Message(" o %s:  %d\n", color.Italic("State files"), len(tfs.stateFileNames))
+	am.Message(" o %s:   %s\n", color.Italic("AWS Region"), displayRegionName)
+	am.Message(" o %s:  %s\n", color.Italic("Output file"), displayOutputFile)
+}
+
+// CountTFStateFiles counts the resources in each of the supplied Terraform state
+// files, adding a row for each file to the supplied Results. The columns are those
+// of the API backend, followed by the name of the state file. Resources are counted
+// either in all regions (regionName is empty) or the supplied region.
+//
+// This method gives status back to the user via the supplied ActivityMonitor
+// instance.
+func CountTFStateFiles(am ActivityMonitor, fileNames []string, regionName string, dedupe ImageDedupeMode, results *Results) {
+	// Get the display name of the selected region
+	displayRegion := regionName
+	if regionName == "" {
+		displayRegion = "ALL_REGIONS"
+	}
+	timestamp := time.Now().Format(time.RFC3339)
+
+	// Loop through the state files...
+	for _, fileName := range fileNames {
+		// Indicate activity
+		am.StartAction("Counting Terraform state %s", fileName)
+
+		// Read the state file
+		state, err := LoadTFState(fileName)
+		if am.CheckError(err) {
+			continue
+		}
+		counts := state.Count(regionName, dedupe)
+
+		// Add its row
+		results.NewRow()
+		results.Append("Account ID", counts.AccountID)
+		results.Append("Timestamp", timestamp)
+		results.Append("Region", displayRegion)
+		results.Append("# of EC2 Instances", counts.Lifecycles.OnDemand)
+		results.Append("# of Spot Instances", counts.Lifecycles.Spot)
+		results.Append("# of Scheduled Instances", counts.Lifecycles.Scheduled)
+		results.Append("# of Capacity Block Instances", counts.Lifecycles.CapacityBlock)
+		results.Append("# of Other Lifecycle Instances", counts.Lifecycles.Other)
+		results.Append("# of EBS Volumes", counts.EBSVolumes)
+		results.Append("# of Unique Containers", counts.UniqueContainers)
+		results.Append("# of Lambda Functions", counts.LambdaFunctions)
+		results.Append("# of RDS Instances", counts.RDSInstances)
+		results.Append("# of Lightsail Instances", counts.LightsailInstances)
+		results.Append("# of S3 Buckets", counts.S3Buckets)
+		results.Append("Terraform State", fileName)
+
+		// Indicate end of activity
+		am.EndAction("OK (%d)", color.Bold(counts.Total()))
+	}
+}
+
+// RunCountTFState runs the count-tfstate subcommand with the supplied arguments
+// (those after the name of the subcommand).
+func RunCountTFState(args []string, am ActivityMonitor) {
+	// Process all command line arguments
+	settings := &TFStateSettings{}
+	cleanupFn := settings.Process(args, am)
+	defer cleanupFn()
+
+	// Show command line settings
+	settings.Display(am)
+
+	// Show activity
+	am.Message("\nActivity\n")
+
+	// Construct a new results data structure
+	results := Results{
+		StoreHeaders:  !settings.appendToOutput,
+		AppendHeaders: settings.outputHeaders,
+		Writer:        settings.outputFile,
+	}
+	results.Init()
+
+	// Count each state file
+	CountTFStateFiles(am, settings.stateFileNames, settings.regionName, settings.imageDedupe, &results)
+
+	// Save our results (noting where they came from)
+	results.Save(am)
+	am.Message("\nThese counts came from Terraform state, not from the AWS account. Resources that are not managed by Terraform are not counted.\n")
+	am.Message("\nSuccess.\n")
+}
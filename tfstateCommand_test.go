@@ -0,0 +1,217 @@
+/******************************************************************************
+Cloud Resource Counter
+File: tfstateCommand_test.go
+
+Summary: The Unit Test for tfstateCommand.
+******************************************************************************/
+
+package main
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+func TestTFStateSettingsProcess(t *testing.T) {
+	// Create a directory with the state of two workspaces
+	dirName, err := ioutil.TempDir("", "tfstate")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dirName)
+	stagingFile := filepath.Join(dirName, "terraform.tfstate.d", "staging", "terraform.tfstate")
+	productionFile := filepath.Join(dirName, "terraform.tfstate.d", "production", "terraform.tfstate")
+	writeTestTFState(t, stagingFile, testTFState)
+	writeTestTFState(t, productionFile, testTFState)
+	tempFile := filepath.Join(dirName, "temp-output-file")
+
+	// Construct our test cases...
+	cases := []struct {
+		Args            []string
+		ExpectError     bool
+		ExpectAppend    bool
+		ExpectDedupe    ImageDedupeMode
+		ExpectFileNames []string
+	}{
+		{
+			Args:            []string{"--output-file", tempFile, dirName},
+			ExpectFileNames: []string{productionFile, stagingFile},
+		},
+		{
+			Args:            []string{"--output-file", tempFile, "--region", "us-east-1", stagingFile},
+			ExpectAppend:    true,
+			ExpectFileNames: []string{stagingFile},
+		},
+		{
+			Args:            []string{"--no-output", "--image-dedupe", "raw", stagingFile, productionFile},
+			ExpectDedupe:    DedupeRaw,
+			ExpectFileNames: []string{stagingFile, productionFile},
+		},
+		{
+			Args:        []string{"--no-output"},
+			ExpectError: true,
+		},
+		{
+			Args:        []string{"--no-output", filepath.Join(dirName, "missing.tfstate")},
+			ExpectError: true,
+		},
+		{
+			Args:        []string{"--region", "abc-def", stagingFile},
+			ExpectError: true,
+		},
+		{
+			Args:        []string{"--image-dedupe", "fuzzy", stagingFile},
+			ExpectError: true,
+		},
+		{
+			Args:        []string{"--image-dedupe", "digest", stagingFile},
+			ExpectError: true,
+		},
+		{
+			Args:        []string{"--output-file", tempFile, "--no-output", stagingFile},
+			ExpectError: true,
+		},
+	}
+
+	// Loop through the cases...
+	for _, c := range cases {
+		// Create our settings
+		settings := &TFStateSettings{}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke the Process method
+		cleanupFn := settings.Process(c.Args, mon)
+
+		// Invoke the cleanup fn
+		cleanupFn()
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Errorf("Expected an error to occur for %v, but it did not", c.Args)
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if c.ExpectAppend != settings.appendToOutput {
+			t.Errorf("Unexpected Append: expected %v, actual: %v", c.ExpectAppend, settings.appendToOutput)
+		} else if c.ExpectDedupe != settings.imageDedupe {
+			t.Errorf("Unexpected ImageDedupe: expected %v, actual: %v", c.ExpectDedupe, settings.imageDedupe)
+		} else if !reflect.DeepEqual(c.ExpectFileNames, settings.stateFileNames) {
+			t.Errorf("Unexpected StateFileNames: expected %v, actual: %v", c.ExpectFileNames, settings.stateFileNames)
+		}
+	}
+}
+
+func TestCountTFStateFiles(t *testing.T) {
+	// Create two state files and a file that is not a state file
+	dirName, err := ioutil.TempDir("", "tfstate")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dirName)
+	fullFile := filepath.Join(dirName, "full.tfstate")
+	emptyFile := filepath.Join(dirName, "empty.tfstate")
+	invalidFile := filepath.Join(dirName, "invalid.tfstate")
+	writeTestTFState(t, fullFile, testTFState)
+	writeTestTFState(t, emptyFile, `{"version": 4, "resources": []}`)
+	writeTestTFState(t, invalidFile, `{"version": 3}`)
+
+	// Create our test cases
+	cases := []struct {
+		RegionName   string
+		FileNames    []string
+		ExpectError  bool
+		ExpectedRows [][]string
+	}{
+		{
+			FileNames: []string{fullFile, emptyFile},
+			ExpectedRows: [][]string{
+				{"123456789012", "ALL_REGIONS", "1", "2", "1", "1", "0", "6", "3", "2", "1", "1", "2", fullFile},
+				{"", "ALL_REGIONS", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0", emptyFile},
+			},
+		},
+		{
+			RegionName: "us-east-2",
+			FileNames:  []string{fullFile},
+			ExpectedRows: [][]string{
+				{"123456789012", "us-east-2", "0", "1", "0", "1", "0", "3", "1", "1", "0", "1", "0", fullFile},
+			},
+		},
+		{
+			FileNames:   []string{invalidFile, emptyFile},
+			ExpectError: true,
+			ExpectedRows: [][]string{
+				{"", "ALL_REGIONS", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0", emptyFile},
+			},
+		},
+	}
+
+	// The header row
+	expectedHeaders := []string{
+		"Account ID", "Timestamp", "Region", "# of EC2 Instances", "# of Spot Instances", "# of Scheduled Instances",
+		"# of Capacity Block Instances", "# of Other Lifecycle Instances", "# of EBS Volumes",
+		"# of Unique Containers", "# of Lambda Functions", "# of RDS Instances", "# of Lightsail Instances",
+		"# of S3 Buckets", "Terraform State",
+	}
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Create a Builder to hold our generated results
+		builder := strings.Builder{}
+		results := Results{
+			StoreHeaders: true,
+			Writer:       &builder,
+		}
+		results.Init()
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Count the state files and save the results
+		CountTFStateFiles(mon, c.FileNames, c.RegionName, DedupeByReference, &results)
+		errorOccurred := mon.ErrorOccured
+		mon.ErrorOccured = false
+		results.Save(mon)
+
+		// Did we expect an error?
+		if c.ExpectError != errorOccurred {
+			t.Errorf("Error: CountTFStateFiles(%v) error occurred: %v; expected %v", c.FileNames, errorOccurred, c.ExpectError)
+		}
+		if mon.ErrorOccured {
+			t.Errorf("Encountered an error during Results.Save: %s", mon.ErrorMessage)
+		}
+
+		// Read the generated results
+		records, err := csv.NewReader(strings.NewReader(builder.String())).ReadAll()
+		if err != nil {
+			t.Errorf("Unexpected error while reading results: %v", err)
+			continue
+		}
+		if len(records) != len(c.ExpectedRows)+1 {
+			t.Errorf("Error: CountTFStateFiles(%v) produced %d rows; expected %d", c.FileNames, len(records), len(c.ExpectedRows)+1)
+			continue
+		}
+		if !reflect.DeepEqual(records[0], expectedHeaders) {
+			t.Errorf("Error: CountTFStateFiles(%v) produced headers %v; expected %v", c.FileNames, records[0], expectedHeaders)
+		}
+
+		// Compare each row (ignoring its timestamp)
+		for index, expectedRow := range c.ExpectedRows {
+			record := records[index+1]
+			actualRow := append([]string{record[0]}, record[2:]...)
+			if !reflect.DeepEqual(actualRow, expectedRow) {
+				t.Errorf("Error: CountTFStateFiles(%v) produced row %v; expected %v", c.FileNames, actualRow, expectedRow)
+			}
+		}
+	}
+}
@@ -0,0 +1,415 @@
+/******************************************************************************
+Cloud Resource Counter
+File: tfstate_test.go
+
+Summary: The Unit Test for tfstate.
+******************************************************************************/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// A Terraform state file with one of every resource type that we count (and a few
+// that we do not)
+const testTFState = `{
+  "version": 4,
+  "terraform_version": "1.5.7",
+  "serial": 12,
+  "lineage": "3e0f3c4a-8a8e-4a5c-9f07-7a52b1d3f1c2",
+  "outputs": {},
+  "resources": [
+    {
+      "mode": "data",
+      "type": "aws_instance",
+      "name": "existing",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"attributes": {"id": "i-00000000000000000", "instance_state": "running", "arn": "arn:aws:ec2:us-east-1:999999999999:instance/i-00000000000000000"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"attributes": {"id": "logs", "arn": "arn:aws:s3:::logs", "region": "us-east-1"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {
+          "index_key": 0,
+          "attributes": {
+            "id": "i-11111111111111111",
+            "arn": "arn:aws:ec2:us-east-1:123456789012:instance/i-11111111111111111",
+            "instance_state": "running",
+            "instance_lifecycle": "",
+            "root_block_device": [{"volume_id": "vol-11111111111111111"}],
+            "ebs_block_device": [{"volume_id": "vol-22222222222222222"}]
+          }
+        },
+        {
+          "index_key": 1,
+          "attributes": {
+            "id": "i-33333333333333333",
+            "arn": "arn:aws:ec2:us-east-2:123456789012:instance/i-33333333333333333",
+            "instance_state": "stopped",
+            "root_block_device": [{"volume_id": "vol-33333333333333333"}],
+            "ebs_block_device": []
+          }
+        },
+        {
+          "index_key": 2,
+          "attributes": {
+            "id": "i-44444444444444444",
+            "arn": "arn:aws:ec2:us-east-1:123456789012:instance/i-44444444444444444",
+            "instance_state": "running",
+            "instance_lifecycle": "spot",
+            "root_block_device": [{"volume_id": "vol-44444444444444444"}]
+          }
+        },
+        {
+          "index_key": 3,
+          "attributes": {
+            "id": "i-66666666666666666",
+            "arn": "arn:aws:ec2:us-east-1:123456789012:instance/i-66666666666666666",
+            "instance_state": "running",
+            "instance_lifecycle": "scheduled"
+          }
+        },
+        {
+          "index_key": 4,
+          "attributes": {
+            "id": "i-77777777777777777",
+            "arn": "arn:aws:ec2:us-east-2:123456789012:instance/i-77777777777777777",
+            "instance_state": "running",
+            "instance_lifecycle": "capacity-block"
+          }
+        }
+      ]
+    },
+    {
+      "module": "module.batch",
+      "mode": "managed",
+      "type": "aws_spot_instance_request",
+      "name": "worker",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"index_key": 0, "attributes": {"id": "sir-1", "spot_instance_id": "i-55555555555555555", "instance_state": "running", "availability_zone": "us-east-2a", "root_block_device": [{"volume_id": "vol-55555555555555555"}]}},
+        {"index_key": 1, "attributes": {"id": "sir-2", "spot_instance_id": "", "availability_zone": "us-east-2b"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_ebs_volume",
+      "name": "data",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"attributes": {"id": "vol-22222222222222222", "arn": "arn:aws:ec2:us-east-1:123456789012:volume/vol-22222222222222222"}},
+        {"attributes": {"id": "vol-66666666666666666", "arn": "arn:aws:ec2:us-east-1:123456789012:volume/vol-66666666666666666"}},
+        {"attributes": {"id": "vol-77777777777777777", "arn": "arn:aws:ec2:us-east-2:123456789012:volume/vol-77777777777777777"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_volume_attachment",
+      "name": "data",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"attributes": {"id": "vai-1", "device_name": "/dev/sdf", "volume_id": "vol-77777777777777777", "instance_id": "i-77777777777777777"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_ecs_task_definition",
+      "name": "app",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"attributes": {"arn": "arn:aws:ecs:us-east-1:123456789012:task-definition/app:3", "container_definitions": "[{\"name\":\"app\",\"image\":\"nginx\"},{\"name\":\"sidecar\",\"image\":\"docker.io/library/nginx:latest\"},{\"name\":\"agent\",\"image\":\"123456789012.dkr.ecr.us-east-1.amazonaws.com/agent:1.2\"}]"}},
+        {"attributes": {"arn": "arn:aws:ecs:us-east-2:123456789012:task-definition/app:1", "container_definitions": "[{\"name\":\"app\",\"image\":\"redis:6\"}]"}},
+        {"attributes": {"arn": "arn:aws:ecs:us-east-2:123456789012:task-definition/broken:1", "container_definitions": "not json"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_lambda_function",
+      "name": "handler",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"index_key": "a", "attributes": {"arn": "arn:aws:lambda:us-east-1:123456789012:function:a"}},
+        {"index_key": "b", "attributes": {"arn": "arn:aws:lambda:us-east-2:123456789012:function:b"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_db_instance",
+      "name": "db",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"index_key": 0, "attributes": {"arn": "arn:aws:rds:us-east-1:123456789012:db:db-0", "status": "available"}},
+        {"index_key": 1, "attributes": {"arn": "arn:aws:rds:us-east-1:123456789012:db:db-1", "status": "stopped"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_lightsail_instance",
+      "name": "blog",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"attributes": {"arn": "arn:aws:lightsail:us-east-2:123456789012:Instance/blog", "availability_zone": "us-east-2a"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_sqs_queue",
+      "name": "jobs",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"attributes": {"arn": "arn:aws:sqs:us-east-1:123456789012:jobs"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "legacy",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"attributes": {"id": "legacy", "arn": "arn:aws:s3:::legacy"}}
+      ]
+    }
+  ]
+}`
+
+// Write a Terraform state file with the supplied contents into the supplied
+// directory.
+func writeTestTFState(t *testing.T, fileName string, contents string) {
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		t.Fatalf("Unexpected error creating directory of %s: %v", fileName, err)
+	}
+	if err := ioutil.WriteFile(fileName, []byte(contents), 0644); err != nil {
+		t.Fatalf("Unexpected error creating %s: %v", fileName, err)
+	}
+}
+
+func TestTFStateCount(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		RegionName     string
+		Dedupe         ImageDedupeMode
+		ExpectedCounts TFStateCounts
+	}{
+		{
+			Dedupe: DedupeByReference,
+			ExpectedCounts: TFStateCounts{
+				AccountID: "123456789012",
+				Lifecycles: EC2LifecycleCounts{
+					OnDemand:      1,
+					Spot:          2,
+					Scheduled:     1,
+					CapacityBlock: 1,
+				},
+				EBSVolumes:         6,
+				UniqueContainers:   3,
+				LambdaFunctions:    2,
+				RDSInstances:       1,
+				LightsailInstances: 1,
+				S3Buckets:          2,
+			},
+		},
+		{
+			Dedupe: DedupeRaw,
+			ExpectedCounts: TFStateCounts{
+				AccountID: "123456789012",
+				Lifecycles: EC2LifecycleCounts{
+					OnDemand:      1,
+					Spot:          2,
+					Scheduled:     1,
+					CapacityBlock: 1,
+				},
+				EBSVolumes:         6,
+				UniqueContainers:   4,
+				LambdaFunctions:    2,
+				RDSInstances:       1,
+				LightsailInstances: 1,
+				S3Buckets:          2,
+			},
+		},
+		{
+			RegionName: "us-east-1",
+			Dedupe:     DedupeByReference,
+			ExpectedCounts: TFStateCounts{
+				AccountID: "123456789012",
+				Lifecycles: EC2LifecycleCounts{
+					OnDemand:  1,
+					Spot:      1,
+					Scheduled: 1,
+				},
+				EBSVolumes:       3,
+				UniqueContainers: 2,
+				LambdaFunctions:  1,
+				RDSInstances:     1,
+				S3Buckets:        1,
+			},
+		},
+		{
+			RegionName: "us-east-2",
+			Dedupe:     DedupeByReference,
+			ExpectedCounts: TFStateCounts{
+				AccountID: "123456789012",
+				Lifecycles: EC2LifecycleCounts{
+					Spot:          1,
+					CapacityBlock: 1,
+				},
+				EBSVolumes:         3,
+				UniqueContainers:   1,
+				LambdaFunctions:    1,
+				LightsailInstances: 1,
+			},
+		},
+		{
+			RegionName: "af-south-1",
+			Dedupe:     DedupeByReference,
+			ExpectedCounts: TFStateCounts{
+				AccountID: "123456789012",
+			},
+		},
+	}
+
+	// Write our state file
+	dirName, err := ioutil.TempDir("", "tfstate")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dirName)
+	fileName := filepath.Join(dirName, "terraform.tfstate")
+	writeTestTFState(t, fileName, testTFState)
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Load the state file
+		state, err := LoadTFState(fileName)
+		if err != nil {
+			t.Fatalf("Unexpected error loading %s: %v", fileName, err)
+		}
+
+		// Count its resources
+		actualCounts := state.Count(c.RegionName, c.Dedupe)
+
+		// Did we get what we expected?
+		if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: Count(%q, %s) returned %+v; expected %+v", c.RegionName, c.Dedupe, actualCounts, c.ExpectedCounts)
+		}
+	}
+}
+
+func TestLoadTFState(t *testing.T) {
+	// Create our test cases
+	cases := []struct {
+		Contents    string
+		ExpectError bool
+	}{
+		{
+			Contents: `{"version": 4, "resources": []}`,
+		},
+		{
+			Contents:    `{"version": 3, "modules": []}`,
+			ExpectError: true,
+		},
+		{
+			Contents:    `{"version": 4, "resources": [`,
+			ExpectError: true,
+		},
+	}
+
+	// Create a directory for our state files
+	dirName, err := ioutil.TempDir("", "tfstate")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dirName)
+
+	// Loop through the test cases
+	for _, c := range cases {
+		// Write the state file and load it
+		fileName := filepath.Join(dirName, "terraform.tfstate")
+		writeTestTFState(t, fileName, c.Contents)
+		_, err := LoadTFState(fileName)
+
+		// Did we expect an error?
+		if c.ExpectError && err == nil {
+			t.Errorf("Error: LoadTFState(%s) did not return an error", c.Contents)
+		} else if !c.ExpectError && err != nil {
+			t.Errorf("Error: LoadTFState(%s) returned an unexpected error: %v", c.Contents, err)
+		}
+	}
+
+	// A missing file is an error
+	if _, err = LoadTFState(filepath.Join(dirName, "missing.tfstate")); err == nil {
+		t.Error("Expected an error for a missing file, but it did not occur")
+	}
+}
+
+func TestFindTFStateFiles(t *testing.T) {
+	// Create a directory with the state of several workspaces (and other files)
+	dirName, err := ioutil.TempDir("", "tfstate")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dirName)
+	for _, fileName := range []string{
+		"terraform.tfstate",
+		"terraform.tfstate.backup",
+		"main.tf",
+		filepath.Join(".terraform", "terraform.tfstate"),
+		filepath.Join("terraform.tfstate.d", "staging", "terraform.tfstate"),
+		filepath.Join("terraform.tfstate.d", "production", "terraform.tfstate"),
+	} {
+		writeTestTFState(t, filepath.Join(dirName, fileName), `{"version": 4}`)
+	}
+
+	// Find the state files in the directory
+	fileNames, err := FindTFStateFiles(dirName)
+	expected := []string{
+		filepath.Join(dirName, "terraform.tfstate"),
+		filepath.Join(dirName, "terraform.tfstate.d", "production", "terraform.tfstate"),
+		filepath.Join(dirName, "terraform.tfstate.d", "staging", "terraform.tfstate"),
+	}
+	if err != nil {
+		t.Errorf("Unexpected error occurred: %v", err)
+	} else if !reflect.DeepEqual(fileNames, expected) {
+		t.Errorf("Error: FindTFStateFiles returned %v; expected %v", fileNames, expected)
+	}
+
+	// A single file is returned as is (whatever its name)
+	fileName := filepath.Join(dirName, "terraform.tfstate.backup")
+	if fileNames, err = FindTFStateFiles(fileName); err != nil {
+		t.Errorf("Unexpected error occurred: %v", err)
+	} else if !reflect.DeepEqual(fileNames, []string{fileName}) {
+		t.Errorf("Error: FindTFStateFiles returned %v; expected %v", fileNames, []string{fileName})
+	}
+
+	// A directory without any state files is an error
+	emptyDirName := filepath.Join(dirName, "empty")
+	if err = os.Mkdir(emptyDirName, 0755); err != nil {
+		t.Fatalf("Unexpected error creating %s: %v", emptyDirName, err)
+	}
+	if _, err = FindTFStateFiles(emptyDirName); err == nil {
+		t.Error("Expected an error for a directory without state files, but it did not occur")
+	}
+
+	// A missing path is an error
+	if _, err = FindTFStateFiles(filepath.Join(dirName, "missing")); err == nil {
+		t.Error("Expected an error for a missing path, but it did not occur")
+	}
+}
@@ -8,11 +8,16 @@ Summary: Various utility functions
 package main
 
 import (
+	"errors"
+	"net"
 	"os"
 	"reflect"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ec2"
 )
 
@@ -93,11 +98,61 @@ func Map(vs []string, f func(string) string) []string {
 	return vsm
 }
 
+// ForEachConcurrently invokes fn for each index from 0 to count-1, using at most
+// concurrency goroutines at once. It returns once all invocations have finished.
+// The supplied function must be safe to call from multiple goroutines.
+func ForEachConcurrently(count int, concurrency int, fn func(int)) {
+	// Always use at least one goroutine
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Feed the indices to our workers
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency && worker < count; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				fn(index)
+			}
+		}()
+	}
+	for index := 0; index < count; index++ {
+		indices <- index
+	}
+	close(indices)
+
+	// Wait for our workers to finish
+	wg.Wait()
+}
+
 // NilInterface checks whether the supplied interface is nil or not
 func NilInterface(intf interface{}) bool {
 	return intf == nil || reflect.ValueOf(intf).IsNil()
 }
 
+// IsServiceUnavailableError checks whether the supplied error shows that a
+// service is not offered in a region: either the SDK has no endpoint for it or
+// the endpoint's host name does not resolve.
+func IsServiceUnavailableError(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+
+	switch aerr.Code() {
+	case "UnknownEndpointError":
+		return true
+	case request.ErrCodeRequestError:
+		var dnsErr *net.DNSError
+		return errors.As(aerr.OrigErr(), &dnsErr)
+	}
+
+	return false
+}
+
 // FileExists checks if a file exists and is not a directory before we
 // try using it to prevent further errors.
 func FileExists(fileName string) bool {
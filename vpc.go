@@ -0,0 +1,164 @@
+/******************************************************************************
+Cloud Resource Counter
+File: vpc.go
+
+Summary: Provides a summary of the VPC footprint: VPCs (with and without flow
+         logs), subnets, Transit Gateway attachments and VPC endpoints.
+******************************************************************************/
+
+package main
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	color "github.com/logrusorgru/aurora"
+)
+
+// VPCFootprintCounts holds the number of VPCs (split by whether they have an active
+// flow log), subnets, Transit Gateway attachments and VPC endpoints.
+type VPCFootprintCounts struct {
+	VPCs                      int
+	VPCsWithFlowLogs          int
+	VPCsWithoutFlowLogs       int
+	Subnets                   int
+	TransitGatewayAttachments int
+	VPCEndpoints              int
+}
+
+// Add the supplied counts into our struct.
+func (vfc *VPCFootprintCounts) Add(other VPCFootprintCounts) {
+	vfc.VPCs += other.VPCs
+	vfc.VPCsWithFlowLogs += other.VPCsWithFlowLogs
+	vfc.VPCsWithoutFlowLogs += other.VPCsWithoutFlowLogs
+	vfc.Subnets += other.Subnets
+	vfc.TransitGatewayAttachments += other.TransitGatewayAttachments
+	vfc.VPCEndpoints += other.VPCEndpoints
+}
+
+// VPCFootprint retrieves the VPC footprint either for all regions (allRegions is
+// true) or the region associated with the session. This method gives status back
+// to the user via the supplied ActivityMonitor instance.
+func VPCFootprint(sf ServiceFactory, am ActivityMonitor, allRegions bool) VPCFootprintCounts {
+	// Indicate activity
+	am.StartAction("Retrieving VPC footprint")
+
+	// Should we get the counts for all regions?
+	var footprint VPCFootprintCounts
+	if allRegions {
+		// Get the list of all enabled regions for this account
+		regionsSlice := GetEC2Regions(sf.GetEC2InstanceService(""), am)
+
+		// Loop through all of the regions
+		for _, regionName := range regionsSlice {
+			// Get the VPC footprint for a specific region
+			footprint.Add(vpcFootprintForSingleRegion(sf.GetEC2InstanceService(regionName), am))
+		}
+	} else {
+		// Get the VPC footprint for the region selected by this session
+		footprint = vpcFootprintForSingleRegion(sf.GetEC2InstanceService(""), am)
+	}
+
+	// Indicate end of activity
+	am.EndAction("OK (%d VPCs, %d subnets, %d without flow logs)",
+		color.Bold(footprint.VPCs), color.Bold(footprint.Subnets), color.Bold(footprint.VPCsWithoutFlowLogs))
+
+	return footprint
+}
+
+// Get the VPC footprint for a single region. We stop at the first error.
+func vpcFootprintForSingleRegion(ec2is *EC2InstanceService, am ActivityMonitor) VPCFootprintCounts {
+	// Indicate activity
+	am.Message(".")
+
+	var footprint VPCFootprintCounts
+
+	// Get the IDs of all VPCs
+	var vpcIDs []string
+	err := ec2is.InspectVpcs(&ec2.DescribeVpcsInput{}, func(page *ec2.DescribeVpcsOutput, lastPage bool) bool {
+		for _, vpc := range page.Vpcs {
+			vpcIDs = append(vpcIDs, aws.StringValue(vpc.VpcId))
+		}
+
+		return true
+	})
+	if am.CheckError(err) {
+		return footprint
+	}
+	footprint.VPCs = len(vpcIDs)
+
+	// Find the resources (VPCs, subnets and network interfaces) with an active flow log
+	flowLogged := make(map[string]bool)
+	err = ec2is.InspectFlowLogs(&ec2.DescribeFlowLogsInput{}, func(page *ec2.DescribeFlowLogsOutput, lastPage bool) bool {
+		for _, flowLog := range page.FlowLogs {
+			if aws.StringValue(flowLog.FlowLogStatus) == "ACTIVE" {
+				flowLogged[aws.StringValue(flowLog.ResourceId)] = true
+			}
+		}
+
+		return true
+	})
+	if am.CheckError(err) {
+		return footprint
+	}
+
+	// Split the VPCs by whether they have a flow log
+	for _, vpcID := range vpcIDs {
+		if flowLogged[vpcID] {
+			footprint.VPCsWithFlowLogs++
+		} else {
+			footprint.VPCsWithoutFlowLogs++
+		}
+	}
+
+	// Count the subnets
+	err = ec2is.InspectSubnets(&ec2.DescribeSubnetsInput{}, func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+		footprint.Subnets += len(page.Subnets)
+
+		return true
+	})
+	if am.CheckError(err) {
+		return footprint
+	}
+
+	// Count the Transit Gateway attachments that are still in use
+	err = ec2is.InspectTransitGatewayAttachments(&ec2.DescribeTransitGatewayAttachmentsInput{}, func(page *ec2.DescribeTransitGatewayAttachmentsOutput, lastPage bool) bool {
+		for _, attachment := range page.TransitGatewayAttachments {
+			if !isDefunctVPCState(aws.StringValue(attachment.State)) {
+				footprint.TransitGatewayAttachments++
+			}
+		}
+
+		return true
+	})
+	if am.CheckError(err) {
+		return footprint
+	}
+
+	// Count the VPC endpoints that are still in use
+	err = ec2is.InspectVpcEndpoints(&ec2.DescribeVpcEndpointsInput{}, func(page *ec2.DescribeVpcEndpointsOutput, lastPage bool) bool {
+		for _, endpoint := range page.VpcEndpoints {
+			if !isDefunctVPCState(aws.StringValue(endpoint.State)) {
+				footprint.VPCEndpoints++
+			}
+		}
+
+		return true
+	})
+	am.CheckError(err)
+
+	return footprint
+}
+
+// Is the supplied state (of a Transit Gateway attachment or VPC endpoint) one in
+// which the resource is no longer in use? The VPC endpoint states are capitalized
+// ("Deleted"), so we compare without case.
+func isDefunctVPCState(state string) bool {
+	switch strings.ToLower(state) {
+	case "deleting", "deleted", "failed", "rejected", "expired":
+		return true
+	default:
+		return false
+	}
+}
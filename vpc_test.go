@@ -0,0 +1,391 @@
+/******************************************************************************
+Cloud Resource Counter
+File: vpc_test.go
+
+Summary: The Unit Test for vpc.
+******************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/expel-io/cloud-resource-counter/mock"
+)
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake VPC Data
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// VPCRegionInfo describes the fake VPC footprint in a region. Each slice holds the
+// pages returned by the corresponding API.
+type VPCRegionInfo struct {
+	VpcPages        []*ec2.DescribeVpcsOutput
+	FlowLogPages    []*ec2.DescribeFlowLogsOutput
+	SubnetPages     []*ec2.DescribeSubnetsOutput
+	AttachmentPages []*ec2.DescribeTransitGatewayAttachmentsOutput
+	EndpointPages   []*ec2.DescribeVpcEndpointsOutput
+}
+
+// Helper function to construct a flow log for the supplied resource
+func flowLogFor(resourceID string, status string) *ec2.FlowLog {
+	return &ec2.FlowLog{
+		ResourceId:    aws.String(resourceID),
+		FlowLogStatus: aws.String(status),
+	}
+}
+
+// This is our map of regions and the VPC footprint in each
+var vpcFootprintPerRegion = map[string]*VPCRegionInfo{
+	// US-EAST-1 illustrates a case where most APIs return two pages of results:
+	// 3 VPCs (1 with an active flow log), 4 subnets, 2 Transit Gateway attachments
+	// (of 3) and 2 VPC endpoints (of 3).
+	"us-east-1": &VPCRegionInfo{
+		VpcPages: []*ec2.DescribeVpcsOutput{
+			&ec2.DescribeVpcsOutput{
+				Vpcs: []*ec2.Vpc{
+					&ec2.Vpc{VpcId: aws.String("vpc-1111")},
+					&ec2.Vpc{VpcId: aws.String("vpc-2222")},
+				},
+			},
+			&ec2.DescribeVpcsOutput{
+				Vpcs: []*ec2.Vpc{
+					&ec2.Vpc{VpcId: aws.String("vpc-3333")},
+				},
+			},
+		},
+		// vpc-2222 has an active flow log; vpc-3333's flow log is not active; a subnet
+		// of vpc-1111 has a flow log (which does not count for the VPC)
+		FlowLogPages: []*ec2.DescribeFlowLogsOutput{
+			&ec2.DescribeFlowLogsOutput{
+				FlowLogs: []*ec2.FlowLog{
+					flowLogFor("vpc-2222", "ACTIVE"),
+					flowLogFor("subnet-1111a", "ACTIVE"),
+				},
+			},
+			&ec2.DescribeFlowLogsOutput{
+				FlowLogs: []*ec2.FlowLog{
+					flowLogFor("vpc-3333", "INACTIVE"),
+				},
+			},
+		},
+		SubnetPages: []*ec2.DescribeSubnetsOutput{
+			&ec2.DescribeSubnetsOutput{
+				Subnets: []*ec2.Subnet{
+					&ec2.Subnet{SubnetId: aws.String("subnet-1111a")},
+					&ec2.Subnet{SubnetId: aws.String("subnet-1111b")},
+					&ec2.Subnet{SubnetId: aws.String("subnet-2222a")},
+				},
+			},
+			&ec2.DescribeSubnetsOutput{
+				Subnets: []*ec2.Subnet{
+					&ec2.Subnet{SubnetId: aws.String("subnet-3333a")},
+				},
+			},
+		},
+		AttachmentPages: []*ec2.DescribeTransitGatewayAttachmentsOutput{
+			&ec2.DescribeTransitGatewayAttachmentsOutput{
+				TransitGatewayAttachments: []*ec2.TransitGatewayAttachment{
+					&ec2.TransitGatewayAttachment{State: aws.String("available")},
+					&ec2.TransitGatewayAttachment{State: aws.String("deleted")},
+				},
+			},
+			&ec2.DescribeTransitGatewayAttachmentsOutput{
+				TransitGatewayAttachments: []*ec2.TransitGatewayAttachment{
+					&ec2.TransitGatewayAttachment{State: aws.String("pendingAcceptance")},
+				},
+			},
+		},
+		EndpointPages: []*ec2.DescribeVpcEndpointsOutput{
+			&ec2.DescribeVpcEndpointsOutput{
+				VpcEndpoints: []*ec2.VpcEndpoint{
+					&ec2.VpcEndpoint{State: aws.String("Available")},
+					&ec2.VpcEndpoint{State: aws.String("Pending")},
+					&ec2.VpcEndpoint{State: aws.String("Deleted")},
+				},
+			},
+		},
+	},
+	// US-EAST-2 has 1 VPC (without a flow log) and 2 subnets
+	"us-east-2": &VPCRegionInfo{
+		VpcPages: []*ec2.DescribeVpcsOutput{
+			&ec2.DescribeVpcsOutput{
+				Vpcs: []*ec2.Vpc{
+					&ec2.Vpc{VpcId: aws.String("vpc-4444")},
+				},
+			},
+		},
+		FlowLogPages: []*ec2.DescribeFlowLogsOutput{
+			&ec2.DescribeFlowLogsOutput{},
+		},
+		SubnetPages: []*ec2.DescribeSubnetsOutput{
+			&ec2.DescribeSubnetsOutput{
+				Subnets: []*ec2.Subnet{
+					&ec2.Subnet{SubnetId: aws.String("subnet-4444a")},
+					&ec2.Subnet{SubnetId: aws.String("subnet-4444b")},
+				},
+			},
+		},
+		AttachmentPages: []*ec2.DescribeTransitGatewayAttachmentsOutput{
+			&ec2.DescribeTransitGatewayAttachmentsOutput{},
+		},
+		EndpointPages: []*ec2.DescribeVpcEndpointsOutput{
+			&ec2.DescribeVpcEndpointsOutput{},
+		},
+	},
+	// AF-SOUTH-1 has no VPCs
+	"af-south-1": &VPCRegionInfo{
+		VpcPages: []*ec2.DescribeVpcsOutput{
+			&ec2.DescribeVpcsOutput{},
+		},
+		FlowLogPages: []*ec2.DescribeFlowLogsOutput{
+			&ec2.DescribeFlowLogsOutput{},
+		},
+		SubnetPages: []*ec2.DescribeSubnetsOutput{
+			&ec2.DescribeSubnetsOutput{},
+		},
+		AttachmentPages: []*ec2.DescribeTransitGatewayAttachmentsOutput{
+			&ec2.DescribeTransitGatewayAttachmentsOutput{},
+		},
+		EndpointPages: []*ec2.DescribeVpcEndpointsOutput{
+			&ec2.DescribeVpcEndpointsOutput{},
+		},
+	},
+	// AF-SOUTH-2 has a VPC, but its flow logs cannot be described (an error)
+	"af-south-2": &VPCRegionInfo{
+		VpcPages: []*ec2.DescribeVpcsOutput{
+			&ec2.DescribeVpcsOutput{
+				Vpcs: []*ec2.Vpc{
+					&ec2.Vpc{VpcId: aws.String("vpc-5555")},
+				},
+			},
+		},
+	},
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake VPC Service
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// To use this struct, the caller must supply a VPCRegionInfo and a
+// DescribeRegionsOutput. If either (or the pages for a particular API) is missing,
+// it will trigger the mock functions to simulate an error.
+type fakeVPCService struct {
+	ec2iface.EC2API
+	RegionInfo *VPCRegionInfo
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Simulate the DescribeRegions function
+func (fake *fakeVPCService) DescribeRegions(input *ec2.DescribeRegionsInput) (*ec2.DescribeRegionsOutput, error) {
+	// If the supplied response is nil, then simulate an error
+	if fake.DRResponse == nil {
+		return nil, errors.New("DescribeRegions encountered an unexpected error: 6789")
+	}
+
+	return fake.DRResponse, nil
+}
+
+// Simulate the DescribeVpcsPages function
+func (fake *fakeVPCService) DescribeVpcsPages(input *ec2.DescribeVpcsInput, fn func(*ec2.DescribeVpcsOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.VpcPages == nil {
+		return errors.New("DescribeVpcsPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.VpcPages {
+		if !fn(output, index == len(fake.RegionInfo.VpcPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Simulate the DescribeFlowLogsPages function
+func (fake *fakeVPCService) DescribeFlowLogsPages(input *ec2.DescribeFlowLogsInput, fn func(*ec2.DescribeFlowLogsOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.FlowLogPages == nil {
+		return errors.New("DescribeFlowLogsPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.FlowLogPages {
+		if !fn(output, index == len(fake.RegionInfo.FlowLogPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Simulate the DescribeSubnetsPages function
+func (fake *fakeVPCService) DescribeSubnetsPages(input *ec2.DescribeSubnetsInput, fn func(*ec2.DescribeSubnetsOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.SubnetPages == nil {
+		return errors.New("DescribeSubnetsPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.SubnetPages {
+		if !fn(output, index == len(fake.RegionInfo.SubnetPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Simulate the DescribeTransitGatewayAttachmentsPages function
+func (fake *fakeVPCService) DescribeTransitGatewayAttachmentsPages(input *ec2.DescribeTransitGatewayAttachmentsInput, fn func(*ec2.DescribeTransitGatewayAttachmentsOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.AttachmentPages == nil {
+		return errors.New("DescribeTransitGatewayAttachmentsPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.AttachmentPages {
+		if !fn(output, index == len(fake.RegionInfo.AttachmentPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Simulate the DescribeVpcEndpointsPages function
+func (fake *fakeVPCService) DescribeVpcEndpointsPages(input *ec2.DescribeVpcEndpointsInput, fn func(*ec2.DescribeVpcEndpointsOutput, bool) bool) error {
+	// If the supplied region info is nil, then simulate an error
+	if fake.RegionInfo == nil || fake.RegionInfo.EndpointPages == nil {
+		return errors.New("DescribeVpcEndpointsPages encountered an unexpected error: 1234")
+	}
+
+	// Loop through the slice of responses, invoking the supplied function
+	for index, output := range fake.RegionInfo.EndpointPages {
+		if !fn(output, index == len(fake.RegionInfo.EndpointPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Fake Service Factory
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+// This structure simulates the AWS Service Factory by storing some pregenerated
+// responses (that would come from AWS).
+type fakeVPCServiceFactory struct {
+	baseFakeServiceFactory
+	RegionName string
+	DRResponse *ec2.DescribeRegionsOutput
+}
+
+// Return our current region
+func (fsf fakeVPCServiceFactory) GetCurrentRegion() string {
+	return fsf.RegionName
+}
+
+// Return a specialized EC2InstanceService that returns pre-canned responses
+func (fsf fakeVPCServiceFactory) GetEC2InstanceService(regionName string) *EC2InstanceService {
+	// If the caller failed to specify a region, then use what is associated with our factory
+	var resolvedRegionName string
+	if regionName == "" {
+		resolvedRegionName = fsf.RegionName
+	} else {
+		resolvedRegionName = regionName
+	}
+
+	return &EC2InstanceService{
+		Client: &fakeVPCService{
+			RegionInfo: vpcFootprintPerRegion[resolvedRegionName],
+			DRResponse: fsf.DRResponse,
+		},
+	}
+}
+
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+// Unit Test for VPCFootprint
+// =-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=
+
+func TestVPCFootprint(t *testing.T) {
+	// Describe all of our test cases: 2 failures and 4 success cases
+	cases := []struct {
+		RegionName     string
+		AllRegions     bool
+		ExpectedCounts VPCFootprintCounts
+		ExpectError    bool
+	}{
+		{
+			RegionName: "us-east-1",
+			ExpectedCounts: VPCFootprintCounts{
+				VPCs:                      3,
+				VPCsWithFlowLogs:          1,
+				VPCsWithoutFlowLogs:       2,
+				Subnets:                   4,
+				TransitGatewayAttachments: 2,
+				VPCEndpoints:              2,
+			},
+		}, {
+			RegionName: "us-east-2",
+			ExpectedCounts: VPCFootprintCounts{
+				VPCs:                1,
+				VPCsWithoutFlowLogs: 1,
+				Subnets:             2,
+			},
+		}, {
+			RegionName: "af-south-1",
+		}, {
+			RegionName:  "af-south-2",
+			ExpectError: true,
+		}, {
+			RegionName:  "undefined-region",
+			ExpectError: true,
+		}, {
+			AllRegions: true,
+			ExpectedCounts: VPCFootprintCounts{
+				VPCs:                      4,
+				VPCsWithFlowLogs:          1,
+				VPCsWithoutFlowLogs:       3,
+				Subnets:                   6,
+				TransitGatewayAttachments: 2,
+				VPCEndpoints:              2,
+			},
+		},
+	}
+
+	// Loop through each test case
+	for _, c := range cases {
+		// Create our fake service factory
+		sf := fakeVPCServiceFactory{
+			RegionName: c.RegionName,
+			DRResponse: ec2Regions,
+		}
+
+		// Create a mock activity monitor
+		mon := &mock.ActivityMonitorImpl{}
+
+		// Invoke our VPC Footprint function
+		actualCounts := VPCFootprint(sf, mon, c.AllRegions)
+
+		// Did we expect an error?
+		if c.ExpectError {
+			// Did it fail to arrive?
+			if !mon.ErrorOccured {
+				t.Error("Expected an error to occur, but it did not... :^(")
+			}
+		} else if mon.ErrorOccured {
+			t.Errorf("Unexpected error occurred: %s", mon.ErrorMessage)
+		} else if actualCounts != c.ExpectedCounts {
+			t.Errorf("Error: VPCFootprint returned %+v; expected %+v", actualCounts, c.ExpectedCounts)
+		} else if mon.ProgramExited {
+			t.Errorf("Unexpected Exit: The program unexpected exited with status code=%d", mon.ExitCode)
+		}
+	}
+}